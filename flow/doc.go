@@ -0,0 +1,2 @@
+// Package flow normalizes the IPFIX, Netflow v9 and sFlow data to a protocol-neutral flow
+package flow
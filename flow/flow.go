@@ -0,0 +1,366 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    flow.go
+//: details: protocol-neutral normalized flow record
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package flow
+
+import (
+	"net"
+
+	"github.com/VerizonDigital/vflow/ipfix"
+	"github.com/VerizonDigital/vflow/netflow/v9"
+	"github.com/VerizonDigital/vflow/packet"
+	"github.com/VerizonDigital/vflow/sflow"
+)
+
+// SchemaVersion is the normalized flow schema version, it changes
+// if a field is removed or its meaning changes, docs/flow_schema.md
+const SchemaVersion = 1
+
+// Flow represents the protocol-neutral normalized flow record
+type Flow struct {
+	SchemaVersion int    // normalized flow schema version
+	FlowType      string // ipfix, netflow9 or sflow
+	Exporter      string // exporter address or agent id
+	SrcAddr       string // source IPv4 or IPv6 address
+	DstAddr       string // destination IPv4 or IPv6 address
+	SrcPort       uint16 // source transport port
+	DstPort       uint16 // destination transport port
+	Protocol      uint8  // IP protocol number
+	Bytes         uint64 // IP bytes
+	Packets       uint64 // IP packets
+	TCPFlags      uint16 // TCP control bits
+	InputIf       uint32 // input interface index
+	OutputIf      uint32 // output interface index
+	SrcVlan       uint16 // source 802.1Q VLAN id
+	DstVlan       uint16 // destination 802.1Q VLAN id
+	NextHop       string // IP or BGP next hop address
+	SrcAS         uint32 // source BGP AS number
+	DstAS         uint32 // destination BGP AS number
+	StartTime     int64  // flow start time in unix milliseconds
+	EndTime       int64  // flow end time in unix milliseconds
+	SamplingRate  uint32 // one out of N packets sampled, 0 unknown
+}
+
+// Flow types
+const (
+	TypeIPFIX    = "ipfix"
+	TypeNetflow9 = "netflow9"
+	TypeSFlow    = "sflow"
+)
+
+// record represents a flow while the fields are mapping,
+// the system uptime relative times resolve at the end
+type record struct {
+	Flow
+	startUpTime, endUpTime uint64
+	initTime               uint64
+	upTime                 bool
+	exportTime             int64
+	startBy, endBy         uint16
+}
+
+// FromIPFIX returns the normalized flows of the IPFIX message
+// data records, the enterprise elements and the options data
+// records are ignored
+func FromIPFIX(m *ipfix.Message) []Flow {
+	flows := make([]Flow, 0, len(m.DataSets))
+
+	for i, fields := range m.DataSets {
+		if m.IsOptions(i) {
+			continue
+		}
+
+		r := newRecord(TypeIPFIX, m.AgentID)
+		r.exportTime = int64(m.Header.ExportTime) * 1000
+		for _, f := range fields {
+			if f.EnterpriseNo == 0 {
+				r.set(f.ID, f.Value)
+			}
+		}
+
+		// flowStartSysUpTime and flowEndSysUpTime are relative
+		// to the systemInitTimeMilliseconds, RFC 7011 4.2
+		if r.upTime && r.initTime > 0 {
+			r.setTime(22, int64(r.initTime+r.startUpTime))
+			r.setTime(21, int64(r.initTime+r.endUpTime))
+		}
+
+		// the effective sampling rate of the exporter's sampling table
+		if i < len(m.SamplingRates) && m.SamplingRates[i] > 0 {
+			r.SamplingRate = m.SamplingRates[i]
+		}
+
+		flows = append(flows, r.Flow)
+	}
+
+	return flows
+}
+
+// FromNetflowV9 returns the normalized flows of the netflow v9
+// message data records, the options data records are ignored
+func FromNetflowV9(m *netflow9.Message) []Flow {
+	flows := make([]Flow, 0, len(m.DataSets))
+
+	for i, fields := range m.DataSets {
+		if m.IsOptions(i) {
+			continue
+		}
+
+		r := newRecord(TypeNetflow9, m.AgentID)
+		for _, f := range fields {
+			r.set(f.ID, f.Value)
+		}
+
+		// the FIRST_SWITCHED and LAST_SWITCHED are the system uptime
+		if r.upTime {
+			r.setTime(22, m.Header.UnixMilli(uint32(r.startUpTime)))
+			r.setTime(21, m.Header.UnixMilli(uint32(r.endUpTime)))
+		}
+
+		if i < len(m.SamplingRates) && m.SamplingRates[i] > 0 {
+			r.SamplingRate = m.SamplingRates[i]
+		}
+
+		flows = append(flows, r.Flow)
+	}
+
+	return flows
+}
+
+// FromSFlow returns the normalized flows of the sFlow datagram
+// flow samples, a sampled packet per flow at the collected time
+func FromSFlow(d *sflow.SFDatagram) []Flow {
+	var (
+		flows = make([]Flow, 0, len(d.Samples))
+		now   = d.ColTime * 1000
+	)
+
+	for _, sample := range d.Samples {
+		r := newRecord(TypeSFlow, d.IPAddress.String())
+		r.Packets = 1
+		r.StartTime = now
+		r.EndTime = now
+
+		switch s := sample.(type) {
+		case *sflow.FlowSample:
+			// the compact interface format is in the two high bits
+			r.InputIf = s.Input & 0x3fffffff
+			r.OutputIf = s.Output & 0x3fffffff
+			r.SamplingRate = s.SamplingRate
+			r.setSFlowRecords(s.Records)
+		case *sflow.ExpandedFlowSample:
+			r.InputIf = s.Input
+			r.OutputIf = s.Output
+			r.SamplingRate = s.SamplingRate
+			r.setSFlowRecords(s.Records)
+		default:
+			continue
+		}
+
+		flows = append(flows, r.Flow)
+	}
+
+	return flows
+}
+
+func newRecord(flowType, exporter string) *record {
+	return &record{
+		Flow: Flow{
+			SchemaVersion: SchemaVersion,
+			FlowType:      flowType,
+			Exporter:      exporter,
+		},
+	}
+}
+
+// set maps the IANA information element to the flow field,
+// the Netflow v9 field types share the same ids
+func (r *record) set(id uint16, v interface{}) {
+	n, _ := toUint(v)
+
+	switch id {
+	case 8, 27:
+		r.SrcAddr = toIP(v)
+	case 12, 28:
+		r.DstAddr = toIP(v)
+	case 7:
+		r.SrcPort = uint16(n)
+	case 11:
+		r.DstPort = uint16(n)
+	case 4:
+		r.Protocol = uint8(n)
+	case 1:
+		r.Bytes = n
+	case 85:
+		if r.Bytes == 0 {
+			r.Bytes = n
+		}
+	case 2:
+		r.Packets = n
+	case 86:
+		if r.Packets == 0 {
+			r.Packets = n
+		}
+	case 6:
+		r.TCPFlags = uint16(n)
+	case 10:
+		r.InputIf = uint32(n)
+	case 14:
+		r.OutputIf = uint32(n)
+	case 58:
+		r.SrcVlan = uint16(n)
+	case 59:
+		r.DstVlan = uint16(n)
+	case 243:
+		if r.SrcVlan == 0 {
+			r.SrcVlan = uint16(n)
+		}
+	case 254:
+		if r.DstVlan == 0 {
+			r.DstVlan = uint16(n)
+		}
+	case 15, 62:
+		r.NextHop = toIP(v)
+	case 18, 63:
+		if r.NextHop == "" {
+			r.NextHop = toIP(v)
+		}
+	case 16:
+		r.SrcAS = uint32(n)
+	case 17:
+		r.DstAS = uint32(n)
+	case 150, 151:
+		r.setTime(id, int64(n)*1000)
+	case 152, 153:
+		r.setTime(id, int64(n))
+	case 154, 155:
+		r.setTime(id, int64(n/1e3))
+	case 156, 157:
+		r.setTime(id, int64(n/1e6))
+	case 158, 159:
+		r.setTime(id, r.exportTime-int64(n/1e3))
+	case 22:
+		r.startUpTime, r.upTime = n, true
+	case 21:
+		r.endUpTime, r.upTime = n, true
+	case 160:
+		r.initTime = n
+	case 34:
+		r.SamplingRate = uint32(n)
+	}
+}
+
+// setTime sets the start (even id) or end (odd id) time by the
+// element unless it's already set by a higher priority element,
+// the time elements are in the priority order 150 to 159 then the
+// system uptime relative 22 and 21
+func (r *record) setTime(id uint16, t int64) {
+	rank := id
+	if id == 21 || id == 22 {
+		rank = id + 1000
+	}
+
+	if id%2 == 0 {
+		if r.startBy == 0 || rank < r.startBy {
+			r.StartTime, r.startBy = t, rank
+		}
+		return
+	}
+
+	if r.endBy == 0 || rank < r.endBy {
+		r.EndTime, r.endBy = t, rank
+	}
+}
+
+// setSFlowRecords maps the sFlow sampled header and extended records
+func (r *record) setSFlowRecords(records map[string]sflow.Record) {
+	if p, ok := records["RawHeader"].(*packet.Packet); ok {
+		r.SrcVlan = uint16(p.L2.Vlan)
+
+		switch l3 := p.L3.(type) {
+		case packet.IPv4Header:
+			r.SrcAddr, r.DstAddr = l3.Src, l3.Dst
+			r.Protocol = uint8(l3.Protocol)
+			r.Bytes = uint64(l3.TotalLen)
+		case packet.IPv6Header:
+			r.SrcAddr, r.DstAddr = l3.Src, l3.Dst
+			r.Protocol = uint8(l3.NextHeader)
+			r.Bytes = uint64(l3.PayloadLen + packet.IPv6HLen)
+		}
+
+		switch l4 := p.L4.(type) {
+		case packet.TCPHeader:
+			r.SrcPort, r.DstPort = uint16(l4.SrcPort), uint16(l4.DstPort)
+			r.TCPFlags = uint16(l4.Flags)
+		case packet.UDPHeader:
+			r.SrcPort, r.DstPort = uint16(l4.SrcPort), uint16(l4.DstPort)
+		}
+	}
+
+	if s, ok := records["ExtSwitch"].(*sflow.ExtSwitchData); ok {
+		r.SrcVlan, r.DstVlan = uint16(s.SrcVlan), uint16(s.DstVlan)
+	}
+
+	if s, ok := records["ExtRouter"].(*sflow.ExtRouterData); ok {
+		r.NextHop = s.NextHop.String()
+	}
+
+	if s, ok := records["ExtGateway"].(*sflow.ExtGatewayData); ok {
+		r.SrcAS, r.DstAS = s.SrcAS, s.DstAS()
+		if r.NextHop == "" && s.NextHop != nil {
+			r.NextHop = s.NextHop.String()
+		}
+	}
+}
+
+// toUint returns the unsigned integer of the decoded value
+func toUint(v interface{}) (uint64, bool) {
+	switch v := v.(type) {
+	case uint8:
+		return uint64(v), true
+	case uint16:
+		return uint64(v), true
+	case uint32:
+		return uint64(v), true
+	case uint64:
+		return v, true
+	case int8:
+		return uint64(v), true
+	case int16:
+		return uint64(v), true
+	case int32:
+		return uint64(v), true
+	case int64:
+		return uint64(v), true
+	}
+
+	return 0, false
+}
+
+// toIP returns the string of the decoded address
+func toIP(v interface{}) string {
+	if ip, ok := v.(net.IP); ok {
+		return ip.String()
+	}
+
+	return ""
+}
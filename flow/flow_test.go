@@ -0,0 +1,208 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    flow_test.go
+//: details: normalized flow record unit tests
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package flow
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/VerizonDigital/vflow/ipfix"
+	"github.com/VerizonDigital/vflow/netflow/v9"
+	"github.com/VerizonDigital/vflow/packet"
+	"github.com/VerizonDigital/vflow/sflow"
+)
+
+func TestFromIPFIX(t *testing.T) {
+	m := &ipfix.Message{
+		AgentID: "192.0.2.1",
+		DataSets: [][]ipfix.DecodedField{
+			{
+				{ID: 8, Value: net.IP{10, 0, 0, 1}},
+				{ID: 12, Value: net.IP{10, 0, 0, 2}},
+				{ID: 7, Value: uint16(50000)},
+				{ID: 11, Value: uint16(443)},
+				{ID: 4, Value: uint8(6)},
+				{ID: 1, Value: uint64(1500)},
+				{ID: 2, Value: uint64(3)},
+				{ID: 6, Value: uint16(0x12)},
+				{ID: 10, Value: uint32(5)},
+				{ID: 14, Value: uint32(6)},
+				{ID: 58, Value: uint16(100)},
+				{ID: 15, Value: net.IP{10, 0, 0, 254}},
+				{ID: 16, Value: uint32(65000)},
+				{ID: 17, Value: uint32(15169)},
+				{ID: 152, Value: uint64(1483484685331)},
+				{ID: 153, Value: uint64(1483484686331)},
+				{ID: 34, Value: uint32(1000)},
+				{ID: 12232, Value: "mail", EnterpriseNo: 9},
+			},
+			{
+				{ID: 27, Value: net.ParseIP("2001:db8::1")},
+				{ID: 160, Value: uint64(1483484600000)},
+				{ID: 22, Value: uint32(1000)},
+				{ID: 21, Value: uint32(2000)},
+			},
+		},
+	}
+
+	expected := []Flow{
+		{
+			SchemaVersion: SchemaVersion, FlowType: TypeIPFIX, Exporter: "192.0.2.1",
+			SrcAddr: "10.0.0.1", DstAddr: "10.0.0.2", SrcPort: 50000, DstPort: 443, Protocol: 6,
+			Bytes: 1500, Packets: 3, TCPFlags: 0x12, InputIf: 5, OutputIf: 6, SrcVlan: 100,
+			NextHop: "10.0.0.254", SrcAS: 65000, DstAS: 15169,
+			StartTime: 1483484685331, EndTime: 1483484686331, SamplingRate: 1000,
+		},
+		{
+			SchemaVersion: SchemaVersion, FlowType: TypeIPFIX, Exporter: "192.0.2.1",
+			SrcAddr: "2001:db8::1", StartTime: 1483484601000, EndTime: 1483484602000,
+		},
+	}
+
+	if flows := FromIPFIX(m); !reflect.DeepEqual(flows, expected) {
+		t.Errorf("expected %+v, got %+v", expected, flows)
+	}
+}
+
+func TestFromIPFIXTimePriority(t *testing.T) {
+	m := &ipfix.Message{
+		DataSets: [][]ipfix.DecodedField{
+			{
+				{ID: 160, Value: uint64(1483484600000)},
+				{ID: 22, Value: uint32(1000)},
+				{ID: 21, Value: uint32(2000)},
+				{ID: 156, Value: uint64(1483484685000000000)},
+				{ID: 152, Value: uint64(1483484685331)},
+				{ID: 150, Value: uint32(1483484685)},
+				{ID: 153, Value: uint64(1483484686331)},
+				{ID: 157, Value: uint64(1483484686000000000)},
+			},
+		},
+	}
+
+	f := FromIPFIX(m)[0]
+	if f.StartTime != 1483484685000 || f.EndTime != 1483484686331 {
+		t.Error("expected the times by the priority, got", f.StartTime, f.EndTime)
+	}
+}
+
+func TestFromOptions(t *testing.T) {
+	m := &netflow9.Message{
+		DataSets: [][]netflow9.DecodedField{
+			{{ID: 8, Value: net.IP{10, 0, 0, 1}}, {ID: 2, Value: uint32(5)}},
+			{{ID: 2, Value: uint32(5)}, {ID: 34, Value: uint32(1000)}},
+		},
+		Options: []bool{false, true},
+	}
+
+	if flows := FromNetflowV9(m); len(flows) != 1 || flows[0].SrcAddr != "10.0.0.1" {
+		t.Error("expected the options data record ignored, got", flows)
+	}
+
+	im := &ipfix.Message{
+		DataSets: [][]ipfix.DecodedField{{{ID: 34, Value: uint32(1000)}}},
+		Options:  []bool{true},
+	}
+
+	if flows := FromIPFIX(im); len(flows) != 0 {
+		t.Error("expected the options data record ignored, got", flows)
+	}
+}
+
+func TestFromNetflowV9(t *testing.T) {
+	m := &netflow9.Message{
+		AgentID: "192.0.2.2",
+		Header:  netflow9.PacketHeader{SysUpTime: 360000, UNIXSecs: 1493918653},
+		DataSets: [][]netflow9.DecodedField{
+			{
+				{ID: 8, Value: net.IP{10, 0, 0, 1}},
+				{ID: 1, Value: uint64(80)},
+				{ID: 22, Value: uint32(300000)},
+				{ID: 21, Value: uint32(359000)},
+			},
+		},
+	}
+
+	flows := FromNetflowV9(m)
+	if len(flows) != 1 {
+		t.Fatal("expected 1 flow, got", len(flows))
+	}
+
+	f := flows[0]
+	if f.FlowType != TypeNetflow9 || f.SrcAddr != "10.0.0.1" || f.Bytes != 80 {
+		t.Error("unexpected flow", f)
+	}
+
+	if f.StartTime != 1493918593000 || f.EndTime != 1493918652000 {
+		t.Error("expected the absolute times, got", f.StartTime, f.EndTime)
+	}
+
+	// the LAST_SWITCHED after the header's uptime
+	m.DataSets[0][3].Value = uint32(360250)
+	if f = FromNetflowV9(m)[0]; f.EndTime != 1493918653250 {
+		t.Error("expected the end time after the export time, got", f.EndTime)
+	}
+}
+
+func TestFromSFlow(t *testing.T) {
+	d := &sflow.SFDatagram{
+		IPAddress: net.IP{192, 0, 2, 3},
+		ColTime:   1493918653,
+		Samples: []sflow.Sample{
+			&sflow.FlowSample{
+				SamplingRate: 4096,
+				Input:        536,
+				Output:       728,
+				Records: map[string]sflow.Record{
+					"RawHeader": &packet.Packet{
+						L2: packet.Datalink{Vlan: 10},
+						L3: packet.IPv4Header{Src: "10.1.8.5", Dst: "161.140.24.181", Protocol: 6, TotalLen: 1452},
+						L4: packet.TCPHeader{SrcPort: 443, DstPort: 56521, Flags: 16},
+					},
+					"ExtRouter": &sflow.ExtRouterData{NextHop: net.IP{115, 131, 251, 90}},
+					"ExtGateway": &sflow.ExtGatewayData{
+						NextHop:   net.IP{115, 131, 251, 1},
+						AS:        65000,
+						SrcAS:     15133,
+						DstASPath: []sflow.ASPathSegment{{Type: 2, AS: []uint32{3356, 2914}}},
+					},
+				},
+			},
+			"counter sample",
+		},
+	}
+
+	expected := []Flow{
+		{
+			SchemaVersion: SchemaVersion, FlowType: TypeSFlow, Exporter: "192.0.2.3",
+			SrcAddr: "10.1.8.5", DstAddr: "161.140.24.181", SrcPort: 443, DstPort: 56521, Protocol: 6,
+			Bytes: 1452, Packets: 1, TCPFlags: 16, InputIf: 536, OutputIf: 728, SrcVlan: 10,
+			NextHop: "115.131.251.90", SrcAS: 15133, DstAS: 2914, StartTime: 1493918653000, EndTime: 1493918653000, SamplingRate: 4096,
+		},
+	}
+
+	if flows := FromSFlow(d); !reflect.DeepEqual(flows, expected) {
+		t.Errorf("expected %+v, got %+v", expected, flows)
+	}
+}
@@ -0,0 +1,119 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    abstime.go
+//: details: IPFIX flow time fields to absolute time
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package ipfix
+
+// the absolute flow start and end elements in unix milliseconds
+const (
+	flowStartMilliseconds = 152
+	flowEndMilliseconds   = 153
+)
+
+// absoluteTime represents a flow time element and the
+// function that converts its value to unix milliseconds
+type absoluteTime struct {
+	id      uint16
+	convert func(v uint64, r timeRef) (uint64, bool)
+}
+
+// timeRef represents the references of the relative times
+type timeRef struct {
+	exportTime uint64 // export time in unix seconds
+	initTime   uint64 // systemInitTimeMilliseconds
+}
+
+var absoluteTimes = map[uint16]absoluteTime{
+	150: {flowStartMilliseconds, seconds},
+	151: {flowEndMilliseconds, seconds},
+	154: {flowStartMilliseconds, microseconds},
+	155: {flowEndMilliseconds, microseconds},
+	156: {flowStartMilliseconds, nanoseconds},
+	157: {flowEndMilliseconds, nanoseconds},
+	158: {flowStartMilliseconds, deltaMicroseconds},
+	159: {flowEndMilliseconds, deltaMicroseconds},
+	22:  {flowStartMilliseconds, sysUpTime},
+	21:  {flowEndMilliseconds, sysUpTime},
+}
+
+// AbsoluteTime converts the flow time fields of the data records to the
+// flowStartMilliseconds and flowEndMilliseconds in unix milliseconds.
+// the delta microseconds are relative to the export time and the system
+// uptime fields are relative to the systemInitTimeMilliseconds of the
+// record, the uptime fields stay as they are without it.
+func (m *Message) AbsoluteTime() {
+	for _, fields := range m.DataSets {
+		ref := timeRef{exportTime: uint64(m.Header.ExportTime)}
+		for _, f := range fields {
+			if f.ID == 160 && f.EnterpriseNo == 0 {
+				ref.initTime, _ = toUint(f.Value)
+			}
+		}
+
+		for i := range fields {
+			at, ok := absoluteTimes[fields[i].ID]
+			if !ok || fields[i].EnterpriseNo != 0 {
+				continue
+			}
+
+			v, ok := toUint(fields[i].Value)
+			if !ok {
+				continue
+			}
+
+			if v, ok = at.convert(v, ref); ok {
+				fields[i].ID = at.id
+				fields[i].Value = v
+			}
+		}
+	}
+}
+
+func seconds(v uint64, r timeRef) (uint64, bool) {
+	return v * 1000, true
+}
+
+func microseconds(v uint64, r timeRef) (uint64, bool) {
+	return v / 1e3, true
+}
+
+func nanoseconds(v uint64, r timeRef) (uint64, bool) {
+	return v / 1e6, true
+}
+
+// deltaMicroseconds converts the microseconds before the export time,
+// the delta before the unix epoch is invalid
+func deltaMicroseconds(v uint64, r timeRef) (uint64, bool) {
+	if v/1e3 > r.exportTime*1000 {
+		return 0, false
+	}
+
+	return r.exportTime*1000 - v/1e3, true
+}
+
+// sysUpTime converts the milliseconds since the system init time
+func sysUpTime(v uint64, r timeRef) (uint64, bool) {
+	if r.initTime == 0 {
+		return 0, false
+	}
+
+	return r.initTime + v, true
+}
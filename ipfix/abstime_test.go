@@ -0,0 +1,101 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    abstime_test.go
+//: details: IPFIX flow time fields to absolute time
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package ipfix
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAbsoluteTime(t *testing.T) {
+	m := &Message{
+		Header: MessageHeader{ExportTime: 1483484642},
+		DataSets: [][]DecodedField{
+			{
+				{ID: 160, Value: uint64(1483000000000)},
+				{ID: 22, Value: uint32(1000)},
+				{ID: 21, Value: uint32(5000)},
+			},
+			{
+				{ID: 158, Value: uint32(2500000)},
+				{ID: 159, Value: uint32(0)},
+				{ID: 150, Value: uint32(1483484600), EnterpriseNo: 9},
+			},
+			{
+				{ID: 154, Value: uint64(1483484600123456)},
+				{ID: 157, Value: uint64(1483484601123456789)},
+				{ID: 22, Value: uint32(1000)},
+			},
+			{
+				{ID: 158, Value: uint64(1483484643000000)},
+			},
+		},
+	}
+
+	m.AbsoluteTime()
+
+	expected := [][]DecodedField{
+		{
+			{ID: 160, Value: uint64(1483000000000)},
+			{ID: 152, Value: uint64(1483000001000)},
+			{ID: 153, Value: uint64(1483000005000)},
+		},
+		{
+			{ID: 152, Value: uint64(1483484639500)},
+			{ID: 153, Value: uint64(1483484642000)},
+			{ID: 150, Value: uint32(1483484600), EnterpriseNo: 9},
+		},
+		{
+			{ID: 152, Value: uint64(1483484600123)},
+			{ID: 153, Value: uint64(1483484601123)},
+			// no systemInitTimeMilliseconds in the record
+			{ID: 22, Value: uint32(1000)},
+		},
+		{
+			// the delta before the unix epoch
+			{ID: 158, Value: uint64(1483484643000000)},
+		},
+	}
+
+	for i := range expected {
+		for j := range expected[i] {
+			if m.DataSets[i][j] != expected[i][j] {
+				t.Errorf("record %d field %d expect %#v, got %#v", i, j, expected[i][j], m.DataSets[i][j])
+			}
+		}
+	}
+}
+
+func TestReceiveTime(t *testing.T) {
+	m := &Message{AgentID: "192.0.2.1", ReceiveTime: 1483484642123}
+
+	b, err := m.JSONMarshal(new(bytes.Buffer))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !strings.HasPrefix(string(b), `{"AgentID":"192.0.2.1","ReceiveTime":1483484642123,"Header":`) {
+		t.Error("expect the receive time after the agent id, got", string(b))
+	}
+}
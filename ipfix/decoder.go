@@ -28,14 +28,23 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	"github.com/VerizonDigital/vflow/reader"
 )
 
 // Decoder represents IPFIX payload and remote address
 type Decoder struct {
-	raddr  net.IP
-	reader *reader.Reader
+	raddr    net.IP
+	reader   *reader.Reader
+	agentID  string
+	port     int
+	pending  *Pending
+	sampling *Sampling
+	strict   bool
+	rcvd     int64
 }
 
 // MessageHeader represents IPFIX message header
@@ -72,9 +81,22 @@ type TemplateFieldSpecifier struct {
 
 // Message represents IPFIX decoded data
 type Message struct {
-	AgentID  string
-	Header   MessageHeader
-	DataSets [][]DecodedField
+	AgentID     string
+	ReceiveTime int64 // collector receive time in unix milliseconds
+	Header      MessageHeader
+	DataSets    [][]DecodedField
+
+	// SamplingRates represents the effective sampling rate of
+	// the data sets once the sampling table is set, zero unknown
+	SamplingRates []uint32
+
+	// Options represents the data sets that decoded by an options
+	// template, they're the exporter's metadata and not the flows
+	Options []bool
+
+	// Pending represents the buffered messages that
+	// decoded once their template arrived by this message
+	Pending []*Message
 }
 
 // DecodedField represents a decoded field
@@ -92,11 +114,74 @@ type SetHeader struct {
 
 type nonfatalError error
 
+// variableLength is the field length of the variable-length
+// information elements, RFC 7011 7
+const variableLength = 65535
+
 var rpcChan = make(chan RPCRequest, 1)
 
 // NewDecoder constructs a decoder
 func NewDecoder(raddr net.IP, b []byte) *Decoder {
-	return &Decoder{raddr, reader.NewReader(b)}
+	return &Decoder{raddr: raddr, reader: reader.NewReader(b)}
+}
+
+// SetAgentID sets the exporter identity instead of the remote
+// address, it uses as message agent id and templates cache key
+func (d *Decoder) SetAgentID(id string) {
+	d.agentID = id
+}
+
+// SetReceiveTime sets the time that the collector received the
+// message, the buffered data sets keep their own receive time
+func (d *Decoder) SetReceiveTime(t time.Time) {
+	d.rcvd = t.UnixNano() / int64(time.Millisecond)
+}
+
+// SetPending sets the pending buffer to keep the data sets
+// that their template hasn't arrived yet
+func (d *Decoder) SetPending(p *Pending) {
+	d.pending = p
+}
+
+// SetSampling sets the sampling table to learn the sampling rates
+// from the options data and annotate the data records by them
+func (d *Decoder) SetSampling(s *Sampling) {
+	d.sampling = s
+}
+
+// SetStrict sets the strict decoding, a data record with
+// an unknown information element is dropped
+func (d *Decoder) SetStrict(strict bool) {
+	d.strict = strict
+}
+
+// SetSourcePort sets the exporter's source port, it includes
+// in the templates scope next to the observation domain
+func (d *Decoder) SetSourcePort(port int) {
+	d.port = port
+}
+
+// exporter returns the exporter identity as string
+func (d *Decoder) exporter() string {
+	id := d.agentID
+	if id == "" {
+		id = d.raddr.String()
+	}
+
+	if d.port != 0 {
+		return net.JoinHostPort(id, strconv.Itoa(d.port))
+	}
+
+	return id
+}
+
+// tplScope returns the templates scope of the observation domain
+func (d *Decoder) tplScope(domainID uint32) TemplateScope {
+	if d.agentID != "" {
+		return TemplateScope{AgentID: d.agentID, DomainID: domainID, Port: d.port}
+	}
+
+	return TemplateScope{Addr: d.raddr, DomainID: domainID, Port: d.port}
 }
 
 // Decode decodes the IPFIX raw data
@@ -114,6 +199,11 @@ func (d *Decoder) Decode(mem MemCache) (*Message, error) {
 
 	// Add source IP address as Agent ID
 	msg.AgentID = d.raddr.String()
+	if d.agentID != "" {
+		msg.AgentID = d.agentID
+	}
+
+	msg.ReceiveTime = d.rcvd
 
 	// In case there are multiple non-fatal errors, collect them and report all of them.
 	// The rest of the received sets will still be interpreted, until a fatal error is encountered.
@@ -130,6 +220,8 @@ func (d *Decoder) Decode(mem MemCache) (*Message, error) {
 		}
 	}
 
+	d.annotateSampling(msg)
+
 	return msg, combineErrors(decodeErrors...)
 }
 
@@ -162,15 +254,25 @@ func (d *Decoder) decodeSet(mem MemCache, msg *Message) error {
 	// This check is somewhat redundant with the switch-clause below, but the retrieve() operation should not be executed inside the loop.
 	if setHeader.SetID > 255 {
 		var ok bool
-		tr, ok = mem.retrieve(setHeader.SetID, d.raddr)
+		tr, ok = mem.retrieve(setHeader.SetID, d.tplScope(msg.Header.DomainID))
 		if !ok {
-			select {
-			case rpcChan <- RPCRequest{
-				ID: setHeader.SetID,
-				IP: d.raddr,
-			}:
-			default:
+			// the other vflow servers know the exporters by ip address
+			if d.agentID == "" {
+				select {
+				case rpcChan <- RPCRequest{
+					ID:       setHeader.SetID,
+					IP:       d.raddr,
+					DomainID: msg.Header.DomainID,
+					Port:     d.port,
+				}:
+				default:
+				}
 			}
+
+			if d.pending != nil {
+				return d.bufferSet(msg, setHeader)
+			}
+
 			err = nonfatalError(fmt.Errorf("%s unknown ipfix template id# %d",
 				d.raddr.String(),
 				setHeader.SetID,
@@ -179,7 +281,13 @@ func (d *Decoder) decodeSet(mem MemCache, msg *Message) error {
 	}
 
 	// the next set should be greater than 4 bytes otherwise that's padding
-	for err == nil && setHeader.Length > uint16(d.reader.ReadCount()-startCount) && d.reader.Len() > 4 {
+	// but a template withdrawal record is exactly 4 bytes
+	minLen := 4
+	if setHeader.SetID == 2 || setHeader.SetID == 3 {
+		minLen = 3
+	}
+
+	for err == nil && setHeader.Length > uint16(d.reader.ReadCount()-startCount) && d.reader.Len() > minLen {
 		if setId := setHeader.SetID; setId == 2 || setId == 3 {
 			// Template record or template option record
 
@@ -189,6 +297,10 @@ func (d *Decoder) decodeSet(mem MemCache, msg *Message) error {
 				break
 			}
 
+			if d.withdraw(mem, msg, setId) {
+				continue
+			}
+
 			tr := TemplateRecord{}
 			if setId == 2 {
 				err = tr.unmarshal(d.reader)
@@ -196,7 +308,8 @@ func (d *Decoder) decodeSet(mem MemCache, msg *Message) error {
 				err = tr.unmarshalOpts(d.reader)
 			}
 			if err == nil {
-				mem.insert(tr.TemplateID, d.raddr, tr)
+				mem.insert(tr.TemplateID, d.tplScope(msg.Header.DomainID), tr)
+				d.decodePending(mem, msg, tr)
 			}
 		} else if setId >= 4 && setId <= 255 {
 			// Reserved set, do not read any records
@@ -204,9 +317,10 @@ func (d *Decoder) decodeSet(mem MemCache, msg *Message) error {
 		} else {
 			// Data set
 			var data []DecodedField
-			data, err = d.decodeData(tr)
+			data, err = d.decodeData(mem, msg.Header.DomainID, tr)
 			if err == nil {
 				msg.DataSets = append(msg.DataSets, data)
+				msg.Options = append(msg.Options, len(tr.ScopeFieldSpecifiers) > 0)
 			}
 		}
 	}
@@ -223,6 +337,95 @@ func (d *Decoder) decodeSet(mem MemCache, msg *Message) error {
 	return err
 }
 
+// withdraw handles the template withdrawal record, the template id
+// same as the set id withdraws all templates of the set, RFC 7011 8.1
+func (d *Decoder) withdraw(mem MemCache, msg *Message, setID uint16) bool {
+	var th TemplateHeader
+
+	b, err := d.reader.Peek(4)
+	if err != nil {
+		return false
+	}
+
+	if err = th.unmarshal(reader.NewReader(b)); err != nil || th.FieldCount != 0 {
+		return false
+	}
+
+	d.reader.Read(4)
+
+	if th.TemplateID == setID {
+		mem.withdrawAll(d.tplScope(msg.Header.DomainID), setID == 3)
+	} else {
+		mem.withdraw(th.TemplateID, d.tplScope(msg.Header.DomainID))
+	}
+
+	return true
+}
+
+// bufferSet keeps the data set in the pending buffer
+func (d *Decoder) bufferSet(msg *Message, setHeader *SetHeader) error {
+	b, err := d.reader.Read(int(setHeader.Length) - 4)
+	if err != nil {
+		return err
+	}
+
+	d.pending.add(d.exporter(), pendingKey{msg.Header.DomainID, setHeader.SetID}, pendingSet{
+		header:      msg.Header,
+		agentID:     msg.AgentID,
+		receiveTime: msg.ReceiveTime,
+		data:        append([]byte{}, b...),
+		timestamp:   time.Now(),
+	})
+
+	return nil
+}
+
+// decodePending decodes the buffered data sets of the template
+func (d *Decoder) decodePending(mem MemCache, msg *Message, tr TemplateRecord) {
+	if d.pending == nil {
+		return
+	}
+
+	sets := d.pending.take(d.exporter(), pendingKey{msg.Header.DomainID, tr.TemplateID})
+	if len(sets) < 1 {
+		return
+	}
+
+	// the padding is shorter than the minimum record length
+	minLen := 0
+	for _, fields := range [][]TemplateFieldSpecifier{tr.ScopeFieldSpecifiers, tr.FieldSpecifiers} {
+		for _, f := range fields {
+			if f.Length == variableLength {
+				minLen++
+			} else {
+				minLen += int(f.Length)
+			}
+		}
+	}
+
+	for _, s := range sets {
+		pd := d.listDecoder(s.data)
+		pm := &Message{AgentID: s.agentID, ReceiveTime: s.receiveTime, Header: s.header}
+
+		for minLen > 0 && pd.reader.Len() >= minLen {
+			data, err := pd.decodeData(mem, s.header.DomainID, tr)
+			if err != nil {
+				break
+			}
+			pm.DataSets = append(pm.DataSets, data)
+			pm.Options = append(pm.Options, len(tr.ScopeFieldSpecifiers) > 0)
+		}
+
+		if len(pm.DataSets) < 1 {
+			atomic.AddUint64(&d.pending.stats.DroppedCount, 1)
+			continue
+		}
+
+		atomic.AddUint64(&d.pending.stats.DecodedCount, 1)
+		msg.Pending = append(msg.Pending, pm)
+	}
+}
+
 // RFC 7011 - part 3.1. Message Header Format
 // 0                   1                   2                   3
 // 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
@@ -460,7 +663,7 @@ func (tr *TemplateRecord) unmarshalOpts(r *reader.Reader) error {
 		if err := tf.unmarshal(r); err != nil {
 			return err
 		}
-		tr.ScopeFieldSpecifiers = append(tr.FieldSpecifiers, tf)
+		tr.ScopeFieldSpecifiers = append(tr.ScopeFieldSpecifiers, tf)
 	}
 
 	for i := th.FieldCount - th.ScopeFieldCount; i > 0; i-- {
@@ -472,7 +675,7 @@ func (tr *TemplateRecord) unmarshalOpts(r *reader.Reader) error {
 	return nil
 }
 
-func (d *Decoder) decodeData(tr TemplateRecord) ([]DecodedField, error) {
+func (d *Decoder) decodeData(mem MemCache, domainID uint32, tr TemplateRecord) ([]DecodedField, error) {
 	var (
 		fields []DecodedField
 		err    error
@@ -480,54 +683,117 @@ func (d *Decoder) decodeData(tr TemplateRecord) ([]DecodedField, error) {
 	)
 	r := d.reader
 
-	for i := 0; i < len(tr.FieldSpecifiers); i++ {
-		b, err = r.Read(int(tr.FieldSpecifiers[i].Length))
+	// the scope fields come first in the options data record
+	for i := 0; i < len(tr.ScopeFieldSpecifiers); i++ {
+		b, err = readField(r, tr.ScopeFieldSpecifiers[i].Length)
 		if err != nil {
 			return nil, err
 		}
 
-		m, ok := InfoModel[ElementKey{
-			tr.FieldSpecifiers[i].EnterpriseNo,
-			tr.FieldSpecifiers[i].ElementID,
-		}]
-
+		m, ok := d.element(tr.ScopeFieldSpecifiers[i])
 		if !ok {
-			return nil, nonfatalError(fmt.Errorf("IPFIX element key (%d) not exist",
-				tr.FieldSpecifiers[i].ElementID))
+			return nil, nonfatalError(fmt.Errorf("IPFIX element key (%d) not exist (scope)",
+				tr.ScopeFieldSpecifiers[i].ElementID))
 		}
 
 		fields = append(fields, DecodedField{
-			ID:    m.FieldID,
-			Value: Interpret(&b, m.Type),
+			ID:           m.FieldID,
+			Value:        d.interpret(mem, domainID, b, m.Type),
+			EnterpriseNo: tr.ScopeFieldSpecifiers[i].EnterpriseNo,
 		})
 	}
 
-	for i := 0; i < len(tr.ScopeFieldSpecifiers); i++ {
-		b, err = r.Read(int(tr.ScopeFieldSpecifiers[i].Length))
+	for i := 0; i < len(tr.FieldSpecifiers); i++ {
+		b, err = readField(r, tr.FieldSpecifiers[i].Length)
 		if err != nil {
 			return nil, err
 		}
 
-		m, ok := InfoModel[ElementKey{
-			tr.ScopeFieldSpecifiers[i].EnterpriseNo,
-			tr.ScopeFieldSpecifiers[i].ElementID,
-		}]
-
+		m, ok := d.element(tr.FieldSpecifiers[i])
 		if !ok {
-			return nil, nonfatalError(fmt.Errorf("IPFIX element key (%d) not exist (scope)",
-				tr.ScopeFieldSpecifiers[i].ElementID))
+			return nil, nonfatalError(fmt.Errorf("IPFIX element key (%d) not exist",
+				tr.FieldSpecifiers[i].ElementID))
 		}
 
 		fields = append(fields, DecodedField{
 			ID:           m.FieldID,
-			Value:        Interpret(&b, m.Type),
-			EnterpriseNo: tr.ScopeFieldSpecifiers[i].EnterpriseNo,
+			Value:        d.interpret(mem, domainID, b, m.Type),
+			EnterpriseNo: tr.FieldSpecifiers[i].EnterpriseNo,
 		})
 	}
 
+	if len(tr.ScopeFieldSpecifiers) > 0 {
+		d.learnTypeInfo(fields)
+		d.learnSampling(domainID, fields)
+	}
+
 	return fields, nil
 }
 
+// element returns the information element of the field specifier,
+// the unknown element decodes as octet array unless it's strict
+func (d *Decoder) element(f TemplateFieldSpecifier) (InfoElementEntry, bool) {
+	key := ElementKey{f.EnterpriseNo, f.ElementID}
+
+	// the site overrides for the exporter
+	if m, ok := exporterElement(d.agent(), key); ok {
+		return m, true
+	}
+
+	// the enterprise elements that the exporter announced
+	if f.EnterpriseNo != 0 {
+		if m, ok := overlayElement(d.agent(), key); ok {
+			return m, true
+		}
+	}
+
+	m, ok := InfoModel[key]
+	if ok {
+		return m, true
+	}
+
+	seenUnknown(key)
+
+	if d.strict {
+		return m, false
+	}
+
+	return InfoElementEntry{FieldID: f.ElementID, Type: OctetArray}, true
+}
+
+// RFC 7011 7. Variable-Length Information Element
+// the length 65535 in the template indicates a variable-length field
+// and the length is carried in the data record, 1 byte for less than
+// 255 bytes otherwise 255 followed by 2 bytes length
+//
+// 0                   1                   2                   3
+// 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |      255      |      Length (0 to 65535)      |       IE      |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+func readField(r *reader.Reader, length uint16) ([]byte, error) {
+	if length != variableLength {
+		return r.Read(int(length))
+	}
+
+	n, err := r.Uint8()
+	if err != nil {
+		return nil, err
+	}
+
+	if n < 255 {
+		return r.Read(int(n))
+	}
+
+	l, err := r.Uint16()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Read(int(l))
+}
+
 func combineErrors(errorSlice ...error) (err error) {
 	switch len(errorSlice) {
 	case 0:
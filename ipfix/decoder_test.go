@@ -23,8 +23,10 @@
 package ipfix
 
 import (
+	"encoding/binary"
 	"net"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -160,6 +162,26 @@ func TestDecodeOptsTemplate(t *testing.T) {
 	}
 }
 
+func TestDecodeAgentID(t *testing.T) {
+	ip := net.ParseIP("127.0.0.1")
+	mCache := NewMemCache()
+	d := NewDecoder(ip, tpl)
+	d.SetAgentID("router1.example.com")
+	msg, err := d.Decode(mCache)
+	if err != nil {
+		t.Error("unexpected error happened:", err)
+	}
+	if msg.AgentID != "router1.example.com" {
+		t.Error("expect AgentID router1.example.com, got", msg.AgentID)
+	}
+	if _, ok := mCache.retrieve(256, TemplateScope{Addr: ip, DomainID: 33792}); ok {
+		t.Error("expected the template keyed by agent id not by remote address")
+	}
+	if _, ok := mCache.retrieve(256, TemplateScope{AgentID: "router1.example.com", DomainID: 33792}); !ok {
+		t.Error("expected the template keyed by agent id")
+	}
+}
+
 func BenchmarkDecodeTemplate(b *testing.B) {
 	ip := net.ParseIP("127.0.0.1")
 	mCache := GetCache("cache.file")
@@ -211,3 +233,94 @@ func TestUnknownDatasetsMessage(t *testing.T) {
 		t.Error("Received unexpected erorr:", err)
 	}
 }
+
+func TestDecodeVariableLength(t *testing.T) {
+	ip := net.ParseIP("127.0.0.1")
+	mCache := NewMemCache()
+	appName := strings.Repeat("a", 300)
+
+	b := []byte{
+		0x0, 0xa, 0x0, 0x0, 0x5a, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0, 0x1,
+		// template 256: sourceIPv4Address, interfaceName and applicationName (variable-length)
+		0x0, 0x2, 0x0, 0x14, 0x1, 0x0, 0x0, 0x3, 0x0, 0x8, 0x0, 0x4, 0x0, 0x52, 0xff, 0xff, 0x0, 0x60, 0xff, 0xff,
+		// options template 257: scope interfaceName (variable-length), interfaceDescription (variable-length)
+		0x0, 0x3, 0x0, 0x12, 0x1, 0x1, 0x0, 0x2, 0x0, 0x1, 0x0, 0x52, 0xff, 0xff, 0x0, 0x53, 0xff, 0xff,
+	}
+
+	// data set 256, interfaceName with 1 byte and applicationName with 3 bytes length
+	set := []byte{0x1, 0x0, 0x0, 0x0, 0xc0, 0x0, 0x2, 0x1, 0x4, 'e', 't', 'h', '0', 0xff, 0x1, 0x2c}
+	set = append(set, appName...)
+	binary.BigEndian.PutUint16(set[2:4], uint16(len(set)))
+	b = append(b, set...)
+
+	// options data set 257
+	b = append(b, 0x1, 0x1, 0x0, 0xf, 0x4, 'e', 't', 'h', '0', 0x5, 'u', 'p', 'l', 'n', 'k')
+	binary.BigEndian.PutUint16(b[2:4], uint16(len(b)))
+
+	d := NewDecoder(ip, b)
+	msg, err := d.Decode(mCache)
+	if err != nil {
+		t.Fatal("unexpected error happened:", err)
+	}
+
+	if len(msg.DataSets) != 2 {
+		t.Fatal("expected 2 data sets, got", len(msg.DataSets))
+	}
+
+	fields := msg.DataSets[0]
+	if len(fields) != 3 || fields[1].Value != "eth0" || fields[2].Value != appName {
+		t.Error("unexpected variable-length fields", fields)
+	}
+
+	fields = msg.DataSets[1]
+	if len(fields) != 2 || fields[0].ID != 82 || fields[0].Value != "eth0" || fields[1].Value != "uplnk" {
+		t.Error("unexpected variable-length scope fields", fields)
+	}
+
+	if msg.IsOptions(0) || !msg.IsOptions(1) {
+		t.Error("expected the options data set marked, got", msg.Options)
+	}
+}
+
+func TestDecodeUnknownElement(t *testing.T) {
+	ip := net.ParseIP("127.0.0.1")
+	b := []byte{
+		0x0, 0xa, 0x0, 0x30, 0x5a, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0, 0x1,
+		// template 256: sourceIPv4Address and VMware element 893
+		0x0, 0x2, 0x0, 0x14, 0x1, 0x0, 0x0, 0x2, 0x0, 0x8, 0x0, 0x4, 0x83, 0x7d, 0x0, 0x4, 0x0, 0x0, 0x1a, 0xdc,
+		// data set 256
+		0x1, 0x0, 0x0, 0xc, 0xc0, 0x0, 0x2, 0x1, 0x0, 0x0, 0x0, 0x2a,
+	}
+
+	msg, err := NewDecoder(ip, b).Decode(NewMemCache())
+	if err != nil {
+		t.Fatal("unexpected error happened:", err)
+	}
+
+	if len(msg.DataSets) != 1 {
+		t.Fatal("expected 1 data set, got", len(msg.DataSets))
+	}
+
+	f := msg.DataSets[0][1]
+	if f.ID != 893 || f.EnterpriseNo != 6876 || !reflect.DeepEqual(f.Value, []byte{0x0, 0x0, 0x0, 0x2a}) {
+		t.Error("expected the unknown element as octet array, got", f)
+	}
+
+	var seen bool
+	for _, e := range UnknownElements() {
+		if e.EnterpriseNo == 6876 && e.ElementID == 893 && e.Count > 0 {
+			seen = true
+		}
+	}
+	if !seen {
+		t.Error("expected the unknown element counted, got", UnknownElements())
+	}
+
+	// the strict decoding drops the data record
+	d := NewDecoder(ip, b)
+	d.SetStrict(true)
+	msg, err = d.Decode(NewMemCache())
+	if err == nil || len(msg.DataSets) != 0 {
+		t.Error("expected error and no data set, got", err, msg.DataSets)
+	}
+}
@@ -0,0 +1,265 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    dictionary.go
+//: details: IPFIX layered information elements dictionaries
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package ipfix
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Dictionary represents the information elements layers,
+// the later layers override the earlier ones: the IANA
+// registry, the vendor packs and the site overrides
+type Dictionary struct {
+	// IANAFile is the IANA registry CSV, empty uses the built-in
+	IANAFile string
+	// Packs are the enabled vendor packs
+	Packs []string
+	// SiteFile overrides the elements for all the exporters
+	SiteFile string
+	// ExportersFile overrides the elements per exporter
+	ExportersFile string
+}
+
+// elementsFile represents the ipfix.elements YAML format
+// enterprise number -> element id -> [name, type]
+type elementsFile map[uint32]map[uint16][]string
+
+// the maximum enterprise element id, the first bit is the enterprise
+// bit; the IANA ids above it are the Netflow v9 vendor fields
+const maxElementID = 0x7fff
+
+var (
+	// builtinInfoModel is the IANA information model that vFlow ships
+	builtinInfoModel = InfoModel
+
+	// exporterModels holds the per exporter overrides
+	exporterModels atomic.Value
+)
+
+// Load builds the InfoModel and the per exporter overrides
+// through the layers, it returns the validation errors and
+// the invalid elements are skipped. it should be called
+// before the decoders start.
+func (dict Dictionary) Load() []error {
+	var (
+		model     = make(IANAInfoModel, len(builtinInfoModel))
+		exporters = make(map[string]IANAInfoModel)
+		iana      = builtinInfoModel
+		errs      []error
+	)
+
+	if dict.IANAFile != "" {
+		m, e := LoadIANAFile(dict.IANAFile)
+		if m != nil {
+			iana = m
+		}
+		errs = append(errs, e...)
+	}
+
+	merge(model, iana)
+
+	for _, name := range dict.Packs {
+		pack, ok := vendorPacks[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown vendor pack: %s", name))
+			continue
+		}
+
+		merge(model, pack)
+	}
+
+	if dict.SiteFile != "" {
+		var elements elementsFile
+		e := loadYAML(dict.SiteFile, &elements)
+		m, ve := elements.model(dict.SiteFile)
+		merge(model, m)
+		errs = append(append(errs, e...), ve...)
+	}
+
+	if dict.ExportersFile != "" {
+		var files map[string]elementsFile
+		errs = append(errs, loadYAML(dict.ExportersFile, &files)...)
+		for exporter, elements := range files {
+			m, e := elements.model(dict.ExportersFile + ": " + exporter)
+			exporters[exporter] = m
+			errs = append(errs, e...)
+		}
+	}
+
+	InfoModel = model
+	exporterModels.Store(exporters)
+
+	return errs
+}
+
+// LoadIANAFile loads the IANA information elements registry CSV
+// id,name,dataType,semantics,status,units; the unassigned and
+// reserved elements that have no data type are skipped.
+func LoadIANAFile(file string) (IANAInfoModel, []error) {
+	var (
+		model = make(IANAInfoModel)
+		errs  []error
+	)
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, []error{err}
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+
+	for line := 1; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, append(errs, fmt.Errorf("%s: %v", file, err))
+		}
+
+		// skips the header row and the reserved or unassigned
+		// ranges, they don't have the abstract data type
+		if len(record) < 3 || record[2] == "" || (line == 1 && record[0] == "ElementID") {
+			continue
+		}
+
+		id, err := strconv.ParseUint(record[0], 10, 16)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: invalid element id %q", file, line, record[0]))
+			continue
+		}
+
+		m, err := newElement(0, uint16(id), record[1:3])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: %v", file, line, err))
+			continue
+		}
+
+		model[ElementKey{0, uint16(id)}] = m
+	}
+
+	return model, errs
+}
+
+// model validates the elements and returns the valid ones
+func (elements elementsFile) model(src string) (IANAInfoModel, []error) {
+	var (
+		model = make(IANAInfoModel)
+		errs  []error
+	)
+
+	for PEN, ids := range elements {
+		for id, prop := range ids {
+			m, err := newElement(PEN, id, prop)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: element %d/%d: %v", src, PEN, id, err))
+				continue
+			}
+
+			model[ElementKey{PEN, id}] = m
+		}
+	}
+
+	return model, errs
+}
+
+// newElement validates the element id and its [name, type]
+func newElement(PEN uint32, id uint16, prop []string) (InfoElementEntry, error) {
+	if PEN != 0 && id > maxElementID {
+		return InfoElementEntry{}, fmt.Errorf("element id out of range")
+	}
+
+	if len(prop) < 2 || prop[0] == "" {
+		return InfoElementEntry{}, fmt.Errorf("expected [name, type]")
+	}
+
+	t, ok := FieldTypes[prop[1]]
+	if !ok {
+		return InfoElementEntry{}, fmt.Errorf("unknown data type %s", prop[1])
+	}
+
+	return InfoElementEntry{FieldID: id, Name: prop[0], Type: t}, nil
+}
+
+// loadYAML decodes the file if it exists
+func loadYAML(file string, v interface{}) []error {
+	b, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return []error{err}
+	}
+
+	if err = yaml.Unmarshal(b, v); err != nil {
+		return []error{fmt.Errorf("%s: %v", file, err)}
+	}
+
+	return nil
+}
+
+// merge copies the src elements over the dst elements
+func merge(dst, src IANAInfoModel) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+// exporterElement returns the information element that the
+// site overrides for the exporter
+func exporterElement(agent string, key ElementKey) (InfoElementEntry, bool) {
+	models, _ := exporterModels.Load().(map[string]IANAInfoModel)
+	if len(models) == 0 {
+		return InfoElementEntry{}, false
+	}
+
+	m, ok := models[agent][key]
+
+	return m, ok
+}
+
+// elementName returns the element name as the exporter's
+// elements decoded, empty if the element is unknown
+func elementName(agent string, key ElementKey) string {
+	if m, ok := exporterElement(agent, key); ok {
+		return m.Name
+	}
+
+	if key.EnterpriseNo != 0 {
+		if m, ok := overlayElement(agent, key); ok {
+			return m.Name
+		}
+	}
+
+	return InfoModel[key].Name
+}
@@ -0,0 +1,185 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    dictionary_test.go
+//: details: IPFIX layered information elements dictionaries unit tests
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package ipfix
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDictionaryLayers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer Dictionary{}.Load()
+
+	site := filepath.Join(dir, "ipfix.elements")
+	ioutil.WriteFile(site, []byte(`
+0:
+  8: [srcAddr, ipv4Address]
+9:
+  12232: [applicationName, string]
+  12233: [invalidType, unsigned128]
+  40000: [invalidID, string]
+`), 0644)
+
+	exporters := filepath.Join(dir, "ipfix.exporters")
+	ioutil.WriteFile(exporters, []byte(`
+192.0.2.1:
+  0:
+    95: [appID, string]
+`), 0644)
+
+	errs := Dictionary{SiteFile: site, ExportersFile: exporters}.Load()
+	if len(errs) != 2 {
+		t.Error("expected 2 validation errors, got", errs)
+	}
+
+	if m := InfoModel[ElementKey{0, 8}]; m.Name != "srcAddr" {
+		t.Error("expected the site element overrides IANA, got", m)
+	}
+
+	if m := InfoModel[ElementKey{0, 1}]; m.Name != "octetDeltaCount" {
+		t.Error("expected the built-in element merged, got", m)
+	}
+
+	if m := InfoModel[ElementKey{9, 12232}]; m.Type != String {
+		t.Error("expected the enterprise element, got", m)
+	}
+
+	if _, ok := InfoModel[ElementKey{9, 12233}]; ok {
+		t.Error("expected the invalid element skipped")
+	}
+
+	f := TemplateFieldSpecifier{ElementID: 95, Length: 4}
+	if m, _ := NewDecoder(net.ParseIP("192.0.2.1"), nil).element(f); m.Name != "appID" || m.Type != String {
+		t.Error("expected the exporter element, got", m)
+	}
+
+	if m, _ := NewDecoder(net.ParseIP("192.0.2.2"), nil).element(f); m.Name != "applicationId" {
+		t.Error("expected the IANA element for other exporters, got", m)
+	}
+}
+
+func TestDictionaryYAMLError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer Dictionary{}.Load()
+
+	site := filepath.Join(dir, "ipfix.elements")
+	ioutil.WriteFile(site, []byte("0: [8: srcAddr"), 0644)
+
+	errs := Dictionary{SiteFile: site, Packs: []string{"unknown"}}.Load()
+	if len(errs) != 2 {
+		t.Error("expected the YAML and the vendor pack errors, got", errs)
+	}
+
+	if m := InfoModel[ElementKey{0, 8}]; m.Name != "sourceIPv4Address" {
+		t.Error("expected the built-in model, got", m)
+	}
+
+	if errs := (Dictionary{SiteFile: filepath.Join(dir, "none")}).Load(); len(errs) != 0 {
+		t.Error("expected the missing site file ignored, got", errs)
+	}
+}
+
+func TestLoadIANAFile(t *testing.T) {
+	model, errs := LoadIANAFile("../scripts/ipfix-information-elements.csv")
+	if len(errs) != 0 {
+		t.Error("unexpected error happened:", errs)
+	}
+
+	for _, id := range []uint16{1, 8, 27, 152} {
+		if model[ElementKey{0, id}] != builtinInfoModel[ElementKey{0, id}] {
+			t.Error("expected the IANA element as built-in, got", model[ElementKey{0, id}])
+		}
+	}
+
+	if _, ok := model[ElementKey{0, 97}]; ok {
+		t.Error("expected the element without data type skipped")
+	}
+
+	// the registry as published by IANA with the header row
+	model, errs = LoadIANAFile("testdata/ipfix-information-elements.csv")
+	if len(errs) != 0 {
+		t.Error("unexpected error happened:", errs)
+	}
+
+	if len(model) != 4 {
+		t.Error("expected 4 elements, got", len(model))
+	}
+
+	for _, id := range []uint16{1, 2, 8, 152} {
+		if model[ElementKey{0, id}] != builtinInfoModel[ElementKey{0, id}] {
+			t.Error("expected the IANA element as built-in, got", model[ElementKey{0, id}])
+		}
+	}
+
+	if _, errs := LoadIANAFile("/none"); len(errs) != 1 {
+		t.Error("expected the missing file error")
+	}
+}
+
+func TestVendorPacks(t *testing.T) {
+	defer Dictionary{}.Load()
+
+	for name, pack := range vendorPacks {
+		for k, m := range pack {
+			if m.Type == Unknown || m.Name == "" || m.FieldID != k.ElementID {
+				t.Error(name, "invalid element", k, m)
+			}
+
+			if k.EnterpriseNo != 0 && k.ElementID > maxElementID {
+				t.Error(name, "element id out of range", k)
+			}
+		}
+	}
+
+	if errs := (Dictionary{Packs: []string{"cisco", "juniper", "ntop"}}).Load(); len(errs) != 0 {
+		t.Fatal("unexpected error happened:", errs)
+	}
+
+	if m := InfoModel[ElementKey{9, 12235}]; m.Type != String {
+		t.Error("expected the cisco element, got", m)
+	}
+
+	if m := InfoModel[ElementKey{2636, 137}]; m.Type != Uint64 {
+		t.Error("expected the juniper element, got", m)
+	}
+
+	if m := InfoModel[ElementKey{0, 57590}]; m.Name != "L7_PROTO" {
+		t.Error("expected the ntop Netflow v9 field, got", m)
+	}
+
+	if _, ok := InfoModel[ElementKey{6876, 880}]; ok {
+		t.Error("expected the vmware pack disabled")
+	}
+}
@@ -0,0 +1,418 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    file.go
+//: details: IPFIX file format reader and writer - RFC 5655
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package ipfix
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileWriter represents IPFIX file writer, the messages are
+// stored as they are received in a file per exporter and the
+// files are rotated by size and time. Each file begins with the
+// current templates of the exporter so it's self-contained
+type FileWriter struct {
+	dir      string
+	maxSize  int64
+	interval time.Duration
+	files    map[string]*exporterFile
+	sync.Mutex
+}
+
+// FileReader represents IPFIX file reader
+type FileReader struct {
+	r *bufio.Reader
+}
+
+type exporterFile struct {
+	f       *os.File
+	dir     string
+	size    int64
+	opened  time.Time
+	written time.Time
+	domains map[uint32]*domainTemplates
+}
+
+type domainTemplates struct {
+	seqNo uint32
+	// raw template records by template id for set 2 and 3
+	sets [2]map[uint16][]byte
+}
+
+const (
+	msgHeaderLen  = 16
+	setHeaderLen  = 4
+	maxMessageLen = 65535
+)
+
+var (
+	errFileMessage  = errors.New("invalid ipfix file message")
+	errFileTemplate = errors.New("invalid ipfix file template record")
+)
+
+// NewFileWriter constructs IPFIX file writer, the maxSize is in bytes
+// and zero maxSize or interval disables that rotation condition
+func NewFileWriter(dir string, maxSize int64, interval time.Duration) *FileWriter {
+	return &FileWriter{
+		dir:      dir,
+		maxSize:  maxSize,
+		interval: interval,
+		files:    make(map[string]*exporterFile),
+	}
+}
+
+// Write writes an IPFIX message that received from the exporter
+func (w *FileWriter) Write(exporter string, b []byte) error {
+	if len(b) < msgHeaderLen || binary.BigEndian.Uint16(b[0:2]) != 10 {
+		return errFileMessage
+	}
+
+	length := int(binary.BigEndian.Uint16(b[2:4]))
+	if length < msgHeaderLen || length > len(b) {
+		return errFileMessage
+	}
+	b = b[:length]
+
+	w.Lock()
+	defer w.Unlock()
+
+	ef, ok := w.files[exporter]
+	if !ok {
+		ef = &exporterFile{
+			dir:     filepath.Join(w.dir, fileExporterDir(exporter)),
+			domains: make(map[uint32]*domainTemplates),
+		}
+		w.files[exporter] = ef
+	}
+
+	now := time.Now()
+	if ef.f == nil ||
+		(w.maxSize > 0 && ef.size+int64(len(b)) > w.maxSize) ||
+		(w.interval > 0 && now.Sub(ef.opened) >= w.interval) {
+		if err := ef.rotate(now); err != nil {
+			return err
+		}
+	}
+
+	if err := ef.write(b); err != nil {
+		return err
+	}
+
+	ef.written = now
+	ef.learn(b)
+
+	return nil
+}
+
+// CloseIdle closes the files of the exporters that haven't sent a
+// message during the idle duration, their templates are kept so the
+// next file of the exporter begins with them
+func (w *FileWriter) CloseIdle(idle time.Duration) int {
+	var n int
+
+	deadline := time.Now().Add(-idle)
+
+	w.Lock()
+	defer w.Unlock()
+
+	for _, ef := range w.files {
+		if ef.f == nil || ef.written.After(deadline) {
+			continue
+		}
+
+		ef.f.Close()
+		ef.f = nil
+		n++
+	}
+
+	return n
+}
+
+// Close closes all of the open files
+func (w *FileWriter) Close() error {
+	var err error
+
+	w.Lock()
+	defer w.Unlock()
+
+	for _, ef := range w.files {
+		if ef.f == nil {
+			continue
+		}
+		if cErr := ef.f.Close(); cErr != nil {
+			err = cErr
+		}
+		ef.f = nil
+	}
+
+	return err
+}
+
+func (ef *exporterFile) write(b []byte) error {
+	n, err := ef.f.Write(b)
+	ef.size += int64(n)
+
+	return err
+}
+
+func (ef *exporterFile) rotate(now time.Time) error {
+	if ef.f != nil {
+		ef.f.Close()
+		ef.f = nil
+	}
+
+	if err := os.MkdirAll(ef.dir, 0755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%09d.ipfix", now.UTC().Format("20060102150405"), now.Nanosecond())
+	f, err := os.OpenFile(filepath.Join(ef.dir, name), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	ef.f = f
+	ef.size = 0
+	ef.opened = now
+
+	// embed the known templates at the start of the file
+	for _, domainID := range ef.domainIDs() {
+		for _, msg := range ef.domains[domainID].messages(domainID, uint32(now.Unix())) {
+			if err := ef.write(msg); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ef *exporterFile) domainIDs() []uint32 {
+	var ids []uint32
+	for id := range ef.domains {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+// learn keeps track of the templates and the template withdrawals
+func (ef *exporterFile) learn(b []byte) {
+	domainID := binary.BigEndian.Uint32(b[12:16])
+
+	dt, ok := ef.domains[domainID]
+	if !ok {
+		dt = &domainTemplates{
+			sets: [2]map[uint16][]byte{
+				make(map[uint16][]byte),
+				make(map[uint16][]byte),
+			},
+		}
+		ef.domains[domainID] = dt
+	}
+
+	dt.seqNo = binary.BigEndian.Uint32(b[8:12])
+
+	for b = b[msgHeaderLen:]; len(b) >= setHeaderLen; {
+		setID := binary.BigEndian.Uint16(b[0:2])
+		setLen := int(binary.BigEndian.Uint16(b[2:4]))
+		if setLen < setHeaderLen || setLen > len(b) {
+			return
+		}
+
+		if setID == 2 || setID == 3 {
+			dt.learnSet(setID, b[setHeaderLen:setLen])
+		}
+
+		b = b[setLen:]
+	}
+}
+
+func (dt *domainTemplates) learnSet(setID uint16, b []byte) {
+	records := dt.sets[setID-2]
+
+	// the template id zero indicates padding
+	for len(b) >= 4 && binary.BigEndian.Uint16(b[0:2]) != 0 {
+		tplID := binary.BigEndian.Uint16(b[0:2])
+		fieldCount := binary.BigEndian.Uint16(b[2:4])
+
+		// template withdrawal, RFC 7011 8.1
+		if fieldCount == 0 {
+			if tplID == setID {
+				for id := range records {
+					delete(records, id)
+				}
+			} else {
+				delete(records, tplID)
+			}
+			b = b[4:]
+			continue
+		}
+
+		n, err := templateRecordLen(setID, b)
+		if err != nil {
+			return
+		}
+
+		records[tplID] = append([]byte{}, b[:n]...)
+		b = b[n:]
+	}
+}
+
+// templateRecordLen returns the raw length of a template record
+func templateRecordLen(setID uint16, b []byte) (int, error) {
+	fieldCount := int(binary.BigEndian.Uint16(b[2:4]))
+	n := 4
+	if setID == 3 {
+		n = 6
+	}
+
+	for i := 0; i < fieldCount; i++ {
+		if len(b) < n+4 {
+			return 0, errFileTemplate
+		}
+
+		if b[n]&0x80 != 0 {
+			n += 8
+		} else {
+			n += 4
+		}
+	}
+
+	if n > len(b) {
+		return 0, errFileTemplate
+	}
+
+	return n, nil
+}
+
+// messages builds IPFIX messages that carry all of the templates
+func (dt *domainTemplates) messages(domainID, exportTime uint32) [][]byte {
+	var msgs [][]byte
+
+	for i, records := range dt.sets {
+		var ids []int
+		for id := range records {
+			ids = append(ids, int(id))
+		}
+		sort.Ints(ids)
+
+		var msg []byte
+		for _, id := range ids {
+			r := records[uint16(id)]
+			if msg != nil && len(msg)+len(r) > maxMessageLen {
+				msgs = append(msgs, msg)
+				msg = nil
+			}
+
+			if msg == nil {
+				msg = make([]byte, msgHeaderLen+setHeaderLen)
+				binary.BigEndian.PutUint16(msg[0:2], 10)
+				binary.BigEndian.PutUint32(msg[4:8], exportTime)
+				binary.BigEndian.PutUint32(msg[8:12], dt.seqNo)
+				binary.BigEndian.PutUint32(msg[12:16], domainID)
+				binary.BigEndian.PutUint16(msg[16:18], uint16(i+2))
+			}
+
+			msg = append(msg, r...)
+		}
+
+		if msg != nil {
+			msgs = append(msgs, msg)
+		}
+	}
+
+	for _, msg := range msgs {
+		binary.BigEndian.PutUint16(msg[2:4], uint16(len(msg)))
+		binary.BigEndian.PutUint16(msg[18:20], uint16(len(msg)-msgHeaderLen))
+	}
+
+	return msgs
+}
+
+// fileExporterDir returns a directory name for the exporter
+// it replaces the IPv6 colons to keep it portable
+func fileExporterDir(exporter string) string {
+	return strings.NewReplacer(":", "-", "/", "_", "\\", "_").Replace(exporter)
+}
+
+// FileExporter returns the exporter of an IPFIX file that written
+// by FileWriter, the agent id is empty if the exporter is an IP address
+func FileExporter(file string) (net.IP, string) {
+	name := filepath.Base(filepath.Dir(file))
+
+	if ip := net.ParseIP(name); ip != nil {
+		return ip, ""
+	}
+
+	if ip := net.ParseIP(strings.Replace(name, "-", ":", -1)); ip != nil {
+		return ip, ""
+	}
+
+	return net.IPv4zero, name
+}
+
+// NewFileReader constructs IPFIX file reader
+func NewFileReader(r io.Reader) *FileReader {
+	return &FileReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next IPFIX message in the file,
+// it returns io.EOF at the end of the file
+func (r *FileReader) Next() ([]byte, error) {
+	hdr, err := r.r.Peek(msgHeaderLen)
+	if err != nil {
+		if err == io.EOF && len(hdr) > 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	if binary.BigEndian.Uint16(hdr[0:2]) != 10 {
+		return nil, errFileMessage
+	}
+
+	length := int(binary.BigEndian.Uint16(hdr[2:4]))
+	if length < msgHeaderLen {
+		return nil, errFileMessage
+	}
+
+	b := make([]byte, length)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return b, nil
+}
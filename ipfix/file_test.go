@@ -0,0 +1,174 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    file_test.go
+//: details: IPFIX file format reader and writer tests
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package ipfix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileWriterRotateTemplates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vflow-ipfix-file")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dataMsg := append([]byte{}, unknownDatasetMessage...)
+	binary.BigEndian.PutUint16(dataMsg[2:4], uint16(len(dataMsg)))
+
+	w := NewFileWriter(dir, int64(len(multiMessage)), time.Hour)
+	if err := w.Write("2001:db8::1", multiMessage); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if err := w.Write("2001:db8::1", dataMsg); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	w.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "2001-db8--1", "*.ipfix"))
+	if len(files) != 2 {
+		t.Fatal("expect 2 rotated files, got", len(files))
+	}
+
+	ip, agentID := FileExporter(files[1])
+	if !ip.Equal(net.ParseIP("2001:db8::1")) || agentID != "" {
+		t.Error("unexpected exporter", ip, agentID)
+	}
+
+	// the second file should be decoded without the first one
+	b, err := ioutil.ReadFile(files[1])
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	var (
+		mCache   = NewMemCache()
+		r        = NewFileReader(bytes.NewReader(b))
+		msgCount int
+		dataSets int
+	)
+
+	for {
+		msg, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		m, err := NewDecoder(ip, msg).Decode(mCache)
+		if err != nil {
+			t.Error("unexpected error", err)
+		}
+
+		msgCount++
+		dataSets += len(m.DataSets)
+	}
+
+	// templates, options templates and the data message
+	if msgCount != 3 {
+		t.Error("expect 3 messages, got", msgCount)
+	}
+
+	if dataSets != 2 {
+		t.Error("expect 2 data sets, got", dataSets)
+	}
+}
+
+func TestFileWriterWithdrawal(t *testing.T) {
+	ef := &exporterFile{domains: make(map[uint32]*domainTemplates)}
+	ef.learn(multiMessage)
+
+	withdraw := []byte{
+		0x0, 0xa, 0x0, 0x18, 0x59, 0x6f, 0x2b, 0x2a, 0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0, 0x1,
+		0x0, 0x2, 0x0, 0x8, 0x1, 0x0, 0x0, 0x0,
+	}
+	ef.learn(withdraw)
+
+	dt := ef.domains[1]
+	if _, ok := dt.sets[0][256]; ok {
+		t.Error("expect template 256 withdrawn")
+	}
+
+	if l := len(dt.sets[0]); l != 8 {
+		t.Error("expect 8 templates, got", l)
+	}
+
+	if l := len(dt.sets[1]); l != 1 {
+		t.Error("expect 1 options template, got", l)
+	}
+}
+
+func TestFileReaderInvalid(t *testing.T) {
+	r := NewFileReader(bytes.NewReader([]byte{0x0, 0x9, 0x0, 0x10}))
+	if _, err := r.Next(); err != io.ErrUnexpectedEOF {
+		t.Error("expect unexpected EOF, got", err)
+	}
+
+	r = NewFileReader(bytes.NewReader(make([]byte, 16)))
+	if _, err := r.Next(); err != errFileMessage {
+		t.Error("expect invalid message error, got", err)
+	}
+}
+
+func TestFileWriterCloseIdle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vflow-ipfix-file")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	defer os.RemoveAll(dir)
+
+	w := NewFileWriter(dir, 0, 0)
+	defer w.Close()
+
+	if err := w.Write("192.0.2.1", multiMessage); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if n := w.CloseIdle(time.Hour); n != 0 {
+		t.Error("expect no idle file, got", n)
+	}
+
+	if n := w.CloseIdle(0); n != 1 {
+		t.Error("expect 1 idle file closed, got", n)
+	}
+
+	// the next file of the exporter begins with its templates
+	if err := w.Write("192.0.2.1", multiMessage); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "192.0.2.1", "*.ipfix"))
+	if len(files) != 2 {
+		t.Error("expect 2 files, got", len(files))
+	}
+}
@@ -23,14 +23,21 @@
 package ipfix
 
 import (
+	"bytes"
 	"encoding/binary"
 	"math"
 	"net"
+	"unicode/utf8"
 )
 
-// Interpret read data fields based on the type - big endian
+// the seconds between the NTP era 0 (1900) and the unix epoch
+const ntpEpochOffset = 2208988800
+
+// Interpret read data fields based on the type - big endian,
+// the integers and floats can be reduced-size encoded, RFC 7011 6.2;
+// the data that doesn't fit the type returns as octet array
 func Interpret(b *[]byte, t FieldType) interface{} {
-	if len(*b) < t.minLen() {
+	if !t.validLen(len(*b)) {
 		return *b
 	}
 
@@ -40,39 +47,104 @@ func Interpret(b *[]byte, t FieldType) interface{} {
 	case Uint8:
 		return (*b)[0]
 	case Uint16:
-		return binary.BigEndian.Uint16(*b)
+		return uint16(uintN(*b))
 	case Uint32:
-		return binary.BigEndian.Uint32(*b)
+		return uint32(uintN(*b))
 	case Uint64:
-		return binary.BigEndian.Uint64(*b)
+		return uintN(*b)
 	case Int8:
 		return int8((*b)[0])
 	case Int16:
-		return int16(binary.BigEndian.Uint16(*b))
+		return int16(intN(*b))
 	case Int32:
-		return int32(binary.BigEndian.Uint32(*b))
+		return int32(intN(*b))
 	case Int64:
-		return int64(binary.BigEndian.Uint64(*b))
+		return intN(*b)
 	case Float32:
 		return math.Float32frombits(binary.BigEndian.Uint32(*b))
 	case Float64:
+		if len(*b) == 4 {
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(*b)))
+		}
 		return math.Float64frombits(binary.BigEndian.Uint64(*b))
 	case MacAddress:
 		return net.HardwareAddr(*b)
 	case String:
-		return string(*b)
+		return interpretString(*b)
 	case Ipv4Address, Ipv6Address:
 		return net.IP(*b)
 	case DateTimeSeconds:
 		return binary.BigEndian.Uint32(*b)
-	case DateTimeMilliseconds, DateTimeMicroseconds, DateTimeNanoseconds:
+	case DateTimeMilliseconds:
 		return binary.BigEndian.Uint64(*b)
+	case DateTimeMicroseconds:
+		// the lower 11 bits of the fraction are ignored, RFC 7011 6.1.9
+		sec, frac := ntpTime(*b)
+		return sec*1e6 + ((frac&^0x7ff)*1e6)>>32
+	case DateTimeNanoseconds:
+		sec, frac := ntpTime(*b)
+		return sec*1e9 + (frac*1e9)>>32
 	case Unknown, OctetArray:
 		return *b
 	}
 	return *b
 }
 
+// validLen returns true if the length is the type length or
+// a reduced-size encoding of the type
+func (t FieldType) validLen(n int) bool {
+	switch t {
+	case Uint16, Int16, Uint32, Int32, Uint64, Int64:
+		return n > 0 && n <= t.minLen()
+	case Float64:
+		return n == 4 || n == 8
+	case Unknown, OctetArray, String:
+		return true
+	default:
+		return n == t.minLen()
+	}
+}
+
+// uintN returns the unsigned integer of the big endian bytes
+func uintN(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+
+	return v
+}
+
+// intN returns the sign extended integer of the big endian bytes
+func intN(b []byte) int64 {
+	shift := uint(64 - 8*len(b))
+	return int64(uintN(b)<<shift) >> shift
+}
+
+// ntpTime returns the unix seconds and the fraction of
+// the NTP timestamp, the seconds before the unix epoch
+// belong to the NTP era 1 (after 2036)
+func ntpTime(b []byte) (uint64, uint64) {
+	sec := uint64(binary.BigEndian.Uint32(b))
+	frac := uint64(binary.BigEndian.Uint32(b[4:]))
+
+	if sec < ntpEpochOffset {
+		sec += 1 << 32
+	}
+
+	return sec - ntpEpochOffset, frac
+}
+
+// interpretString returns the string without the trailing
+// zero padding, the invalid UTF-8 returns as octet array
+func interpretString(b []byte) interface{} {
+	if !utf8.Valid(b) {
+		return b
+	}
+
+	return string(bytes.TrimRight(b, "\x00"))
+}
+
 func (t FieldType) minLen() int {
 	switch t {
 	case Boolean:
@@ -0,0 +1,91 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    interpret_test.go
+//: details: IPFIX data types interpret unit tests
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package ipfix
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestInterpret(t *testing.T) {
+	tests := []struct {
+		t        FieldType
+		b        []byte
+		expected interface{}
+	}{
+		{Boolean, []byte{1}, true},
+		{Boolean, []byte{2}, false},
+		{Uint8, []byte{0xff}, uint8(255)},
+		{Uint8, []byte{0x1, 0x2}, []byte{0x1, 0x2}},
+		{Uint16, []byte{0x1, 0x2}, uint16(0x102)},
+		{Uint16, []byte{0x7}, uint16(7)},
+		{Uint32, []byte{0x1, 0x2, 0x3, 0x4}, uint32(0x1020304)},
+		{Uint32, []byte{0x1, 0x2}, uint32(0x102)},
+		{Uint64, []byte{0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7, 0x8}, uint64(0x102030405060708)},
+		{Uint64, []byte{0x0, 0x0, 0x1, 0x0}, uint64(256)},
+		{Uint64, []byte{0x1, 0x2, 0x3}, uint64(0x10203)},
+		{Uint64, []byte{}, []byte{}},
+		{Uint32, []byte{0x1, 0x2, 0x3, 0x4, 0x5}, []byte{0x1, 0x2, 0x3, 0x4, 0x5}},
+		{Int8, []byte{0xff}, int8(-1)},
+		{Int16, []byte{0xff, 0xfe}, int16(-2)},
+		{Int16, []byte{0x80}, int16(-128)},
+		{Int32, []byte{0xff, 0xff, 0xff, 0xfd}, int32(-3)},
+		{Int32, []byte{0xff, 0xfc}, int32(-4)},
+		{Int32, []byte{0x7f, 0xff}, int32(32767)},
+		{Int64, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfb}, int64(-5)},
+		{Int64, []byte{0xff, 0xff, 0xff, 0xfa}, int64(-6)},
+		{Float32, []byte{0x3f, 0xc0, 0x0, 0x0}, float32(1.5)},
+		{Float64, []byte{0x3f, 0xf8, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0}, float64(1.5)},
+		{Float64, []byte{0x3f, 0xc0, 0x0, 0x0}, float64(1.5)},
+		{Float64, []byte{0x3f, 0xc0}, []byte{0x3f, 0xc0}},
+		{MacAddress, []byte{0x0, 0x1b, 0x21, 0xa, 0xb, 0xc}, net.HardwareAddr{0x0, 0x1b, 0x21, 0xa, 0xb, 0xc}},
+		{MacAddress, []byte{0x0, 0x1b}, []byte{0x0, 0x1b}},
+		{String, []byte("eth0"), "eth0"},
+		{String, []byte{'e', 't', 'h', '0', 0x0, 0x0}, "eth0"},
+		{String, []byte("\xe4\xb8\x96"), "世"},
+		{String, []byte{0xff, 0xfe}, []byte{0xff, 0xfe}},
+		{Ipv4Address, []byte{192, 0, 2, 1}, net.IP{192, 0, 2, 1}},
+		{Ipv4Address, []byte{192, 0, 2}, []byte{192, 0, 2}},
+		{Ipv6Address, net.ParseIP("2001:db8::1"), net.ParseIP("2001:db8::1")},
+		{Ipv6Address, []byte{0x20, 0x1, 0xd, 0xb8}, []byte{0x20, 0x1, 0xd, 0xb8}},
+		{DateTimeSeconds, []byte{0x5a, 0x0, 0x0, 0x0}, uint32(0x5a000000)},
+		{DateTimeMilliseconds, []byte{0x0, 0x0, 0x1, 0x59, 0x66, 0x93, 0xe6, 0x13}, uint64(1483484685843)},
+		// 2017-01-03 23:04:45.5 UTC in NTP format
+		{DateTimeMicroseconds, []byte{0xdc, 0x16, 0xac, 0x8d, 0x80, 0x0, 0x7, 0xff}, uint64(1483484685500000)},
+		{DateTimeNanoseconds, []byte{0xdc, 0x16, 0xac, 0x8d, 0x40, 0x0, 0x0, 0x0}, uint64(1483484685250000000)},
+		// NTP era 1, 2036-02-07 06:28:16 UTC
+		{DateTimeNanoseconds, []byte{0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0}, uint64(2085978496000000000)},
+		{DateTimeMicroseconds, []byte{0xdc, 0x16, 0x62}, []byte{0xdc, 0x16, 0x62}},
+		{OctetArray, []byte{0x1, 0x2}, []byte{0x1, 0x2}},
+		{Unknown, []byte{0x1}, []byte{0x1}},
+	}
+
+	for _, test := range tests {
+		b := test.b
+		v := Interpret(&b, test.t)
+		if !reflect.DeepEqual(v, test.expected) {
+			t.Errorf("type %d % x: expected %#v, got %#v", test.t, test.b, test.expected, v)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    list.go
+//: details: IPFIX structured data types decoder RFC 6313
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package ipfix
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/VerizonDigital/vflow/reader"
+)
+
+// BasicListValue represents a list of the same information element
+type BasicListValue struct {
+	Semantic     uint8
+	ID           uint16
+	EnterpriseNo uint32
+	Values       []interface{}
+}
+
+// SubTemplateListValue represents the data records of a template
+type SubTemplateListValue struct {
+	Semantic   uint8
+	TemplateID uint16
+	Records    [][]DecodedField
+}
+
+// SubTemplateMultiListValue represents the data records of the
+// different templates, a list per template
+type SubTemplateMultiListValue struct {
+	Semantic uint8
+	Lists    []SubTemplateListValue
+}
+
+var errEmptyListElement = errors.New("list element with zero length")
+
+// interpret decodes the structured data types through the
+// templates otherwise it interprets the abstract data types,
+// the undecodable structured data keeps as octet array
+func (d *Decoder) interpret(mem MemCache, domainID uint32, b []byte, t FieldType) interface{} {
+	var (
+		v   interface{}
+		err error
+	)
+
+	switch t {
+	case BasicList:
+		v, err = d.decodeBasicList(mem, domainID, b)
+	case SubTemplateList:
+		v, err = d.decodeSubTemplateList(mem, domainID, b)
+	case SubTemplateMultiList:
+		v, err = d.decodeSubTemplateMultiList(mem, domainID, b)
+	default:
+		return Interpret(&b, t)
+	}
+
+	if err != nil {
+		return b
+	}
+
+	return v
+}
+
+// listDecoder returns a decoder of the same exporter
+// to decode the list content
+func (d *Decoder) listDecoder(b []byte) *Decoder {
+	return &Decoder{
+		raddr:    d.raddr,
+		reader:   reader.NewReader(b),
+		agentID:  d.agentID,
+		port:     d.port,
+		sampling: d.sampling,
+		strict:   d.strict,
+	}
+}
+
+// RFC 6313 4.5.1. basicList Encoding
+// 0                   1                   2                   3
+// 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |    Semantic   |1|         Field ID            |   Element...  |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// | ...Length     |               Enterprise Number ...           |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |      ...      |              basicList Content ...            |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+func (d *Decoder) decodeBasicList(mem MemCache, domainID uint32, b []byte) (BasicListValue, error) {
+	var (
+		l   BasicListValue
+		f   TemplateFieldSpecifier
+		err error
+	)
+
+	r := reader.NewReader(b)
+
+	if l.Semantic, err = r.Uint8(); err != nil {
+		return l, err
+	}
+
+	if err = f.unmarshal(r); err != nil {
+		return l, err
+	}
+
+	if f.Length == 0 {
+		return l, errEmptyListElement
+	}
+
+	l.ID = f.ElementID
+	l.EnterpriseNo = f.EnterpriseNo
+
+	m, ok := d.element(f)
+	if !ok {
+		return l, fmt.Errorf("IPFIX element key (%d) not exist (basicList)", f.ElementID)
+	}
+
+	for r.Len() > 0 {
+		if b, err = readField(r, f.Length); err != nil {
+			return l, err
+		}
+
+		l.Values = append(l.Values, d.interpret(mem, domainID, b, m.Type))
+	}
+
+	return l, nil
+}
+
+// RFC 6313 4.5.2. subTemplateList Encoding
+// 0                   1                   2                   3
+// 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |   Semantic    |         Template ID           |     ...       |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |                subTemplateList Content    ...                 |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+func (d *Decoder) decodeSubTemplateList(mem MemCache, domainID uint32, b []byte) (SubTemplateListValue, error) {
+	var (
+		l   SubTemplateListValue
+		err error
+	)
+
+	r := reader.NewReader(b)
+
+	if l.Semantic, err = r.Uint8(); err != nil {
+		return l, err
+	}
+
+	if l.TemplateID, err = r.Uint16(); err != nil {
+		return l, err
+	}
+
+	if b, err = r.Read(r.Len()); err != nil {
+		return l, err
+	}
+
+	l.Records, err = d.decodeRecords(mem, domainID, l.TemplateID, b)
+
+	return l, err
+}
+
+// RFC 6313 4.5.3. subTemplateMultiList Encoding
+// 0                   1                   2                   3
+// 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |   Semantic    |         Template ID X         |Data Records   |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// | Length X      |     Data Record X.1 Content ...               |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |  ...          |         Template ID Y         |Data Records   |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+func (d *Decoder) decodeSubTemplateMultiList(mem MemCache, domainID uint32, b []byte) (SubTemplateMultiListValue, error) {
+	var (
+		l      SubTemplateMultiListValue
+		length uint16
+		err    error
+	)
+
+	r := reader.NewReader(b)
+
+	if l.Semantic, err = r.Uint8(); err != nil {
+		return l, err
+	}
+
+	for r.Len() > 0 {
+		var sl SubTemplateListValue
+
+		if sl.TemplateID, err = r.Uint16(); err != nil {
+			return l, err
+		}
+
+		// the data records length includes the template id and itself
+		if length, err = r.Uint16(); err != nil {
+			return l, err
+		}
+
+		if length < 4 {
+			return l, errEmptyListElement
+		}
+
+		if b, err = r.Read(int(length) - 4); err != nil {
+			return l, err
+		}
+
+		if sl.Records, err = d.decodeRecords(mem, domainID, sl.TemplateID, b); err != nil {
+			return l, err
+		}
+
+		l.Lists = append(l.Lists, sl)
+	}
+
+	return l, nil
+}
+
+// decodeRecords decodes the data records of a template
+func (d *Decoder) decodeRecords(mem MemCache, domainID uint32, id uint16, b []byte) ([][]DecodedField, error) {
+	var records [][]DecodedField
+
+	tr, ok := mem.retrieve(id, d.tplScope(domainID))
+	if !ok {
+		return nil, fmt.Errorf("%s unknown ipfix sub template id# %d", d.raddr.String(), id)
+	}
+
+	ld := d.listDecoder(b)
+	for ld.reader.Len() > 0 {
+		n := ld.reader.Len()
+
+		data, err := ld.decodeData(mem, domainID, tr)
+		if err != nil {
+			return nil, err
+		}
+
+		// the template without field doesn't make progress
+		if ld.reader.Len() == n {
+			return nil, errEmptyListElement
+		}
+
+		records = append(records, data)
+	}
+
+	return records, nil
+}
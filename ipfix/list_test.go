@@ -0,0 +1,170 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    list_test.go
+//: details: IPFIX structured data types testing
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package ipfix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+)
+
+// listMessage returns an IPFIX message includes template 256 with
+// basicList, subTemplateList and subTemplateMultiList and the sub
+// template 257 (ingressInterface, interfaceName)
+func listMessage() []byte {
+	b := []byte{
+		0x0, 0xa, 0x0, 0x0, 0x5a, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0, 0x1,
+		// template set
+		0x0, 0x2, 0x0, 0x24,
+		0x1, 0x0, 0x0, 0x4, 0x0, 0x8, 0x0, 0x4, 0x1, 0x23, 0xff, 0xff, 0x1, 0x24, 0xff, 0xff, 0x1, 0x25, 0xff, 0xff,
+		0x1, 0x1, 0x0, 0x2, 0x0, 0xa, 0x0, 0x4, 0x0, 0x52, 0xff, 0xff,
+		// data set 256
+		0x1, 0x0, 0x0, 0x0,
+		0xc0, 0x0, 0x2, 0x1,
+		// basicList allOf bgpSourceAsNumber [65000, 65001]
+		0xd, 0x3, 0x0, 0x10, 0x0, 0x4, 0x0, 0x0, 0xfd, 0xe8, 0x0, 0x0, 0xfd, 0xe9,
+		// subTemplateList allOf template 257 [[1, ge0], [2, ge1]]
+		0x13, 0x3, 0x1, 0x1,
+		0x0, 0x0, 0x0, 0x1, 0x3, 'g', 'e', '0',
+		0x0, 0x0, 0x0, 0x2, 0x3, 'g', 'e', '1',
+		// subTemplateMultiList allOf template 257 [[3, ge2]]
+		0xd, 0x3, 0x1, 0x1, 0x0, 0xc,
+		0x0, 0x0, 0x0, 0x3, 0x3, 'g', 'e', '2',
+	}
+
+	binary.BigEndian.PutUint16(b[54:56], uint16(len(b)-52))
+	binary.BigEndian.PutUint16(b[2:4], uint16(len(b)))
+
+	return b
+}
+
+func TestDecodeStructuredData(t *testing.T) {
+	ip := net.ParseIP("127.0.0.1")
+	mCache := NewMemCache()
+
+	d := NewDecoder(ip, listMessage())
+	msg, err := d.Decode(mCache)
+	if err != nil {
+		t.Fatal("unexpected error happened:", err)
+	}
+
+	if len(msg.DataSets) != 1 || len(msg.DataSets[0]) != 4 {
+		t.Fatal("expected 1 data set with 4 fields, got", msg.DataSets)
+	}
+
+	fields := msg.DataSets[0]
+
+	bl, ok := fields[1].Value.(BasicListValue)
+	if !ok {
+		t.Fatal("expected basicList, got", fields[1].Value)
+	}
+	if bl.Semantic != 3 || bl.ID != 16 || !reflect.DeepEqual(bl.Values, []interface{}{uint32(65000), uint32(65001)}) {
+		t.Error("unexpected basicList", bl)
+	}
+
+	stl, ok := fields[2].Value.(SubTemplateListValue)
+	if !ok {
+		t.Fatal("expected subTemplateList, got", fields[2].Value)
+	}
+	if stl.TemplateID != 257 || len(stl.Records) != 2 || stl.Records[1][1].Value != "ge1" {
+		t.Error("unexpected subTemplateList", stl)
+	}
+
+	stml, ok := fields[3].Value.(SubTemplateMultiListValue)
+	if !ok {
+		t.Fatal("expected subTemplateMultiList, got", fields[3].Value)
+	}
+	if len(stml.Lists) != 1 || stml.Lists[0].TemplateID != 257 || stml.Lists[0].Records[0][0].Value != uint32(3) {
+		t.Error("unexpected subTemplateMultiList", stml)
+	}
+}
+
+func TestDecodeStructuredDataUnknownTemplate(t *testing.T) {
+	ip := net.ParseIP("127.0.0.1")
+	mCache := NewMemCache()
+
+	if _, err := NewDecoder(ip, listMessage()).Decode(mCache); err != nil {
+		t.Fatal("unexpected error happened:", err)
+	}
+
+	// the sub template withdrawn and the data set received again
+	mCache.withdraw(257, TemplateScope{Addr: ip, DomainID: 1})
+
+	b := listMessage()
+	b = append(b[:16:16], b[52:]...)
+	binary.BigEndian.PutUint16(b[2:4], uint16(len(b)))
+
+	msg, err := NewDecoder(ip, b).Decode(mCache)
+	if err != nil {
+		t.Fatal("unexpected error happened:", err)
+	}
+
+	if _, ok := msg.DataSets[0][1].Value.(BasicListValue); !ok {
+		t.Error("expected basicList, got", msg.DataSets[0][1].Value)
+	}
+
+	if _, ok := msg.DataSets[0][2].Value.([]byte); !ok {
+		t.Error("expected octet array for unknown sub template, got", msg.DataSets[0][2].Value)
+	}
+}
+
+func TestJSONMarshalStructuredData(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ip := net.ParseIP("127.0.0.1")
+
+	msg, err := NewDecoder(ip, listMessage()).Decode(NewMemCache())
+	if err != nil {
+		t.Fatal("unexpected error happened:", err)
+	}
+
+	b, err := msg.JSONMarshal(buf)
+	if err != nil {
+		t.Fatal("unexpected error happened:", err)
+	}
+
+	var v struct {
+		DataSets [][]struct {
+			I uint16
+			V interface{}
+		}
+	}
+
+	if err := json.Unmarshal(b, &v); err != nil {
+		t.Fatal("unexpected JSON", err, string(b))
+	}
+
+	expected := []string{
+		`{"Semantic":3,"I":16,"V":[65000,65001]}`,
+		`{"Semantic":3,"TemplateID":257,"V":[[{"I":10,"V":1},{"I":82,"V":"ge0"}],[{"I":10,"V":2},{"I":82,"V":"ge1"}]]}`,
+		`{"Semantic":3,"V":[{"TemplateID":257,"V":[[{"I":10,"V":3},{"I":82,"V":"ge2"}]]}]}`,
+	}
+
+	for _, e := range expected {
+		if !bytes.Contains(b, []byte(e)) {
+			t.Error("expected", e, "got", string(b))
+		}
+	}
+}
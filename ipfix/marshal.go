@@ -26,14 +26,52 @@ import (
 	"bytes"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"net"
 	"strconv"
 )
 
 var errUknownMarshalDataType = errors.New("unknown data type to marshal")
 
+// JSONFormat represents the data records JSON encoding
+type JSONFormat int
+
+const (
+	// JSONCompact encodes the fields as {"I":id,"V":value}
+	JSONCompact JSONFormat = iota
+	// JSONFlat encodes the records as {"id":value}
+	JSONFlat
+	// JSONNamed encodes the records as {"name":value}
+	JSONNamed
+)
+
+var jsonFormats = map[string]JSONFormat{
+	"compact": JSONCompact,
+	"flat":    JSONFlat,
+	"named":   JSONNamed,
+}
+
+// ParseJSONFormat returns the JSON format by its name,
+// compact, flat or named
+func ParseJSONFormat(name string) (JSONFormat, error) {
+	f, ok := jsonFormats[name]
+	if !ok {
+		return f, fmt.Errorf("unknown json format: %s", name)
+	}
+
+	return f, nil
+}
+
 // JSONMarshal encodes IPFIX message
 func (m *Message) JSONMarshal(b *bytes.Buffer) ([]byte, error) {
+	return m.JSONMarshalFormat(b, JSONCompact)
+}
+
+// JSONMarshalFormat encodes IPFIX message, the data records
+// encode in the format
+func (m *Message) JSONMarshalFormat(b *bytes.Buffer, format JSONFormat) ([]byte, error) {
+	var err error
+
 	b.WriteString("{")
 
 	// encode agent id
@@ -43,10 +81,18 @@ func (m *Message) JSONMarshal(b *bytes.Buffer) ([]byte, error) {
 	m.encodeHeader(b)
 
 	// encode data sets
-	if err := m.encodeDataSet(b); err != nil {
+	if format == JSONCompact {
+		err = m.encodeDataSet(b)
+	} else {
+		err = m.encodeDataSetFlat(b, format == JSONNamed)
+	}
+
+	if err != nil {
 		return nil, err
 	}
 
+	m.encodeSamplingRates(b)
+
 	b.WriteString("}")
 
 	return b.Bytes(), nil
@@ -54,7 +100,6 @@ func (m *Message) JSONMarshal(b *bytes.Buffer) ([]byte, error) {
 
 func (m *Message) encodeDataSet(b *bytes.Buffer) error {
 	var (
-		length   int
 		dsLength int
 		err      error
 	)
@@ -65,76 +110,211 @@ func (m *Message) encodeDataSet(b *bytes.Buffer) error {
 	b.WriteByte('[')
 
 	for i := range m.DataSets {
-		length = len(m.DataSets[i])
+		err = writeFields(b, m.DataSets[i])
 
-		b.WriteByte('[')
-		for j := range m.DataSets[i] {
-			b.WriteString("{\"I\":")
-			b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].ID), 10))
-			b.WriteString(",\"V\":")
-			err = m.writeValue(b, i, j)
-
-			if m.DataSets[i][j].EnterpriseNo != 0 {
-				b.WriteString(",\"E\":")
-				b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].EnterpriseNo), 10))
-			}
+		if i < dsLength-1 {
+			b.WriteByte(',')
+		}
+	}
 
-			if j < length-1 {
-				b.WriteString("},")
-			} else {
-				b.WriteByte('}')
-			}
+	b.WriteByte(']')
+
+	return err
+}
+
+// writeFields encodes a data record as an array of the fields
+func writeFields(b *bytes.Buffer, fields []DecodedField) error {
+	var (
+		length = len(fields)
+		err    error
+	)
+
+	b.WriteByte('[')
+	for j := range fields {
+		b.WriteString("{\"I\":")
+		b.WriteString(strconv.FormatInt(int64(fields[j].ID), 10))
+		b.WriteString(",\"V\":")
+		err = writeValue(b, fields[j].Value)
+
+		if fields[j].EnterpriseNo != 0 {
+			b.WriteString(",\"E\":")
+			b.WriteString(strconv.FormatInt(int64(fields[j].EnterpriseNo), 10))
 		}
 
-		if i < dsLength-1 {
-			b.WriteString("],")
+		if j < length-1 {
+			b.WriteString("},")
 		} else {
-			b.WriteByte(']')
+			b.WriteByte('}')
 		}
 	}
+	b.WriteByte(']')
 
+	return err
+}
+
+// writeRecords encodes the data records of a sub template
+func writeRecords(b *bytes.Buffer, records [][]DecodedField) error {
+	var err error
+
+	b.WriteByte('[')
+	for i := range records {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		if e := writeFields(b, records[i]); e != nil {
+			err = e
+		}
+	}
 	b.WriteByte(']')
 
 	return err
 }
 
-func (m *Message) encodeDataSetFlat(b *bytes.Buffer) error {
-	var (
-		length   int
-		dsLength int
-		err      error
-	)
+// JSONMarshalRecord encodes the i-th data record of the IPFIX message
+// as a message by itself, the exporter, export time, sequence number
+// and observation domain id are folded in
+func (m *Message) JSONMarshalRecord(b *bytes.Buffer, i int, format JSONFormat) ([]byte, error) {
+	var err error
+
+	b.WriteString("{")
+
+	// encode agent id
+	m.encodeAgent(b)
+
+	b.WriteString("\"ExportTime\":")
+	b.WriteString(strconv.FormatInt(int64(m.Header.ExportTime), 10))
+	b.WriteString(",\"SequenceNo\":")
+	b.WriteString(strconv.FormatInt(int64(m.Header.SequenceNo), 10))
+	b.WriteString(",\"DomainID\":")
+	b.WriteString(strconv.FormatInt(int64(m.Header.DomainID), 10))
+
+	if i < len(m.SamplingRates) {
+		b.WriteString(",\"SamplingRate\":")
+		b.WriteString(strconv.FormatUint(uint64(m.SamplingRates[i]), 10))
+	}
+
+	b.WriteString(",\"Record\":")
+
+	if format == JSONCompact {
+		err = writeFields(b, m.DataSets[i])
+	} else {
+		err = m.writeObject(b, m.DataSets[i], format == JSONNamed)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	b.WriteString("}")
+
+	return b.Bytes(), nil
+}
+
+// encodeDataSetFlat encodes the data records as objects, the keys
+// are the element ids or the element names if it's named
+func (m *Message) encodeDataSetFlat(b *bytes.Buffer, named bool) error {
+	var err error
 
 	b.WriteString("\"DataSets\":")
-	dsLength = len(m.DataSets)
 
 	b.WriteByte('[')
 
 	for i := range m.DataSets {
-		length = len(m.DataSets[i])
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		if e := m.writeObject(b, m.DataSets[i], named); e != nil {
+			err = e
+		}
+	}
+
+	b.WriteByte(']')
+
+	return err
+}
 
-		b.WriteByte('{')
-		for j := range m.DataSets[i] {
-			b.WriteByte('"')
-			b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].ID), 10))
-			b.WriteString("\":")
-			err = m.writeValue(b, i, j)
+// writeObject encodes a data record as an object
+func (m *Message) writeObject(b *bytes.Buffer, fields []DecodedField, named bool) error {
+	keys := make([]string, len(fields))
+	for j, f := range fields {
+		keys[j] = m.fieldKey(f, named)
+	}
+
+	return WriteObject(b, keys, func(j int) error {
+		return writeValue(b, fields[j].Value)
+	})
+}
 
-			if j < length-1 {
+// WriteObject encodes the fields as an object by their keys, the
+// repeated keys are encoded once as an array of their values in
+// order so the object doesn't have the duplicate keys
+func WriteObject(b *bytes.Buffer, keys []string, value func(j int) error) error {
+	var (
+		err  error
+		done = make([]bool, len(keys))
+	)
+
+	b.WriteByte('{')
+	for j := range keys {
+		if done[j] {
+			continue
+		}
+
+		if j > 0 {
+			b.WriteByte(',')
+		}
+
+		WriteString(b, keys[j])
+		b.WriteByte(':')
+
+		var repeated []int
+		for k := j + 1; k < len(keys); k++ {
+			if keys[k] == keys[j] {
+				repeated = append(repeated, k)
+				done[k] = true
+			}
+		}
+
+		if len(repeated) < 1 {
+			if e := value(j); e != nil {
+				err = e
+			}
+			continue
+		}
+
+		b.WriteByte('[')
+		for _, k := range append([]int{j}, repeated...) {
+			if k != j {
 				b.WriteByte(',')
-			} else {
-				b.WriteByte('}')
+			}
+
+			if e := value(k); e != nil {
+				err = e
 			}
 		}
+		b.WriteByte(']')
+	}
+	b.WriteByte('}')
 
-		if i < dsLength-1 {
-			b.WriteString(",")
+	return err
+}
+
+// fieldKey returns the element name if it's named and known,
+// otherwise the element id, enterprise.id for the enterprise elements
+func (m *Message) fieldKey(f DecodedField, named bool) string {
+	if named {
+		if name := elementName(m.AgentID, ElementKey{f.EnterpriseNo, f.ID}); name != "" {
+			return name
 		}
 	}
 
-	b.WriteByte(']')
+	if f.EnterpriseNo != 0 {
+		return strconv.FormatUint(uint64(f.EnterpriseNo), 10) + "." + strconv.FormatUint(uint64(f.ID), 10)
+	}
 
-	return err
+	return strconv.FormatUint(uint64(f.ID), 10)
 }
 
 func (m *Message) encodeHeader(b *bytes.Buffer) {
@@ -151,57 +331,177 @@ func (m *Message) encodeHeader(b *bytes.Buffer) {
 	b.WriteString("},")
 }
 
+// encodeSamplingRates encodes the data sets sampling rates if they're annotated
+func (m *Message) encodeSamplingRates(b *bytes.Buffer) {
+	if len(m.SamplingRates) < 1 {
+		return
+	}
+
+	b.WriteString(",\"SamplingRates\":[")
+	for i, r := range m.SamplingRates {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatUint(uint64(r), 10))
+	}
+	b.WriteByte(']')
+}
+
 func (m *Message) encodeAgent(b *bytes.Buffer) {
-	b.WriteString("\"AgentID\":\"")
-	b.WriteString(m.AgentID)
-	b.WriteString("\",")
+	b.WriteString("\"AgentID\":")
+	WriteString(b, m.AgentID)
+	b.WriteByte(',')
+
+	if m.ReceiveTime != 0 {
+		b.WriteString("\"ReceiveTime\":")
+		b.WriteString(strconv.FormatInt(m.ReceiveTime, 10))
+		b.WriteByte(',')
+	}
 }
 
-func (m *Message) writeValue(b *bytes.Buffer, i, j int) error {
-	switch m.DataSets[i][j].Value.(type) {
+func writeValue(b *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
 	case uint:
-		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(uint)), 10))
+		b.WriteString(strconv.FormatUint(uint64(v), 10))
 	case uint8:
-		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(uint8)), 10))
+		b.WriteString(strconv.FormatInt(int64(v), 10))
 	case uint16:
-		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(uint16)), 10))
+		b.WriteString(strconv.FormatInt(int64(v), 10))
 	case uint32:
-		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(uint32)), 10))
+		b.WriteString(strconv.FormatInt(int64(v), 10))
 	case uint64:
-		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(uint64)), 10))
+		b.WriteString(strconv.FormatUint(v, 10))
 	case int:
-		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(int)), 10))
+		b.WriteString(strconv.FormatInt(int64(v), 10))
 	case int8:
-		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(int8)), 10))
+		b.WriteString(strconv.FormatInt(int64(v), 10))
 	case int16:
-		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(int16)), 10))
+		b.WriteString(strconv.FormatInt(int64(v), 10))
 	case int32:
-		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(int32)), 10))
+		b.WriteString(strconv.FormatInt(int64(v), 10))
 	case int64:
-		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(int64)), 10))
+		b.WriteString(strconv.FormatInt(v, 10))
 	case float32:
-		b.WriteString(strconv.FormatFloat(float64(m.DataSets[i][j].Value.(float32)), 'E', -1, 32))
+		b.WriteString(strconv.FormatFloat(float64(v), 'E', -1, 32))
 	case float64:
-		b.WriteString(strconv.FormatFloat(m.DataSets[i][j].Value.(float64), 'E', -1, 64))
+		b.WriteString(strconv.FormatFloat(v, 'E', -1, 64))
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
 	case string:
-		b.WriteByte('"')
-		b.WriteString(m.DataSets[i][j].Value.(string))
-		b.WriteByte('"')
+		WriteString(b, v)
 	case net.IP:
 		b.WriteByte('"')
-		b.WriteString(m.DataSets[i][j].Value.(net.IP).String())
+		b.WriteString(v.String())
 		b.WriteByte('"')
 	case net.HardwareAddr:
 		b.WriteByte('"')
-		b.WriteString(m.DataSets[i][j].Value.(net.HardwareAddr).String())
+		b.WriteString(v.String())
 		b.WriteByte('"')
 	case []uint8:
 		b.WriteByte('"')
-		b.WriteString("0x" + hex.EncodeToString(m.DataSets[i][j].Value.([]uint8)))
+		b.WriteString("0x" + hex.EncodeToString(v))
 		b.WriteByte('"')
+	case BasicListValue:
+		return writeBasicList(b, v)
+	case SubTemplateListValue:
+		return writeSubTemplateList(b, v)
+	case SubTemplateMultiListValue:
+		return writeSubTemplateMultiList(b, v)
 	default:
 		return errUknownMarshalDataType
 	}
 
 	return nil
 }
+
+// writeBasicList encodes RFC 6313 basicList as an object
+// includes the element id and the array of values
+func writeBasicList(b *bytes.Buffer, l BasicListValue) error {
+	var err error
+
+	b.WriteString("{\"Semantic\":")
+	b.WriteString(strconv.FormatInt(int64(l.Semantic), 10))
+	b.WriteString(",\"I\":")
+	b.WriteString(strconv.FormatInt(int64(l.ID), 10))
+
+	if l.EnterpriseNo != 0 {
+		b.WriteString(",\"E\":")
+		b.WriteString(strconv.FormatInt(int64(l.EnterpriseNo), 10))
+	}
+
+	b.WriteString(",\"V\":[")
+	for i := range l.Values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		if e := writeValue(b, l.Values[i]); e != nil {
+			err = e
+		}
+	}
+	b.WriteString("]}")
+
+	return err
+}
+
+// writeSubTemplateList encodes RFC 6313 subTemplateList as an
+// object includes the template id and the array of records
+func writeSubTemplateList(b *bytes.Buffer, l SubTemplateListValue) error {
+	b.WriteString("{\"Semantic\":")
+	b.WriteString(strconv.FormatInt(int64(l.Semantic), 10))
+	b.WriteString(",\"TemplateID\":")
+	b.WriteString(strconv.FormatInt(int64(l.TemplateID), 10))
+	b.WriteString(",\"V\":")
+	err := writeRecords(b, l.Records)
+	b.WriteByte('}')
+
+	return err
+}
+
+// writeSubTemplateMultiList encodes RFC 6313 subTemplateMultiList
+// as an object includes the array of the templates records
+func writeSubTemplateMultiList(b *bytes.Buffer, l SubTemplateMultiListValue) error {
+	var err error
+
+	b.WriteString("{\"Semantic\":")
+	b.WriteString(strconv.FormatInt(int64(l.Semantic), 10))
+	b.WriteString(",\"V\":[")
+	for i := range l.Lists {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		b.WriteString("{\"TemplateID\":")
+		b.WriteString(strconv.FormatInt(int64(l.Lists[i].TemplateID), 10))
+		b.WriteString(",\"V\":")
+		if e := writeRecords(b, l.Lists[i].Records); e != nil {
+			err = e
+		}
+		b.WriteByte('}')
+	}
+	b.WriteString("]}")
+
+	return err
+}
+
+// WriteString encodes the string as a JSON string, it escapes
+// the quote, backslash and control characters
+func WriteString(b *bytes.Buffer, s string) {
+	const hexDigits = "0123456789abcdef"
+
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20:
+			b.WriteString("\\u00")
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&0xf])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+}
@@ -26,6 +26,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"net"
+	"strings"
 	"testing"
 )
 
@@ -156,3 +157,158 @@ func chkString(t *testing.T, f TestDecodedField, expect string) {
 		t.Errorf("expect ID %d value %s, got %s", f.I, expect, f.V.(string))
 	}
 }
+
+func TestWriteString(t *testing.T) {
+	buf := new(bytes.Buffer)
+	WriteString(buf, "a\"b\\c\x01\n世")
+
+	var s string
+	if err := json.Unmarshal(buf.Bytes(), &s); err != nil {
+		t.Fatal("unexpected error happened:", err, buf.String())
+	}
+
+	if s != "a\"b\\c\x01\n世" {
+		t.Error("expected the string escaped, got", s)
+	}
+}
+
+func TestJSONMarshalFormat(t *testing.T) {
+	msg := Message{
+		AgentID: "192.0.2.1",
+		DataSets: [][]DecodedField{
+			{
+				{ID: 8, Value: net.IP{10, 0, 0, 1}},
+				{ID: 2, Value: uint64(5)},
+				{ID: 12232, Value: "mail", EnterpriseNo: 9},
+			},
+			{},
+		},
+	}
+
+	tests := []struct {
+		format   JSONFormat
+		expected string
+	}{
+		{JSONFlat, `[{"8":"10.0.0.1","2":5,"9.12232":"mail"},{}]`},
+		{JSONNamed, `[{"sourceIPv4Address":"10.0.0.1","packetDeltaCount":5,"9.12232":"mail"},{}]`},
+	}
+
+	for _, test := range tests {
+		b, err := msg.JSONMarshalFormat(new(bytes.Buffer), test.format)
+		if err != nil {
+			t.Fatal("unexpected error happened:", err)
+		}
+
+		var v struct{ DataSets json.RawMessage }
+		if err := json.Unmarshal(b, &v); err != nil {
+			t.Fatal("unexpected error happened:", err, string(b))
+		}
+
+		if string(v.DataSets) != test.expected {
+			t.Errorf("expected %s, got %s", test.expected, v.DataSets)
+		}
+	}
+
+	if _, err := ParseJSONFormat("named"); err != nil {
+		t.Error("unexpected error happened:", err)
+	}
+
+	if _, err := ParseJSONFormat("xml"); err == nil {
+		t.Error("expected the unknown format error")
+	}
+}
+
+func TestJSONMarshalAgentID(t *testing.T) {
+	// the TLS client certificate subject as agent id
+	msg := Message{AgentID: `CN=edge"1,O=Example\, Inc.`}
+
+	b, err := msg.JSONMarshal(new(bytes.Buffer))
+	if err != nil {
+		t.Fatal("unexpected error happened:", err)
+	}
+
+	var v struct{ AgentID string }
+	if err := json.Unmarshal(b, &v); err != nil || v.AgentID != msg.AgentID {
+		t.Error("expected the agent id escaped, got", string(b), err)
+	}
+}
+
+func TestJSONMarshalValues(t *testing.T) {
+	msg := Message{
+		AgentID: "192.0.2.1",
+		DataSets: [][]DecodedField{
+			{
+				{ID: 1, Value: uint64(1<<63 + 1)},
+				{ID: 276, Value: true},
+			},
+		},
+	}
+
+	b, err := msg.JSONMarshalFormat(new(bytes.Buffer), JSONFlat)
+	if err != nil {
+		t.Fatal("unexpected error happened:", err)
+	}
+
+	if !strings.Contains(string(b), `"DataSets":[{"1":9223372036854775809,"276":true}]`) {
+		t.Error("expected the unsigned and boolean values, got", string(b))
+	}
+}
+
+func TestJSONMarshalRepeated(t *testing.T) {
+	msg := Message{
+		AgentID: "192.0.2.1",
+		DataSets: [][]DecodedField{
+			{
+				{ID: 12, Value: net.IP{10, 0, 0, 1}},
+				{ID: 2, Value: uint64(5)},
+				{ID: 12, Value: net.IP{10, 0, 0, 2}},
+				{ID: 12, Value: net.IP{10, 0, 0, 3}},
+			},
+		},
+	}
+
+	b, err := msg.JSONMarshalRecord(new(bytes.Buffer), 0, JSONNamed)
+	if err != nil {
+		t.Fatal("unexpected error happened:", err)
+	}
+
+	expected := `"Record":{"destinationIPv4Address":["10.0.0.1","10.0.0.2","10.0.0.3"],"packetDeltaCount":5}}`
+	if !strings.HasSuffix(string(b), expected) {
+		t.Errorf("expected %s, got %s", expected, b)
+	}
+
+	var v map[string]interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		t.Error("unexpected error happened:", err)
+	}
+}
+
+func TestJSONMarshalRecord(t *testing.T) {
+	msg := Message{
+		AgentID: "192.0.2.1",
+		Header:  MessageHeader{ExportTime: 1483484642, SequenceNo: 7, DomainID: 32771},
+		DataSets: [][]DecodedField{
+			{{ID: 8, Value: net.IP{10, 0, 0, 1}}},
+			{{ID: 8, Value: net.IP{10, 0, 0, 2}}, {ID: 2, Value: uint64(5)}},
+		},
+	}
+
+	tests := []struct {
+		format   JSONFormat
+		expected string
+	}{
+		{JSONCompact, `{"AgentID":"192.0.2.1","ExportTime":1483484642,"SequenceNo":7,"DomainID":32771,"Record":[{"I":8,"V":"10.0.0.2"},{"I":2,"V":5}]}`},
+		{JSONNamed, `{"AgentID":"192.0.2.1","ExportTime":1483484642,"SequenceNo":7,"DomainID":32771,"Record":{"sourceIPv4Address":"10.0.0.2","packetDeltaCount":5}}`},
+	}
+
+	for _, test := range tests {
+		b, err := msg.JSONMarshalRecord(new(bytes.Buffer), 1, test.format)
+		if err != nil {
+			t.Fatal("unexpected error happened:", err)
+		}
+
+		if string(b) != test.expected {
+			t.Errorf("expected %s, got %s", test.expected, b)
+		}
+	}
+}
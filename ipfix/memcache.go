@@ -28,6 +28,9 @@ import (
 	"hash/fnv"
 	"io/ioutil"
 	"net"
+	"os"
+	"path/filepath"
+	"reflect"
 	"sort"
 	"sync"
 	"time"
@@ -35,6 +38,9 @@ import (
 
 var shardNo = 32
 
+// memCacheVersion is the templates dump format version
+const memCacheVersion = 2
+
 // MemCache represents templates shards
 type MemCache []*TemplatesShard
 
@@ -43,16 +49,60 @@ type MemCache []*TemplatesShard
 type Data struct {
 	Template  TemplateRecord
 	Timestamp int64
+	Scope     TemplateScope
+}
+
+// TemplateScope represents the scope that a template id is unique
+// in; the observation domain of an exporter and optionally the
+// exporter's source port. the exporter is the address or the agent
+// id once it's identified by the agent id like a TLS certificate
+type TemplateScope struct {
+	Addr     net.IP
+	AgentID  string `json:",omitempty"`
+	DomainID uint32
+	Port     int
 }
 
 // TemplatesShard represents a shard
 type TemplatesShard struct {
-	Templates map[uint32]Data
+	Templates map[templateKey]Data
+	legacy    map[uint32]Data
+	stats     MemCacheStats
 	sync.RWMutex
 }
+
+// MemCacheStats represents the templates counters, a refresh
+// is an identical template that received again and a change
+// is a template id that redefined by the exporter
+type MemCacheStats struct {
+	InsertCount   uint64
+	RefreshCount  uint64
+	ChangeCount   uint64
+	WithdrawCount uint64
+	EvictCount    uint64
+}
+
+// templateKey represents the templates map key, it's comparable
+// and contains the whole scope so there is no collision
+type templateKey struct {
+	addr     string
+	agentID  string
+	domainID uint32
+	port     int
+	id       uint16
+}
+
+// memCacheDisk represents the templates dump, the legacy dumps
+// (version 0) stored the shards with hashed keys
 type memCacheDisk struct {
-	Cache   MemCache
-	ShardNo int
+	Version   int
+	Templates []Data        `json:",omitempty"`
+	Cache     []legacyShard `json:",omitempty"`
+	ShardNo   int           `json:",omitempty"`
+}
+
+type legacyShard struct {
+	Templates map[uint32]Data
 }
 
 // GetCache tries to load saved templates
@@ -63,50 +113,192 @@ func GetCache(cacheFile string) MemCache {
 		err error
 	)
 
+	m := NewMemCache()
+
 	b, err := ioutil.ReadFile(cacheFile)
-	if err == nil {
-		err = json.Unmarshal(b, &mem)
-		if err == nil && mem.ShardNo == shardNo {
-			return mem.Cache
+	if err != nil {
+		return m
+	}
+
+	if err = json.Unmarshal(b, &mem); err != nil || mem.Version > memCacheVersion {
+		return m
+	}
+
+	// migrates the legacy dump, the templates without scope are
+	// kept by their legacy hash until their exporters claim them
+	if mem.Version == 0 {
+		for _, shard := range mem.Cache {
+			for hash, data := range shard.Templates {
+				if data.Scope.Addr != nil {
+					mem.Templates = append(mem.Templates, data)
+					continue
+				}
+
+				legacy := m[uint(hash)%uint(len(m))]
+				if legacy.legacy == nil {
+					legacy.legacy = make(map[uint32]Data)
+				}
+				legacy.legacy[hash] = data
+			}
 		}
 	}
 
+	for _, data := range mem.Templates {
+		shard, key := m.getShard(data.Template.TemplateID, data.Scope)
+		if v, ok := shard.Templates[key]; ok && v.Timestamp > data.Timestamp {
+			continue
+		}
+		shard.Templates[key] = data
+	}
+
+	return m
+}
+
+// NewMemCache constructs new empty shards, it can be
+// used for a template scope that shouldn't be persisted
+// like an IPFIX TCP session
+func NewMemCache() MemCache {
 	m := make(MemCache, shardNo)
 	for i := 0; i < shardNo; i++ {
-		m[i] = &TemplatesShard{Templates: make(map[uint32]Data)}
+		m[i] = &TemplatesShard{Templates: make(map[templateKey]Data)}
 	}
 
 	return m
 }
 
-func (m MemCache) getShard(id uint16, addr net.IP) (*TemplatesShard, uint32) {
-	b := make([]byte, 2)
-	binary.BigEndian.PutUint16(b, id)
-	key := append(addr, b...)
+func newTemplateKey(id uint16, s TemplateScope) templateKey {
+	addr := s.Addr
+	if ip := addr.To16(); ip != nil {
+		addr = ip
+	}
+
+	return templateKey{
+		addr:     string(addr),
+		agentID:  s.AgentID,
+		domainID: s.DomainID,
+		port:     s.Port,
+		id:       id,
+	}
+}
+
+// inScope reports whether the key belongs to the scope's key
+func (k templateKey) inScope(s templateKey) bool {
+	return k.addr == s.addr && k.agentID == s.agentID && k.domainID == s.domainID && k.port == s.port
+}
+
+// exporter returns the exporter identity of the scope
+func (s TemplateScope) exporter() string {
+	if s.AgentID != "" {
+		return s.AgentID
+	}
+
+	if s.Addr == nil {
+		return "*"
+	}
+
+	return s.Addr.String()
+}
+
+// getShard returns the shard by the key's hash
+func (m MemCache) getShard(id uint16, s TemplateScope) (*TemplatesShard, templateKey) {
+	key := newTemplateKey(id, s)
+
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint16(b[0:2], key.id)
+	binary.BigEndian.PutUint32(b[2:6], key.domainID)
+	binary.BigEndian.PutUint16(b[6:8], uint16(key.port))
 
 	hash := fnv.New32()
-	hash.Write(key)
-	hSum32 := hash.Sum32()
+	hash.Write([]byte(key.addr))
+	hash.Write([]byte(key.agentID))
+	hash.Write(b)
 
-	return m[uint(hSum32)%uint(shardNo)], hSum32
+	return m[uint(hash.Sum32())%uint(len(m))], key
 }
 
-func (m MemCache) insert(id uint16, addr net.IP, tr TemplateRecord) {
-	shard, key := m.getShard(id, addr)
+func (m MemCache) insert(id uint16, s TemplateScope, tr TemplateRecord) {
+	shard, key := m.getShard(id, s)
 	shard.Lock()
 	defer shard.Unlock()
-	shard.Templates[key] = Data{tr, time.Now().Unix()}
+
+	if v, ok := shard.Templates[key]; !ok {
+		shard.stats.InsertCount++
+	} else if reflect.DeepEqual(v.Template, tr) {
+		shard.stats.RefreshCount++
+	} else {
+		shard.stats.ChangeCount++
+	}
+
+	shard.Templates[key] = Data{tr, time.Now().Unix(), s}
 }
 
-func (m MemCache) retrieve(id uint16, addr net.IP) (TemplateRecord, bool) {
-	shard, key := m.getShard(id, addr)
+func (m MemCache) retrieve(id uint16, s TemplateScope) (TemplateRecord, bool) {
+	shard, key := m.getShard(id, s)
 	shard.RLock()
-	defer shard.RUnlock()
 	v, ok := shard.Templates[key]
+	shard.RUnlock()
+
+	if !ok {
+		return m.promote(id, s)
+	}
 
 	return v.Template, ok
 }
 
+// promote moves the legacy template that its exporter claims
+// to the exporter's scope, the legacy dumps keyed the templates
+// by the hash of the exporter's address and the template id
+func (m MemCache) promote(id uint16, s TemplateScope) (TemplateRecord, bool) {
+	for _, hash := range legacyHashes(id, s.Addr) {
+		shard := m[uint(hash)%uint(len(m))]
+		shard.Lock()
+		data, ok := shard.legacy[hash]
+		if ok = ok && data.Template.TemplateID == id; ok {
+			delete(shard.legacy, hash)
+		}
+		shard.Unlock()
+
+		if ok {
+			m.insert(id, s, data.Template)
+			return data.Template, true
+		}
+	}
+
+	return TemplateRecord{}, false
+}
+
+// legacyHashes returns the legacy keys of a template, the
+// address was hashed as it was received in 16 or 4 bytes
+func legacyHashes(id uint16, addr net.IP) []uint32 {
+	var hashes []uint32
+
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, id)
+
+	for _, ip := range []net.IP{addr.To16(), addr.To4()} {
+		if ip == nil {
+			continue
+		}
+
+		hash := fnv.New32()
+		hash.Write(ip)
+		hash.Write(b)
+		hashes = append(hashes, hash.Sum32())
+	}
+
+	return hashes
+}
+
+// Flush removes all templates from the memory cache
+func (m MemCache) Flush() {
+	for _, shard := range m {
+		shard.Lock()
+		shard.Templates = make(map[templateKey]Data)
+		shard.legacy = nil
+		shard.Unlock()
+	}
+}
+
 // Fill a slice with all known set ids. This is inefficient and is only used for error reporting or debugging.
 func (m MemCache) allSetIds() []int {
 	num := 0
@@ -125,22 +317,150 @@ func (m MemCache) allSetIds() []int {
 	return result
 }
 
-// Dump saves the current templates to hard disk
+// withdraw removes a template, RFC 7011 8.1
+func (m MemCache) withdraw(id uint16, s TemplateScope) bool {
+	shard, key := m.getShard(id, s)
+	shard.Lock()
+	defer shard.Unlock()
+
+	if _, ok := shard.Templates[key]; !ok {
+		return false
+	}
+
+	delete(shard.Templates, key)
+	shard.stats.WithdrawCount++
+
+	return true
+}
+
+// withdrawAll removes all templates or all options templates
+// of a scope, RFC 7011 8.1
+func (m MemCache) withdrawAll(s TemplateScope, options bool) int {
+	var n int
+
+	scope := newTemplateKey(0, s)
+	for _, shard := range m {
+		shard.Lock()
+		for key, data := range shard.Templates {
+			if !key.inScope(scope) || (data.Template.ScopeFieldCount > 0) != options {
+				continue
+			}
+
+			delete(shard.Templates, key)
+			shard.stats.WithdrawCount++
+			n++
+		}
+		shard.Unlock()
+	}
+
+	return n
+}
+
+// Expire evicts the templates that haven't been refreshed
+// during the lifetime, it returns the number of the evicted
+// templates per exporter
+func (m MemCache) Expire(lifetime time.Duration) map[string]int {
+	evicted := make(map[string]int)
+	deadline := time.Now().Add(-lifetime).Unix()
+
+	for _, shard := range m {
+		shard.Lock()
+		for key, data := range shard.Templates {
+			if data.Timestamp >= deadline {
+				continue
+			}
+
+			delete(shard.Templates, key)
+			shard.stats.EvictCount++
+			evicted[data.Scope.exporter()]++
+		}
+
+		// the legacy templates that no exporter claimed
+		for hash, data := range shard.legacy {
+			if data.Timestamp >= deadline {
+				continue
+			}
+
+			delete(shard.legacy, hash)
+			shard.stats.EvictCount++
+			evicted[data.Scope.exporter()]++
+		}
+		shard.Unlock()
+	}
+
+	return evicted
+}
+
+// Stats returns the templates counters
+func (m MemCache) Stats() MemCacheStats {
+	var stats MemCacheStats
+
+	for _, shard := range m {
+		shard.RLock()
+		stats.InsertCount += shard.stats.InsertCount
+		stats.RefreshCount += shard.stats.RefreshCount
+		stats.ChangeCount += shard.stats.ChangeCount
+		stats.WithdrawCount += shard.stats.WithdrawCount
+		stats.EvictCount += shard.stats.EvictCount
+		shard.RUnlock()
+	}
+
+	return stats
+}
+
+// Dump saves the current templates to hard disk, the legacy
+// templates that no exporter claimed are dropped
 func (m MemCache) Dump(cacheFile string) error {
-	b, err := json.Marshal(
-		memCacheDisk{
-			m,
-			shardNo,
-		},
-	)
+	mem := memCacheDisk{Version: memCacheVersion}
+
+	for _, shard := range m {
+		shard.RLock()
+		for _, data := range shard.Templates {
+			mem.Templates = append(mem.Templates, data)
+		}
+		shard.RUnlock()
+	}
+
+	b, err := json.Marshal(mem)
 	if err != nil {
 		return err
 	}
 
-	err = ioutil.WriteFile(cacheFile, b, 0644)
+	return writeFile(cacheFile, b)
+}
+
+// Changes returns the number of the templates inserts, changes,
+// withdrawals and evictions, it can be used to skip dumping the
+// unchanged templates, the refreshes don't change them
+func (m MemCache) Changes() uint64 {
+	stats := m.Stats()
+
+	return stats.InsertCount + stats.ChangeCount + stats.WithdrawCount +
+		stats.EvictCount
+}
+
+// writeFile writes to a temporary file and renames it to the
+// file so a crash in the middle doesn't corrupt the saved file
+func writeFile(file string, b []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(f.Name())
+
+	if _, err = f.Write(b); err == nil {
+		err = f.Sync()
+	}
+	if err == nil {
+		err = f.Chmod(0644)
+	}
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return os.Rename(f.Name(), file)
 }
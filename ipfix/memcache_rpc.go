@@ -56,8 +56,15 @@ type RPCConfig struct {
 
 // RPCRequest represents RPC request
 type RPCRequest struct {
-	ID uint16
-	IP net.IP
+	ID       uint16
+	IP       net.IP
+	DomainID uint32
+	Port     int
+}
+
+// scope returns the template scope of the request
+func (req RPCRequest) scope() TemplateScope {
+	return TemplateScope{Addr: req.IP, DomainID: req.DomainID, Port: req.Port}
 }
 
 type vFlowServer struct {
@@ -91,7 +98,7 @@ func NewRPC(mCache MemCache) *IRPC {
 func (r *IRPC) Get(req RPCRequest, resp *TemplateRecord) error {
 	var ok bool
 
-	*resp, ok = r.mCache.retrieve(req.ID, req.IP)
+	*resp, ok = r.mCache.retrieve(req.ID, req.scope())
 	if !ok {
 		return errNotAvail
 	}
@@ -168,7 +175,7 @@ func RPC(m MemCache, config *RPCConfig) {
 				continue
 			}
 
-			m.insert(req.ID, req.IP, *tr)
+			m.insert(req.ID, req.scope(), *tr)
 			break
 		}
 
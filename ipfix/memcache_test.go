@@ -23,9 +23,12 @@
 package ipfix
 
 import (
+	"io/ioutil"
 	"net"
+	"os"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestMemCacheRetrieve(t *testing.T) {
@@ -33,7 +36,7 @@ func TestMemCacheRetrieve(t *testing.T) {
 	mCache := GetCache("cache.file")
 	d := NewDecoder(ip, tpl)
 	d.Decode(mCache)
-	v, ok := mCache.retrieve(256, ip)
+	v, ok := mCache.retrieve(256, TemplateScope{Addr: ip, DomainID: 33792})
 	if !ok {
 		t.Error("expected mCache retrieve status true, got", ok)
 	}
@@ -44,13 +47,13 @@ func TestMemCacheRetrieve(t *testing.T) {
 
 func TestMemCacheInsert(t *testing.T) {
 	var tpl TemplateRecord
-	ip := net.ParseIP("127.0.0.1")
+	scope := TemplateScope{Addr: net.ParseIP("127.0.0.1")}
 	mCache := GetCache("cache.file")
 
 	tpl.TemplateID = 310
-	mCache.insert(310, ip, tpl)
+	mCache.insert(310, scope, tpl)
 
-	v, ok := mCache.retrieve(310, ip)
+	v, ok := mCache.retrieve(310, scope)
 	if !ok {
 		t.Error("expected mCache retrieve status true, got", ok)
 	}
@@ -61,15 +64,15 @@ func TestMemCacheInsert(t *testing.T) {
 
 func TestMemCacheAllSetIds(t *testing.T) {
 	var tpl TemplateRecord
-	ip := net.ParseIP("127.0.0.1")
+	scope := TemplateScope{Addr: net.ParseIP("127.0.0.1")}
 	mCache := GetCache("cache.file")
 
 	tpl.TemplateID = 310
-	mCache.insert(tpl.TemplateID, ip, tpl)
+	mCache.insert(tpl.TemplateID, scope, tpl)
 	tpl.TemplateID = 410
-	mCache.insert(tpl.TemplateID, ip, tpl)
+	mCache.insert(tpl.TemplateID, scope, tpl)
 	tpl.TemplateID = 210
-	mCache.insert(tpl.TemplateID, ip, tpl)
+	mCache.insert(tpl.TemplateID, scope, tpl)
 
 	expected := []int{210, 310, 410}
 	actual := mCache.allSetIds()
@@ -77,3 +80,301 @@ func TestMemCacheAllSetIds(t *testing.T) {
 		t.Errorf("Expected set IDs %v, got %v", expected, actual)
 	}
 }
+
+func TestMemCacheFlush(t *testing.T) {
+	ip := net.ParseIP("127.0.0.1")
+	mCache := NewMemCache()
+	d := NewDecoder(ip, tpl)
+	d.Decode(mCache)
+
+	if _, ok := mCache.retrieve(256, TemplateScope{Addr: ip, DomainID: 33792}); !ok {
+		t.Fatal("expected mCache retrieve status true, got", ok)
+	}
+
+	mCache.Flush()
+
+	if _, ok := mCache.retrieve(256, TemplateScope{Addr: ip, DomainID: 33792}); ok {
+		t.Error("expected mCache retrieve status false after flush, got", ok)
+	}
+}
+
+func TestMemCacheScope(t *testing.T) {
+	var tpl TemplateRecord
+	ip := net.ParseIP("127.0.0.1")
+	mCache := NewMemCache()
+
+	scopes := []TemplateScope{
+		{Addr: ip, DomainID: 1},
+		{Addr: ip, DomainID: 2},
+		{Addr: ip, DomainID: 2, Port: 4739},
+	}
+
+	for i, scope := range scopes {
+		tpl.TemplateID = 256
+		tpl.FieldCount = uint16(i + 1)
+		mCache.insert(256, scope, tpl)
+	}
+
+	for i, scope := range scopes {
+		v, ok := mCache.retrieve(256, scope)
+		if !ok {
+			t.Fatal("expected mCache retrieve status true, got", ok)
+		}
+		if v.FieldCount != uint16(i+1) {
+			t.Error("expected field count", i+1, "got", v.FieldCount)
+		}
+	}
+
+	if _, ok := mCache.retrieve(256, TemplateScope{Addr: ip, DomainID: 3}); ok {
+		t.Error("expected mCache retrieve status false, got", ok)
+	}
+}
+
+func TestMemCacheDumpLoad(t *testing.T) {
+	var tpl TemplateRecord
+	scope := TemplateScope{Addr: net.ParseIP("192.0.2.1"), DomainID: 5}
+	mCache := NewMemCache()
+
+	tpl.TemplateID = 300
+	mCache.insert(300, scope, tpl)
+
+	f, err := ioutil.TempFile("", "vflow-templates")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	if err := mCache.Dump(f.Name()); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	v, ok := GetCache(f.Name()).retrieve(300, scope)
+	if !ok || v.TemplateID != 300 {
+		t.Error("expected template id#:300, got", v.TemplateID, ok)
+	}
+}
+
+func TestMemCacheLegacyDump(t *testing.T) {
+	legacy := `{"Cache":[{"Templates":{"12345":{"Template":{"TemplateID":256,"FieldCount":1},` +
+		`"Timestamp":1,"Scope":{"Addr":"192.0.2.1","DomainID":1,"Port":0}},` +
+		`"67890":{"Template":{"TemplateID":257},"Timestamp":1}}},{"Templates":{}}],"ShardNo":32}`
+
+	f, err := ioutil.TempFile("", "vflow-templates")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	f.WriteString(legacy)
+	f.Close()
+	defer os.Remove(f.Name())
+
+	mCache := GetCache(f.Name())
+
+	v, ok := mCache.retrieve(256, TemplateScope{Addr: net.ParseIP("192.0.2.1"), DomainID: 1})
+	if !ok || v.FieldCount != 1 {
+		t.Error("expected migrated template id#:256, got", v.TemplateID, ok)
+	}
+
+	// the template without scope belongs to an exporter with another hash
+	if _, ok := mCache.retrieve(257, TemplateScope{Addr: net.ParseIP("192.0.2.2"), DomainID: 9}); ok {
+		t.Error("unexpected legacy template id#:257 for another exporter")
+	}
+
+	if err := mCache.Dump(f.Name()); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	mCache = GetCache(f.Name())
+	if _, ok := mCache.retrieve(256, TemplateScope{Addr: net.ParseIP("192.0.2.1"), DomainID: 1}); !ok {
+		t.Error("expected template id#:256 after dump")
+	}
+
+	if n := len(mCache.Expire(-time.Second)); n != 1 {
+		t.Error("expected only the scoped template after dump, got", n)
+	}
+}
+
+func TestMemCacheBaselineDump(t *testing.T) {
+	// the templates dump of the releases before the scoped templates
+	mCache := GetCache("testdata/legacy.templates")
+
+	if _, ok := mCache.retrieve(256, TemplateScope{Addr: net.ParseIP("192.0.2.3"), DomainID: 33792}); ok {
+		t.Error("unexpected baseline template id#:256 for another exporter")
+	}
+
+	// the exporter claims its template by the legacy hash
+	scope := TemplateScope{Addr: net.ParseIP("192.0.2.1"), DomainID: 33792}
+	v, ok := mCache.retrieve(256, scope)
+	if !ok || v.FieldCount != 25 {
+		t.Fatal("expected the baseline template id#:256, got", v.TemplateID, ok)
+	}
+
+	if n := mCache.Stats().InsertCount; n != 1 {
+		t.Error("expected the claimed template inserted to its scope, got", n)
+	}
+
+	if _, ok := mCache.retrieve(256, TemplateScope{Addr: net.ParseIP("192.0.2.1"), DomainID: 1}); ok {
+		t.Error("unexpected baseline template id#:256 after it's claimed")
+	}
+
+	// the exporter's template takes precedence once it's received
+	mCache.insert(256, scope, TemplateRecord{TemplateID: 256, FieldCount: 1})
+	if v, _ = mCache.retrieve(256, scope); v.FieldCount != 1 {
+		t.Error("expected the exporter's template, got", v.FieldCount)
+	}
+}
+
+func TestMemCacheLegacyExpire(t *testing.T) {
+	mCache := GetCache("testdata/legacy.templates")
+
+	evicted := mCache.Expire(-time.Second)
+	if evicted["*"] != 1 || mCache.Changes() != 1 {
+		t.Error("expected the unclaimed legacy template evicted, got", evicted)
+	}
+
+	if _, ok := mCache.retrieve(256, TemplateScope{Addr: net.ParseIP("192.0.2.1"), DomainID: 33792}); ok {
+		t.Error("unexpected baseline template id#:256 after eviction")
+	}
+}
+
+func TestMemCacheWithdraw(t *testing.T) {
+	ip := net.ParseIP("127.0.0.1")
+	scope := TemplateScope{Addr: ip, DomainID: 1}
+	mCache := NewMemCache()
+
+	header := []byte{0x0, 0xa, 0x0, 0x0, 0x5a, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0, 0x1}
+	message := func(sets ...byte) []byte {
+		b := append(append([]byte{}, header...), sets...)
+		b[3] = byte(len(b))
+		return b
+	}
+
+	// templates 256, 257 and options template 258
+	d := NewDecoder(ip, message(
+		0x0, 0x2, 0x0, 0x14, 0x1, 0x0, 0x0, 0x1, 0x0, 0x8, 0x0, 0x4, 0x1, 0x1, 0x0, 0x1, 0x0, 0xc, 0x0, 0x4,
+		0x0, 0x3, 0x0, 0x12, 0x1, 0x2, 0x0, 0x2, 0x0, 0x1, 0x0, 0x90, 0x0, 0x4, 0x0, 0x29, 0x0, 0x8,
+	))
+	if _, err := d.Decode(mCache); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if ids := mCache.allSetIds(); !reflect.DeepEqual(ids, []int{256, 257, 258}) {
+		t.Fatal("expected template ids [256 257 258], got", ids)
+	}
+
+	// template 256 withdrawal
+	d = NewDecoder(ip, message(0x0, 0x2, 0x0, 0x8, 0x1, 0x0, 0x0, 0x0))
+	if _, err := d.Decode(mCache); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if _, ok := mCache.retrieve(256, scope); ok {
+		t.Error("expected template id#:256 withdrawn")
+	}
+
+	// all templates withdrawal, the options template remains
+	d = NewDecoder(ip, message(0x0, 0x2, 0x0, 0x8, 0x0, 0x2, 0x0, 0x0))
+	if _, err := d.Decode(mCache); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if ids := mCache.allSetIds(); !reflect.DeepEqual(ids, []int{258}) {
+		t.Error("expected template ids [258], got", ids)
+	}
+
+	if stats := mCache.Stats(); stats.InsertCount != 3 || stats.WithdrawCount != 2 {
+		t.Error("expected 3 inserts and 2 withdrawals, got", stats)
+	}
+}
+
+func TestMemCacheExpire(t *testing.T) {
+	var tpl TemplateRecord
+	scope := TemplateScope{Addr: net.ParseIP("192.0.2.1")}
+	mCache := NewMemCache()
+
+	tpl.TemplateID = 256
+	mCache.insert(256, scope, tpl)
+	mCache.insert(256, scope, tpl)
+	tpl.FieldCount = 1
+	mCache.insert(256, scope, tpl)
+	mCache.insert(257, scope, tpl)
+
+	shard, key := mCache.getShard(256, scope)
+	data := shard.Templates[key]
+	data.Timestamp -= 3600
+	shard.Templates[key] = data
+
+	evicted := mCache.Expire(time.Minute)
+	if evicted["192.0.2.1"] != 1 {
+		t.Error("expected 1 evicted template, got", evicted)
+	}
+
+	if _, ok := mCache.retrieve(257, scope); !ok {
+		t.Error("expected template id#:257 not expired")
+	}
+
+	expected := MemCacheStats{InsertCount: 2, RefreshCount: 1, ChangeCount: 1, EvictCount: 1}
+	if stats := mCache.Stats(); stats != expected {
+		t.Errorf("expected stats %v, got %v", expected, stats)
+	}
+}
+
+func TestMemCacheDumpAtomic(t *testing.T) {
+	var tpl TemplateRecord
+	scope := TemplateScope{Addr: net.ParseIP("192.0.2.1")}
+	mCache := NewMemCache()
+
+	dir, err := ioutil.TempDir("", "vflow")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tpl.TemplateID = 256
+	mCache.insert(256, scope, tpl)
+
+	file := dir + "/templates"
+	if err := mCache.Dump(file); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 || files[0].Mode().Perm() != 0644 {
+		t.Error("expected only the templates file with 0644, got", files)
+	}
+
+	// the unknown newer version shouldn't be loaded
+	ioutil.WriteFile(file, []byte(`{"Version":99,"Templates":[{"Template":{"TemplateID":256},`+
+		`"Scope":{"Addr":"192.0.2.1"}}]}`), 0644)
+	if ids := GetCache(file).allSetIds(); len(ids) != 0 {
+		t.Error("expected empty templates, got", ids)
+	}
+}
+
+func TestMemCacheChanges(t *testing.T) {
+	var tpl TemplateRecord
+	scope := TemplateScope{Addr: net.ParseIP("192.0.2.1")}
+	mCache := NewMemCache()
+
+	tpl.TemplateID = 256
+	mCache.insert(256, scope, tpl)
+	last := mCache.Changes()
+
+	// the refreshed template doesn't need a dump
+	mCache.insert(256, scope, tpl)
+	if changes := mCache.Changes(); changes != last {
+		t.Error("expected no changes after refresh, got", changes-last)
+	}
+
+	tpl.FieldCount = 1
+	mCache.insert(256, scope, tpl)
+	if changes := mCache.Changes(); changes != last+1 {
+		t.Error("expected one change, got", changes-last)
+	}
+
+	// the expired template needs a dump to not come back
+	mCache.Expire(-time.Second)
+	if changes := mCache.Changes(); changes != last+2 {
+		t.Error("expected the eviction as change, got", changes-last)
+	}
+}
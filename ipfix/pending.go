@@ -0,0 +1,196 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    pending.go
+//: details: pending buffer for data sets without template
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package ipfix
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Pending represents a bounded per exporter buffer of the data
+// sets that their template hasn't arrived yet. The data sets are
+// decoded once the template inserted or dropped after the timeout
+// or once the exporter's buffer exceeds the maximum size
+type Pending struct {
+	timeout   time.Duration
+	maxSize   int
+	exporters map[string]*exporterPending
+	stats     PendingStats
+	sync.Mutex
+}
+
+// PendingStats represents the pending buffer counters
+type PendingStats struct {
+	BufferedCount uint64
+	DecodedCount  uint64
+	DroppedCount  uint64
+}
+
+type pendingKey struct {
+	domainID   uint32
+	templateID uint16
+}
+
+type pendingSet struct {
+	header      MessageHeader
+	agentID     string
+	receiveTime int64
+	data        []byte
+	timestamp   time.Time
+}
+
+type exporterPending struct {
+	size int
+	sets map[pendingKey][]pendingSet
+}
+
+// NewPending constructs pending buffer, the maxSize is
+// the maximum buffered bytes per exporter
+func NewPending(timeout time.Duration, maxSize int) *Pending {
+	return &Pending{
+		timeout:   timeout,
+		maxSize:   maxSize,
+		exporters: make(map[string]*exporterPending),
+	}
+}
+
+// Sweep drops the expired data sets
+func (p *Pending) Sweep() {
+	p.Lock()
+	defer p.Unlock()
+
+	deadline := time.Now().Add(-p.timeout)
+	for exporter, ep := range p.exporters {
+		p.expire(ep, deadline)
+		if len(ep.sets) == 0 {
+			delete(p.exporters, exporter)
+		}
+	}
+}
+
+// Stats returns the pending buffer counters
+func (p *Pending) Stats() PendingStats {
+	return PendingStats{
+		BufferedCount: atomic.LoadUint64(&p.stats.BufferedCount),
+		DecodedCount:  atomic.LoadUint64(&p.stats.DecodedCount),
+		DroppedCount:  atomic.LoadUint64(&p.stats.DroppedCount),
+	}
+}
+
+func (p *Pending) add(exporter string, key pendingKey, s pendingSet) {
+	if len(s.data) > p.maxSize {
+		atomic.AddUint64(&p.stats.DroppedCount, 1)
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	ep, ok := p.exporters[exporter]
+	if !ok {
+		ep = &exporterPending{sets: make(map[pendingKey][]pendingSet)}
+		p.exporters[exporter] = ep
+	}
+
+	p.expire(ep, s.timestamp.Add(-p.timeout))
+
+	for ep.size+len(s.data) > p.maxSize {
+		p.dropOldest(ep)
+	}
+
+	ep.sets[key] = append(ep.sets[key], s)
+	ep.size += len(s.data)
+
+	atomic.AddUint64(&p.stats.BufferedCount, 1)
+}
+
+func (p *Pending) take(exporter string, key pendingKey) []pendingSet {
+	p.Lock()
+	defer p.Unlock()
+
+	ep, ok := p.exporters[exporter]
+	if !ok {
+		return nil
+	}
+
+	sets := ep.sets[key]
+	for _, s := range sets {
+		ep.size -= len(s.data)
+	}
+
+	delete(ep.sets, key)
+
+	return sets
+}
+
+func (p *Pending) expire(ep *exporterPending, deadline time.Time) {
+	for key, sets := range ep.sets {
+		n := 0
+		for n < len(sets) && sets[n].timestamp.Before(deadline) {
+			ep.size -= len(sets[n].data)
+			n++
+		}
+
+		if n == 0 {
+			continue
+		}
+
+		atomic.AddUint64(&p.stats.DroppedCount, uint64(n))
+
+		if n == len(sets) {
+			delete(ep.sets, key)
+		} else {
+			ep.sets[key] = sets[n:]
+		}
+	}
+}
+
+func (p *Pending) dropOldest(ep *exporterPending) {
+	var (
+		oldest pendingKey
+		found  bool
+		ts     time.Time
+	)
+
+	for key, sets := range ep.sets {
+		if !found || sets[0].timestamp.Before(ts) {
+			oldest, ts, found = key, sets[0].timestamp, true
+		}
+	}
+
+	if !found {
+		ep.size = 0
+		return
+	}
+
+	sets := ep.sets[oldest]
+	ep.size -= len(sets[0].data)
+	if len(sets) == 1 {
+		delete(ep.sets, oldest)
+	} else {
+		ep.sets[oldest] = sets[1:]
+	}
+
+	atomic.AddUint64(&p.stats.DroppedCount, 1)
+}
@@ -0,0 +1,109 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    pending_test.go
+//: details: pending buffer tests
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package ipfix
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDecodePending(t *testing.T) {
+	var (
+		ip      = net.ParseIP("127.0.0.1")
+		mCache  = NewMemCache()
+		pending = NewPending(time.Minute, 1024)
+	)
+
+	d := NewDecoder(ip, unknownDatasetMessage)
+	d.SetPending(pending)
+	m, err := d.Decode(mCache)
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	if len(m.DataSets) != 0 {
+		t.Error("expect no data sets, got", len(m.DataSets))
+	}
+
+	if s := pending.Stats(); s.BufferedCount != 2 {
+		t.Error("expect 2 buffered data sets, got", s.BufferedCount)
+	}
+
+	d = NewDecoder(ip, multiMessage)
+	d.SetPending(pending)
+	m, err = d.Decode(mCache)
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	if len(m.Pending) != 2 {
+		t.Fatal("expect 2 pending messages, got", len(m.Pending))
+	}
+
+	for _, pm := range m.Pending {
+		if len(pm.DataSets) != 1 {
+			t.Error("expect 1 data set, got", len(pm.DataSets))
+		}
+		if pm.AgentID != "127.0.0.1" {
+			t.Error("unexpected agent id", pm.AgentID)
+		}
+	}
+
+	if s := pending.Stats(); s.DecodedCount != 2 || s.DroppedCount != 0 {
+		t.Error("unexpected pending stats", s)
+	}
+}
+
+func TestPendingMaxSize(t *testing.T) {
+	p := NewPending(time.Minute, 10)
+	key := pendingKey{1, 256}
+	now := time.Now()
+
+	p.add("a", key, pendingSet{data: make([]byte, 6), timestamp: now})
+	p.add("a", key, pendingSet{data: make([]byte, 6), timestamp: now})
+	p.add("a", key, pendingSet{data: make([]byte, 11), timestamp: now})
+
+	if s := p.Stats(); s.BufferedCount != 2 || s.DroppedCount != 2 {
+		t.Error("unexpected pending stats", s)
+	}
+
+	if sets := p.take("a", key); len(sets) != 1 {
+		t.Error("expect 1 pending set, got", len(sets))
+	}
+}
+
+func TestPendingSweep(t *testing.T) {
+	p := NewPending(time.Second, 1024)
+
+	p.add("a", pendingKey{1, 256}, pendingSet{data: make([]byte, 6), timestamp: time.Now().Add(-2 * time.Second)})
+	p.Sweep()
+
+	if s := p.Stats(); s.DroppedCount != 1 {
+		t.Error("expect 1 dropped data set, got", s.DroppedCount)
+	}
+
+	if len(p.exporters) != 0 {
+		t.Error("expect no exporter, got", len(p.exporters))
+	}
+}
@@ -22,14 +22,6 @@
 
 package ipfix
 
-import (
-	"io/ioutil"
-	"os"
-	"path"
-
-	"gopkg.in/yaml.v2"
-)
-
 // FieldType is IPFIX Abstract Data Types RFC5102#section-3.1
 type FieldType int
 
@@ -128,6 +120,18 @@ const (
 
 	// Ipv6Address represents a value of an IPv6 address.
 	Ipv6Address
+
+	// BasicList represents a list of zero or more instances of
+	// any information element, RFC 6313 4.5.1
+	BasicList
+
+	// SubTemplateList represents a list of zero or more data
+	// records of the same template, RFC 6313 4.5.2
+	SubTemplateList
+
+	// SubTemplateMultiList represents a list of zero or more
+	// data records of different templates, RFC 6313 4.5.3
+	SubTemplateMultiList
 )
 
 // FieldTypes represents data types
@@ -152,6 +156,9 @@ var FieldTypes = map[string]FieldType{
 	"dateTimeNanoseconds":  DateTimeNanoseconds,
 	"ipv4Address":          Ipv4Address,
 	"ipv6Address":          Ipv6Address,
+	"basicList":            BasicList,
+	"subTemplateList":      SubTemplateList,
+	"subTemplateMultiList": SubTemplateMultiList,
 }
 
 //InfoModel maps element to name and type based on the field id and enterprise id
@@ -559,34 +566,3 @@ var InfoModel = IANAInfoModel{
 	ElementKey{0, 432}: InfoElementEntry{FieldID: 432, Name: "pseudoWireDestinationIPv4Address", Type: FieldTypes["ipv4Address"]},
 	ElementKey{0, 433}: InfoElementEntry{FieldID: 433, Name: "ignoredLayer2FrameTotalCount", Type: FieldTypes["unsigned64"]},
 }
-
-// LoadExtElements loads ipfix elements information through ipfix.elemets file
-func LoadExtElements(cfgPath string) {
-	var (
-		file          = path.Join(cfgPath, "ipfix.elements")
-		ipfixElements map[uint32]map[uint16][]string
-	)
-
-	if _, err := os.Stat(file); os.IsNotExist(err) {
-		return
-	}
-
-	b, err := ioutil.ReadFile(file)
-	if err != nil {
-		return
-	}
-	err = yaml.Unmarshal(b, &ipfixElements)
-	if err != nil {
-	}
-
-	InfoModel = make(map[ElementKey]InfoElementEntry)
-
-	for PEN, elements := range ipfixElements {
-		for elementID, prop := range elements {
-			if len(prop) > 1 {
-				InfoModel[ElementKey{PEN, elementID}] =
-					InfoElementEntry{FieldID: elementID, Name: prop[0], Type: FieldTypes[prop[1]]}
-			}
-		}
-	}
-}
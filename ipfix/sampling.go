@@ -0,0 +1,241 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    sampling.go
+//: details: exporters sampling table from the options data
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package ipfix
+
+import "sync"
+
+// Sampling represents the exporters sampling table, it's built from
+// the options data records. the data records refer to a sampler by the
+// samplerId (48) or the selectorId (302) and the options data without
+// them announces the sampling of the exporter's observation domain
+type Sampling struct {
+	rates     map[SamplerKey]uint32
+	overrides map[string]uint32
+	sync.RWMutex
+}
+
+// SamplerKey represents an exporter's sampler, the zero
+// id is the observation domain or source id sampling
+type SamplerKey struct {
+	Exporter string
+	DomainID uint32
+	ID       uint64
+}
+
+// counters are the elements that scale by the sampling rate
+var counters = map[uint16]bool{
+	1:   true, // octetDeltaCount
+	2:   true, // packetDeltaCount
+	3:   true, // deltaFlowCount
+	23:  true, // postOctetDeltaCount
+	24:  true, // postPacketDeltaCount
+	85:  true, // octetTotalCount
+	86:  true, // packetTotalCount
+	231: true, // initiatorOctets
+	232: true, // responderOctets
+	298: true, // initiatorPackets
+	299: true, // responderPackets
+}
+
+// NewSampling constructs the sampling table
+func NewSampling() *Sampling {
+	return &Sampling{
+		rates:     make(map[SamplerKey]uint32),
+		overrides: make(map[string]uint32),
+	}
+}
+
+// SetOverride sets the static sampling rate of the exporter,
+// it overrides the announced sampling rates
+func (s *Sampling) SetOverride(exporter string, rate uint32) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.overrides[exporter] = rate
+}
+
+// Learn keeps the announced sampling rate of the sampler
+func (s *Sampling) Learn(key SamplerKey, rate uint32) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.rates[key] = rate
+}
+
+// Rate returns the effective sampling rate of a data record that refers
+// to the sampler, the record's own rate is used if it's not zero. it
+// returns zero if the sampling rate is unknown
+func (s *Sampling) Rate(key SamplerKey, rate uint32) uint32 {
+	s.RLock()
+	defer s.RUnlock()
+
+	if r, ok := s.overrides[key.Exporter]; ok {
+		return r
+	}
+
+	if rate > 0 {
+		return rate
+	}
+
+	if r, ok := s.rates[key]; ok {
+		return r
+	}
+
+	key.ID = 0
+
+	return s.rates[key]
+}
+
+// Rates returns a copy of the sampling table
+func (s *Sampling) Rates() map[SamplerKey]uint32 {
+	s.RLock()
+	defer s.RUnlock()
+
+	rates := make(map[SamplerKey]uint32, len(s.rates))
+	for k, r := range s.rates {
+		rates[k] = r
+	}
+
+	return rates
+}
+
+// SamplerFields returns the sampler id and the sampling rate of the
+// IANA elements of the record, the rate is the samplingInterval (34),
+// the samplerRandomInterval (50) or the PSAMP selectors count-based,
+// n-out-of-N and probability (305-311) parameters; the netflow v9
+// fields have the same ids
+func SamplerFields(fields []DecodedField) (uint64, uint32) {
+	var (
+		id               uint64
+		rate             uint32
+		interval, space  uint64
+		size, population uint64
+		probability      float64
+	)
+
+	for _, f := range fields {
+		if f.EnterpriseNo != 0 {
+			continue
+		}
+
+		switch f.ID {
+		case 48, 302:
+			id, _ = toUint(f.Value)
+		case 34, 50:
+			if v, _ := toUint(f.Value); v > 0 {
+				rate = uint32(v)
+			}
+		case 305:
+			interval, _ = toUint(f.Value)
+		case 306:
+			space, _ = toUint(f.Value)
+		case 309:
+			size, _ = toUint(f.Value)
+		case 310:
+			population, _ = toUint(f.Value)
+		case 311:
+			probability, _ = f.Value.(float64)
+		}
+	}
+
+	switch {
+	case rate > 0:
+	case interval > 0:
+		rate = uint32((interval + space) / interval)
+	case size > 0:
+		rate = uint32(population / size)
+	case probability > 0:
+		rate = uint32(1/probability + 0.5)
+	}
+
+	return id, rate
+}
+
+// UpscaleCounter returns the counter's value scaled by the sampling
+// rate, it returns false if the element isn't a counter
+func UpscaleCounter(id uint16, v interface{}, rate uint32) (uint64, bool) {
+	if !counters[id] {
+		return 0, false
+	}
+
+	n, ok := toUint(v)
+
+	return n * uint64(rate), ok
+}
+
+// learnSampling keeps the sampling rate of an options data record
+func (d *Decoder) learnSampling(domainID uint32, fields []DecodedField) {
+	if d.sampling == nil {
+		return
+	}
+
+	if id, rate := SamplerFields(fields); rate > 0 {
+		d.sampling.Learn(SamplerKey{d.agent(), domainID, id}, rate)
+	}
+}
+
+// annotateSampling sets the effective sampling rate of the data records,
+// it's after the message decoded as the options data can be after them.
+// the options data records aren't sampled, their rate stays zero
+func (d *Decoder) annotateSampling(msg *Message) {
+	if d.sampling == nil {
+		return
+	}
+
+	for _, m := range append([]*Message{msg}, msg.Pending...) {
+		m.SamplingRates = make([]uint32, len(m.DataSets))
+		for i, fields := range m.DataSets {
+			if m.IsOptions(i) {
+				continue
+			}
+
+			id, rate := SamplerFields(fields)
+			m.SamplingRates[i] = d.sampling.Rate(SamplerKey{m.AgentID, m.Header.DomainID, id}, rate)
+		}
+	}
+}
+
+// IsOptions returns true if the i-th data set decoded by an options template
+func (m *Message) IsOptions(i int) bool {
+	return i < len(m.Options) && m.Options[i]
+}
+
+// Upscale scales the data records counters by their sampling rate,
+// the counters of the records with unknown sampling stay as they are
+func (m *Message) Upscale() {
+	for i, fields := range m.DataSets {
+		if i >= len(m.SamplingRates) || m.SamplingRates[i] < 2 {
+			continue
+		}
+
+		for j := range fields {
+			if fields[j].EnterpriseNo != 0 {
+				continue
+			}
+
+			if v, ok := UpscaleCounter(fields[j].ID, fields[j].Value, m.SamplingRates[i]); ok {
+				fields[j].Value = v
+			}
+		}
+	}
+}
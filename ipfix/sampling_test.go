@@ -0,0 +1,110 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    sampling_test.go
+//: details: exporters sampling table from the options data
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package ipfix
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSamplerFields(t *testing.T) {
+	var tests = []struct {
+		fields []DecodedField
+		id     uint64
+		rate   uint32
+	}{
+		{[]DecodedField{{ID: 48, Value: uint8(2)}, {ID: 50, Value: uint32(1000)}}, 2, 1000},
+		{[]DecodedField{{ID: 34, Value: uint32(512)}}, 0, 512},
+		{[]DecodedField{{ID: 302, Value: uint64(7)}, {ID: 305, Value: uint32(1)}, {ID: 306, Value: uint32(99)}}, 7, 100},
+		{[]DecodedField{{ID: 309, Value: uint32(10)}, {ID: 310, Value: uint32(10000)}}, 0, 1000},
+		{[]DecodedField{{ID: 311, Value: float64(0.001)}}, 0, 1000},
+		{[]DecodedField{{ID: 48, Value: []byte{0x1, 0x2}}}, 0x102, 0},
+		{[]DecodedField{{ID: 34, Value: uint32(512), EnterpriseNo: 9}}, 0, 0},
+	}
+
+	for i, test := range tests {
+		id, rate := SamplerFields(test.fields)
+		if id != test.id || rate != test.rate {
+			t.Errorf("test %d expect id %d rate %d, got id %d rate %d", i, test.id, test.rate, id, rate)
+		}
+	}
+}
+
+func TestSamplingRate(t *testing.T) {
+	s := NewSampling()
+	s.Learn(SamplerKey{"192.0.2.1", 1, 2}, 1000)
+	s.Learn(SamplerKey{"192.0.2.1", 1, 0}, 100)
+	s.SetOverride("192.0.2.2", 64)
+
+	var tests = []struct {
+		key  SamplerKey
+		rate uint32
+		want uint32
+	}{
+		{SamplerKey{"192.0.2.1", 1, 2}, 0, 1000},
+		{SamplerKey{"192.0.2.1", 1, 3}, 0, 100},
+		{SamplerKey{"192.0.2.1", 1, 2}, 10, 10},
+		{SamplerKey{"192.0.2.1", 2, 2}, 0, 0},
+		{SamplerKey{"192.0.2.2", 1, 0}, 10, 64},
+	}
+
+	for i, test := range tests {
+		if r := s.Rate(test.key, test.rate); r != test.want {
+			t.Errorf("test %d expect rate %d, got %d", i, test.want, r)
+		}
+	}
+}
+
+func TestUpscale(t *testing.T) {
+	m := &Message{
+		DataSets: [][]DecodedField{
+			{{ID: 1, Value: uint64(1500)}, {ID: 2, Value: uint64(3)}, {ID: 7, Value: uint16(443)}},
+			{{ID: 1, Value: uint64(1500)}},
+		},
+		SamplingRates: []uint32{1000, 0},
+	}
+
+	m.Upscale()
+
+	if m.DataSets[0][0].Value != uint64(1500000) || m.DataSets[0][1].Value != uint64(3000) {
+		t.Error("expect the counters upscaled, got", m.DataSets[0])
+	}
+
+	if m.DataSets[0][2].Value != uint16(443) {
+		t.Error("expect the port as it is, got", m.DataSets[0][2])
+	}
+
+	if m.DataSets[1][0].Value != uint64(1500) {
+		t.Error("expect the unknown sampling as it is, got", m.DataSets[1][0])
+	}
+
+	b, err := m.JSONMarshalRecord(new(bytes.Buffer), 0, JSONFlat)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !strings.Contains(string(b), `"SamplingRate":1000,"Record":{"1":1500000`) {
+		t.Error("expect the record sampling rate, got", string(b))
+	}
+}
@@ -0,0 +1,197 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    typeinfo.go
+//: details: IPFIX information element type information RFC 5610
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package ipfix
+
+import (
+	"encoding/binary"
+	"sort"
+	"sync"
+)
+
+// LearnedElement represents an information element that an
+// exporter announced through the type information
+type LearnedElement struct {
+	EnterpriseNo uint32
+	ElementID    uint16
+	Name         string
+	Type         string
+}
+
+// RFC 5610 information element type information fields
+const (
+	typeInfoElementID    = 303
+	typeInfoDataType     = 339
+	typeInfoName         = 341
+	typeInfoEnterpriseNo = 346
+)
+
+// dataTypes maps the IANA information element data types
+// registry to the abstract data types, RFC 5610 3.1
+var dataTypes = []FieldType{
+	OctetArray, Uint8, Uint16, Uint32, Uint64, Int8, Int16, Int32, Int64,
+	Float32, Float64, Boolean, MacAddress, String, DateTimeSeconds,
+	DateTimeMilliseconds, DateTimeMicroseconds, DateTimeNanoseconds,
+	Ipv4Address, Ipv6Address, BasicList, SubTemplateList, SubTemplateMultiList,
+}
+
+// infoModelOverlay represents the per exporter information
+// elements that overlay the InfoModel
+var infoModelOverlay = struct {
+	exporters map[string]IANAInfoModel
+	sync.RWMutex
+}{
+	exporters: make(map[string]IANAInfoModel),
+}
+
+// overlayElement returns the information element that
+// the exporter announced
+func overlayElement(agent string, key ElementKey) (InfoElementEntry, bool) {
+	infoModelOverlay.RLock()
+	defer infoModelOverlay.RUnlock()
+
+	m, ok := infoModelOverlay.exporters[agent][key]
+
+	return m, ok
+}
+
+// agent returns the exporter identity regardless of the port
+func (d *Decoder) agent() string {
+	if d.agentID != "" {
+		return d.agentID
+	}
+
+	return d.raddr.String()
+}
+
+// learnTypeInfo registers the enterprise information element
+// if the options data record is an information element type
+// record, RFC 5610 3.2
+func (d *Decoder) learnTypeInfo(fields []DecodedField) {
+	var (
+		key      ElementKey
+		m        InfoElementEntry
+		id, code uint64
+		ok       bool
+	)
+
+	for _, f := range fields {
+		if f.EnterpriseNo != 0 {
+			continue
+		}
+
+		switch f.ID {
+		case typeInfoElementID:
+			id, ok = toUint(f.Value)
+		case typeInfoEnterpriseNo:
+			v, _ := toUint(f.Value)
+			key.EnterpriseNo = uint32(v)
+		case typeInfoDataType:
+			code, _ = toUint(f.Value)
+			m.Type = Unknown
+			if code < uint64(len(dataTypes)) {
+				m.Type = dataTypes[code]
+			}
+		case typeInfoName:
+			m.Name, _ = f.Value.(string)
+		}
+	}
+
+	// the IANA elements aren't overridden by the exporters
+	if !ok || key.EnterpriseNo == 0 || m.Type == Unknown {
+		return
+	}
+
+	key.ElementID = uint16(id) & 0x7fff
+	m.FieldID = key.ElementID
+
+	infoModelOverlay.Lock()
+	defer infoModelOverlay.Unlock()
+
+	model, ok := infoModelOverlay.exporters[d.agent()]
+	if !ok {
+		model = make(IANAInfoModel)
+		infoModelOverlay.exporters[d.agent()] = model
+	}
+
+	model[key] = m
+}
+
+// LearnedElements returns the information elements that the
+// exporters announced through the type information
+func LearnedElements() map[string][]LearnedElement {
+	var names = make(map[FieldType]string)
+	for name, t := range FieldTypes {
+		names[t] = name
+	}
+
+	infoModelOverlay.RLock()
+	defer infoModelOverlay.RUnlock()
+
+	learned := make(map[string][]LearnedElement)
+	for exporter, model := range infoModelOverlay.exporters {
+		elements := make([]LearnedElement, 0, len(model))
+		for key, m := range model {
+			elements = append(elements, LearnedElement{
+				EnterpriseNo: key.EnterpriseNo,
+				ElementID:    key.ElementID,
+				Name:         m.Name,
+				Type:         names[m.Type],
+			})
+		}
+
+		sort.Slice(elements, func(i, j int) bool {
+			if elements[i].EnterpriseNo != elements[j].EnterpriseNo {
+				return elements[i].EnterpriseNo < elements[j].EnterpriseNo
+			}
+			return elements[i].ElementID < elements[j].ElementID
+		})
+
+		learned[exporter] = elements
+	}
+
+	return learned
+}
+
+// toUint returns the unsigned value of the field even
+// if it's encoded in reduced size
+func toUint(v interface{}) (uint64, bool) {
+	switch v := v.(type) {
+	case uint8:
+		return uint64(v), true
+	case uint16:
+		return uint64(v), true
+	case uint32:
+		return uint64(v), true
+	case uint64:
+		return v, true
+	case []byte:
+		if len(v) == 0 || len(v) > 8 {
+			return 0, false
+		}
+		b := make([]byte, 8)
+		copy(b[8-len(v):], v)
+		return binary.BigEndian.Uint64(b), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,79 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    typeinfo_test.go
+//: details: IPFIX type information testing
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package ipfix
+
+import (
+	"encoding/binary"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeTypeInfo(t *testing.T) {
+	ip := net.ParseIP("192.0.2.10")
+	b := []byte{
+		0x0, 0xa, 0x0, 0x0, 0x5a, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0, 0x1,
+		// options template 258: scope informationElementId and privateEnterpriseNumber,
+		// informationElementDataType and informationElementName
+		0x0, 0x3, 0x0, 0x1a, 0x1, 0x2, 0x0, 0x4, 0x0, 0x2,
+		0x1, 0x2f, 0x0, 0x2, 0x1, 0x5a, 0x0, 0x4, 0x1, 0x53, 0x0, 0x1, 0x1, 0x55, 0xff, 0xff,
+		// type information of VMware element 893 (unsigned32)
+		0x1, 0x2, 0x0, 0x14, 0x3, 0x7d, 0x0, 0x0, 0x1a, 0xdc, 0x3, 0x8, 'v', 'x', 'l', 'a', 'n', 'V', 'n', 'i',
+		// template 256: sourceIPv4Address and VMware element 893
+		0x0, 0x2, 0x0, 0x14, 0x1, 0x0, 0x0, 0x2, 0x0, 0x8, 0x0, 0x4, 0x83, 0x7d, 0x0, 0x4, 0x0, 0x0, 0x1a, 0xdc,
+		// data set 256
+		0x1, 0x0, 0x0, 0xc, 0xc0, 0x0, 0x2, 0x1, 0x0, 0x0, 0x0, 0x2a,
+	}
+	binary.BigEndian.PutUint16(b[2:4], uint16(len(b)))
+
+	msg, err := NewDecoder(ip, b).Decode(NewMemCache())
+	if err != nil {
+		t.Fatal("unexpected error happened:", err)
+	}
+
+	if len(msg.DataSets) != 2 {
+		t.Fatal("expected 2 data sets, got", len(msg.DataSets))
+	}
+
+	if f := msg.DataSets[1][1]; f.Value != uint32(42) || f.EnterpriseNo != 6876 {
+		t.Error("expected the learned element decoded as unsigned32, got", f)
+	}
+
+	expected := []LearnedElement{{EnterpriseNo: 6876, ElementID: 893, Name: "vxlanVni", Type: "unsigned32"}}
+	if learned := LearnedElements()[ip.String()]; !reflect.DeepEqual(learned, expected) {
+		t.Error("expected learned elements", expected, "got", learned)
+	}
+
+	// the other exporters don't use the learned elements
+	b = append(b[:16:16], b[62:]...)
+	binary.BigEndian.PutUint16(b[2:4], uint16(len(b)))
+
+	msg, err = NewDecoder(net.ParseIP("192.0.2.11"), b).Decode(NewMemCache())
+	if err != nil {
+		t.Fatal("unexpected error happened:", err)
+	}
+
+	if _, ok := msg.DataSets[0][1].Value.([]byte); !ok {
+		t.Error("expected the unknown element as octet array, got", msg.DataSets[0][1].Value)
+	}
+}
@@ -0,0 +1,90 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    unknown.go
+//: details: IPFIX unknown information elements tracking
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package ipfix
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// UnknownElement represents an information element that
+// doesn't exist in the InfoModel and how often it's seen
+type UnknownElement struct {
+	EnterpriseNo uint32
+	ElementID    uint16
+	Count        uint64
+	LastSeen     int64
+}
+
+var unknownElements = struct {
+	elements map[ElementKey]*UnknownElement
+	sync.RWMutex
+}{
+	elements: make(map[ElementKey]*UnknownElement),
+}
+
+// seenUnknown counts the unknown information element
+func seenUnknown(key ElementKey) {
+	unknownElements.RLock()
+	e, ok := unknownElements.elements[key]
+	unknownElements.RUnlock()
+
+	if !ok {
+		unknownElements.Lock()
+		if e, ok = unknownElements.elements[key]; !ok {
+			e = &UnknownElement{EnterpriseNo: key.EnterpriseNo, ElementID: key.ElementID}
+			unknownElements.elements[key] = e
+		}
+		unknownElements.Unlock()
+	}
+
+	atomic.AddUint64(&e.Count, 1)
+	atomic.StoreInt64(&e.LastSeen, time.Now().Unix())
+}
+
+// UnknownElements returns the seen unknown information
+// elements sorted by the enterprise number and element id
+func UnknownElements() []UnknownElement {
+	unknownElements.RLock()
+	elements := make([]UnknownElement, 0, len(unknownElements.elements))
+	for _, e := range unknownElements.elements {
+		elements = append(elements, UnknownElement{
+			EnterpriseNo: e.EnterpriseNo,
+			ElementID:    e.ElementID,
+			Count:        atomic.LoadUint64(&e.Count),
+			LastSeen:     atomic.LoadInt64(&e.LastSeen),
+		})
+	}
+	unknownElements.RUnlock()
+
+	sort.Slice(elements, func(i, j int) bool {
+		if elements[i].EnterpriseNo != elements[j].EnterpriseNo {
+			return elements[i].EnterpriseNo < elements[j].EnterpriseNo
+		}
+		return elements[i].ElementID < elements[j].ElementID
+	})
+
+	return elements
+}
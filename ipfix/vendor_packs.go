@@ -0,0 +1,215 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    vendor_packs.go
+//: details: IPFIX bundled vendor information elements packs
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package ipfix
+
+// vendorPacks are the bundled vendor information elements,
+// they're enabled per pack through the configuration
+var vendorPacks = map[string]IANAInfoModel{
+	// Cisco Systems (9) application visibility and control, NBAR and RTP performance
+	"cisco": {
+		ElementKey{9, 4251}:  InfoElementEntry{FieldID: 4251, Name: "transportPacketsLostCounter", Type: FieldTypes["unsigned32"]},
+		ElementKey{9, 4254}:  InfoElementEntry{FieldID: 4254, Name: "transportRtpSsrc", Type: FieldTypes["unsigned32"]},
+		ElementKey{9, 4257}:  InfoElementEntry{FieldID: 4257, Name: "transportRtpJitterMaximum", Type: FieldTypes["unsigned32"]},
+		ElementKey{9, 4273}:  InfoElementEntry{FieldID: 4273, Name: "transportRtpPayloadType", Type: FieldTypes["unsigned8"]},
+		ElementKey{9, 4325}:  InfoElementEntry{FieldID: 4325, Name: "transportRtpJitterMeanSum", Type: FieldTypes["unsigned64"]},
+		ElementKey{9, 8233}:  InfoElementEntry{FieldID: 8233, Name: "c3plClassCceId", Type: FieldTypes["unsigned32"]},
+		ElementKey{9, 8234}:  InfoElementEntry{FieldID: 8234, Name: "c3plClassName", Type: FieldTypes["string"]},
+		ElementKey{9, 8236}:  InfoElementEntry{FieldID: 8236, Name: "c3plPolicyCceId", Type: FieldTypes["unsigned32"]},
+		ElementKey{9, 8237}:  InfoElementEntry{FieldID: 8237, Name: "c3plPolicyName", Type: FieldTypes["string"]},
+		ElementKey{9, 9252}:  InfoElementEntry{FieldID: 9252, Name: "connectionTransactionDurationSum", Type: FieldTypes["unsigned64"]},
+		ElementKey{9, 9253}:  InfoElementEntry{FieldID: 9253, Name: "connectionTransactionDurationMin", Type: FieldTypes["unsigned32"]},
+		ElementKey{9, 9254}:  InfoElementEntry{FieldID: 9254, Name: "connectionTransactionDurationMax", Type: FieldTypes["unsigned32"]},
+		ElementKey{9, 9268}:  InfoElementEntry{FieldID: 9268, Name: "connectionClientCounterPacketsRetransmitted", Type: FieldTypes["unsigned32"]},
+		ElementKey{9, 9272}:  InfoElementEntry{FieldID: 9272, Name: "connectionTransactionCounterComplete", Type: FieldTypes["unsigned32"]},
+		ElementKey{9, 9292}:  InfoElementEntry{FieldID: 9292, Name: "connectionServerCounterResponses", Type: FieldTypes["unsigned32"]},
+		ElementKey{9, 12232}: InfoElementEntry{FieldID: 12232, Name: "applicationCategoryName", Type: FieldTypes["string"]},
+		ElementKey{9, 12233}: InfoElementEntry{FieldID: 12233, Name: "applicationSubCategoryName", Type: FieldTypes["string"]},
+		ElementKey{9, 12234}: InfoElementEntry{FieldID: 12234, Name: "applicationGroupName", Type: FieldTypes["string"]},
+		ElementKey{9, 12235}: InfoElementEntry{FieldID: 12235, Name: "applicationHttpHost", Type: FieldTypes["string"]},
+		ElementKey{9, 12236}: InfoElementEntry{FieldID: 12236, Name: "clientIPv4Address", Type: FieldTypes["ipv4Address"]},
+		ElementKey{9, 12237}: InfoElementEntry{FieldID: 12237, Name: "serverIPv4Address", Type: FieldTypes["ipv4Address"]},
+		ElementKey{9, 12240}: InfoElementEntry{FieldID: 12240, Name: "clientTransportPort", Type: FieldTypes["unsigned16"]},
+		ElementKey{9, 12241}: InfoElementEntry{FieldID: 12241, Name: "serverTransportPort", Type: FieldTypes["unsigned16"]},
+		ElementKey{9, 12242}: InfoElementEntry{FieldID: 12242, Name: "connectionId", Type: FieldTypes["unsigned32"]},
+		ElementKey{9, 12243}: InfoElementEntry{FieldID: 12243, Name: "applicationTrafficClass", Type: FieldTypes["unsigned32"]},
+		ElementKey{9, 12244}: InfoElementEntry{FieldID: 12244, Name: "applicationBusinessRelevance", Type: FieldTypes["unsigned32"]},
+	},
+	// Juniper Networks (2636) inline J-Flow, the common properties id carries
+	// the forwarding class and the loss priority of the flow
+	"juniper": {
+		ElementKey{2636, 137}: InfoElementEntry{FieldID: 137, Name: "commonPropertiesId", Type: FieldTypes["unsigned64"]},
+	},
+	// VMware (6876) NSX and vSphere distributed switch tenant flows
+	"vmware": {
+		ElementKey{6876, 880}: InfoElementEntry{FieldID: 880, Name: "tenantProtocol", Type: FieldTypes["unsigned8"]},
+		ElementKey{6876, 881}: InfoElementEntry{FieldID: 881, Name: "tenantSourceIPv4", Type: FieldTypes["ipv4Address"]},
+		ElementKey{6876, 882}: InfoElementEntry{FieldID: 882, Name: "tenantDestIPv4", Type: FieldTypes["ipv4Address"]},
+		ElementKey{6876, 883}: InfoElementEntry{FieldID: 883, Name: "tenantSourceIPv6", Type: FieldTypes["ipv6Address"]},
+		ElementKey{6876, 884}: InfoElementEntry{FieldID: 884, Name: "tenantDestIPv6", Type: FieldTypes["ipv6Address"]},
+		ElementKey{6876, 886}: InfoElementEntry{FieldID: 886, Name: "tenantSourcePort", Type: FieldTypes["unsigned16"]},
+		ElementKey{6876, 887}: InfoElementEntry{FieldID: 887, Name: "tenantDestPort", Type: FieldTypes["unsigned16"]},
+		ElementKey{6876, 888}: InfoElementEntry{FieldID: 888, Name: "egressInterfaceAttr", Type: FieldTypes["unsigned16"]},
+		ElementKey{6876, 889}: InfoElementEntry{FieldID: 889, Name: "vxlanExportRole", Type: FieldTypes["unsigned8"]},
+		ElementKey{6876, 890}: InfoElementEntry{FieldID: 890, Name: "ingressInterfaceAttr", Type: FieldTypes["unsigned16"]},
+		ElementKey{6876, 891}: InfoElementEntry{FieldID: 891, Name: "virtualObsID", Type: FieldTypes["string"]},
+	},
+	// ntop nProbe (35632), the Netflow v9 templates carry them as 57472 + id
+	"ntop": {
+		ElementKey{35632, 80}:  InfoElementEntry{FieldID: 80, Name: "FRAGMENTS", Type: FieldTypes["unsigned32"]},
+		ElementKey{35632, 82}:  InfoElementEntry{FieldID: 82, Name: "CLIENT_NW_DELAY_SEC", Type: FieldTypes["unsigned32"]},
+		ElementKey{35632, 83}:  InfoElementEntry{FieldID: 83, Name: "SERVER_NW_DELAY_SEC", Type: FieldTypes["unsigned32"]},
+		ElementKey{35632, 84}:  InfoElementEntry{FieldID: 84, Name: "APPL_LATENCY_SEC", Type: FieldTypes["unsigned32"]},
+		ElementKey{35632, 98}:  InfoElementEntry{FieldID: 98, Name: "ICMP_FLAGS", Type: FieldTypes["unsigned32"]},
+		ElementKey{35632, 101}: InfoElementEntry{FieldID: 101, Name: "SRC_IP_COUNTRY", Type: FieldTypes["string"]},
+		ElementKey{35632, 102}: InfoElementEntry{FieldID: 102, Name: "SRC_IP_CITY", Type: FieldTypes["string"]},
+		ElementKey{35632, 103}: InfoElementEntry{FieldID: 103, Name: "DST_IP_COUNTRY", Type: FieldTypes["string"]},
+		ElementKey{35632, 104}: InfoElementEntry{FieldID: 104, Name: "DST_IP_CITY", Type: FieldTypes["string"]},
+		ElementKey{35632, 105}: InfoElementEntry{FieldID: 105, Name: "FLOW_PROTO_PORT", Type: FieldTypes["unsigned16"]},
+		ElementKey{35632, 106}: InfoElementEntry{FieldID: 106, Name: "UPSTREAM_TUNNEL_ID", Type: FieldTypes["unsigned32"]},
+		ElementKey{35632, 107}: InfoElementEntry{FieldID: 107, Name: "LONGEST_FLOW_PKT", Type: FieldTypes["unsigned16"]},
+		ElementKey{35632, 108}: InfoElementEntry{FieldID: 108, Name: "SHORTEST_FLOW_PKT", Type: FieldTypes["unsigned16"]},
+		ElementKey{35632, 109}: InfoElementEntry{FieldID: 109, Name: "RETRANSMITTED_IN_PKTS", Type: FieldTypes["unsigned32"]},
+		ElementKey{35632, 110}: InfoElementEntry{FieldID: 110, Name: "RETRANSMITTED_OUT_PKTS", Type: FieldTypes["unsigned32"]},
+		ElementKey{35632, 111}: InfoElementEntry{FieldID: 111, Name: "OOORDER_IN_PKTS", Type: FieldTypes["unsigned32"]},
+		ElementKey{35632, 112}: InfoElementEntry{FieldID: 112, Name: "OOORDER_OUT_PKTS", Type: FieldTypes["unsigned32"]},
+		ElementKey{35632, 113}: InfoElementEntry{FieldID: 113, Name: "UNTUNNELED_PROTOCOL", Type: FieldTypes["unsigned8"]},
+		ElementKey{35632, 114}: InfoElementEntry{FieldID: 114, Name: "UNTUNNELED_IPV4_SRC_ADDR", Type: FieldTypes["ipv4Address"]},
+		ElementKey{35632, 115}: InfoElementEntry{FieldID: 115, Name: "UNTUNNELED_L4_SRC_PORT", Type: FieldTypes["unsigned16"]},
+		ElementKey{35632, 116}: InfoElementEntry{FieldID: 116, Name: "UNTUNNELED_IPV4_DST_ADDR", Type: FieldTypes["ipv4Address"]},
+		ElementKey{35632, 117}: InfoElementEntry{FieldID: 117, Name: "UNTUNNELED_L4_DST_PORT", Type: FieldTypes["unsigned16"]},
+		ElementKey{35632, 118}: InfoElementEntry{FieldID: 118, Name: "L7_PROTO", Type: FieldTypes["unsigned16"]},
+		ElementKey{35632, 119}: InfoElementEntry{FieldID: 119, Name: "L7_PROTO_NAME", Type: FieldTypes["string"]},
+		ElementKey{35632, 120}: InfoElementEntry{FieldID: 120, Name: "DOWNSTREAM_TUNNEL_ID", Type: FieldTypes["unsigned32"]},
+		ElementKey{35632, 121}: InfoElementEntry{FieldID: 121, Name: "FLOW_USER_NAME", Type: FieldTypes["string"]},
+		ElementKey{35632, 122}: InfoElementEntry{FieldID: 122, Name: "FLOW_SERVER_NAME", Type: FieldTypes["string"]},
+		ElementKey{35632, 123}: InfoElementEntry{FieldID: 123, Name: "CLIENT_NW_DELAY_MS", Type: FieldTypes["unsigned32"]},
+		ElementKey{35632, 124}: InfoElementEntry{FieldID: 124, Name: "SERVER_NW_DELAY_MS", Type: FieldTypes["unsigned32"]},
+		ElementKey{35632, 125}: InfoElementEntry{FieldID: 125, Name: "APPL_LATENCY_MS", Type: FieldTypes["unsigned32"]},
+		ElementKey{35632, 126}: InfoElementEntry{FieldID: 126, Name: "PLUGIN_NAME", Type: FieldTypes["string"]},
+		ElementKey{35632, 127}: InfoElementEntry{FieldID: 127, Name: "RETRANSMITTED_IN_BYTES", Type: FieldTypes["unsigned32"]},
+		ElementKey{35632, 128}: InfoElementEntry{FieldID: 128, Name: "RETRANSMITTED_OUT_BYTES", Type: FieldTypes["unsigned32"]},
+		ElementKey{35632, 180}: InfoElementEntry{FieldID: 180, Name: "HTTP_URL", Type: FieldTypes["string"]},
+		ElementKey{35632, 181}: InfoElementEntry{FieldID: 181, Name: "HTTP_RET_CODE", Type: FieldTypes["unsigned16"]},
+		ElementKey{35632, 182}: InfoElementEntry{FieldID: 182, Name: "HTTP_REFERER", Type: FieldTypes["string"]},
+		ElementKey{35632, 183}: InfoElementEntry{FieldID: 183, Name: "HTTP_UA", Type: FieldTypes["string"]},
+		ElementKey{35632, 184}: InfoElementEntry{FieldID: 184, Name: "HTTP_MIME", Type: FieldTypes["string"]},
+		ElementKey{35632, 185}: InfoElementEntry{FieldID: 185, Name: "SMTP_MAIL_FROM", Type: FieldTypes["string"]},
+		ElementKey{35632, 186}: InfoElementEntry{FieldID: 186, Name: "SMTP_RCPT_TO", Type: FieldTypes["string"]},
+		ElementKey{35632, 187}: InfoElementEntry{FieldID: 187, Name: "HTTP_HOST", Type: FieldTypes["string"]},
+		ElementKey{35632, 188}: InfoElementEntry{FieldID: 188, Name: "SSL_SERVER_NAME", Type: FieldTypes["string"]},
+		ElementKey{35632, 205}: InfoElementEntry{FieldID: 205, Name: "DNS_QUERY", Type: FieldTypes["string"]},
+		ElementKey{35632, 206}: InfoElementEntry{FieldID: 206, Name: "DNS_QUERY_ID", Type: FieldTypes["unsigned16"]},
+		ElementKey{35632, 207}: InfoElementEntry{FieldID: 207, Name: "DNS_QUERY_TYPE", Type: FieldTypes["unsigned16"]},
+		ElementKey{35632, 208}: InfoElementEntry{FieldID: 208, Name: "DNS_RET_CODE", Type: FieldTypes["unsigned8"]},
+		ElementKey{35632, 209}: InfoElementEntry{FieldID: 209, Name: "DNS_NUM_ANSWERS", Type: FieldTypes["unsigned8"]},
+		ElementKey{0, 57552}:   InfoElementEntry{FieldID: 57552, Name: "FRAGMENTS", Type: FieldTypes["unsigned32"]},
+		ElementKey{0, 57554}:   InfoElementEntry{FieldID: 57554, Name: "CLIENT_NW_DELAY_SEC", Type: FieldTypes["unsigned32"]},
+		ElementKey{0, 57555}:   InfoElementEntry{FieldID: 57555, Name: "SERVER_NW_DELAY_SEC", Type: FieldTypes["unsigned32"]},
+		ElementKey{0, 57556}:   InfoElementEntry{FieldID: 57556, Name: "APPL_LATENCY_SEC", Type: FieldTypes["unsigned32"]},
+		ElementKey{0, 57570}:   InfoElementEntry{FieldID: 57570, Name: "ICMP_FLAGS", Type: FieldTypes["unsigned32"]},
+		ElementKey{0, 57573}:   InfoElementEntry{FieldID: 57573, Name: "SRC_IP_COUNTRY", Type: FieldTypes["string"]},
+		ElementKey{0, 57574}:   InfoElementEntry{FieldID: 57574, Name: "SRC_IP_CITY", Type: FieldTypes["string"]},
+		ElementKey{0, 57575}:   InfoElementEntry{FieldID: 57575, Name: "DST_IP_COUNTRY", Type: FieldTypes["string"]},
+		ElementKey{0, 57576}:   InfoElementEntry{FieldID: 57576, Name: "DST_IP_CITY", Type: FieldTypes["string"]},
+		ElementKey{0, 57577}:   InfoElementEntry{FieldID: 57577, Name: "FLOW_PROTO_PORT", Type: FieldTypes["unsigned16"]},
+		ElementKey{0, 57578}:   InfoElementEntry{FieldID: 57578, Name: "UPSTREAM_TUNNEL_ID", Type: FieldTypes["unsigned32"]},
+		ElementKey{0, 57579}:   InfoElementEntry{FieldID: 57579, Name: "LONGEST_FLOW_PKT", Type: FieldTypes["unsigned16"]},
+		ElementKey{0, 57580}:   InfoElementEntry{FieldID: 57580, Name: "SHORTEST_FLOW_PKT", Type: FieldTypes["unsigned16"]},
+		ElementKey{0, 57581}:   InfoElementEntry{FieldID: 57581, Name: "RETRANSMITTED_IN_PKTS", Type: FieldTypes["unsigned32"]},
+		ElementKey{0, 57582}:   InfoElementEntry{FieldID: 57582, Name: "RETRANSMITTED_OUT_PKTS", Type: FieldTypes["unsigned32"]},
+		ElementKey{0, 57583}:   InfoElementEntry{FieldID: 57583, Name: "OOORDER_IN_PKTS", Type: FieldTypes["unsigned32"]},
+		ElementKey{0, 57584}:   InfoElementEntry{FieldID: 57584, Name: "OOORDER_OUT_PKTS", Type: FieldTypes["unsigned32"]},
+		ElementKey{0, 57585}:   InfoElementEntry{FieldID: 57585, Name: "UNTUNNELED_PROTOCOL", Type: FieldTypes["unsigned8"]},
+		ElementKey{0, 57586}:   InfoElementEntry{FieldID: 57586, Name: "UNTUNNELED_IPV4_SRC_ADDR", Type: FieldTypes["ipv4Address"]},
+		ElementKey{0, 57587}:   InfoElementEntry{FieldID: 57587, Name: "UNTUNNELED_L4_SRC_PORT", Type: FieldTypes["unsigned16"]},
+		ElementKey{0, 57588}:   InfoElementEntry{FieldID: 57588, Name: "UNTUNNELED_IPV4_DST_ADDR", Type: FieldTypes["ipv4Address"]},
+		ElementKey{0, 57589}:   InfoElementEntry{FieldID: 57589, Name: "UNTUNNELED_L4_DST_PORT", Type: FieldTypes["unsigned16"]},
+		ElementKey{0, 57590}:   InfoElementEntry{FieldID: 57590, Name: "L7_PROTO", Type: FieldTypes["unsigned16"]},
+		ElementKey{0, 57591}:   InfoElementEntry{FieldID: 57591, Name: "L7_PROTO_NAME", Type: FieldTypes["string"]},
+		ElementKey{0, 57592}:   InfoElementEntry{FieldID: 57592, Name: "DOWNSTREAM_TUNNEL_ID", Type: FieldTypes["unsigned32"]},
+		ElementKey{0, 57593}:   InfoElementEntry{FieldID: 57593, Name: "FLOW_USER_NAME", Type: FieldTypes["string"]},
+		ElementKey{0, 57594}:   InfoElementEntry{FieldID: 57594, Name: "FLOW_SERVER_NAME", Type: FieldTypes["string"]},
+		ElementKey{0, 57595}:   InfoElementEntry{FieldID: 57595, Name: "CLIENT_NW_DELAY_MS", Type: FieldTypes["unsigned32"]},
+		ElementKey{0, 57596}:   InfoElementEntry{FieldID: 57596, Name: "SERVER_NW_DELAY_MS", Type: FieldTypes["unsigned32"]},
+		ElementKey{0, 57597}:   InfoElementEntry{FieldID: 57597, Name: "APPL_LATENCY_MS", Type: FieldTypes["unsigned32"]},
+		ElementKey{0, 57598}:   InfoElementEntry{FieldID: 57598, Name: "PLUGIN_NAME", Type: FieldTypes["string"]},
+		ElementKey{0, 57599}:   InfoElementEntry{FieldID: 57599, Name: "RETRANSMITTED_IN_BYTES", Type: FieldTypes["unsigned32"]},
+		ElementKey{0, 57600}:   InfoElementEntry{FieldID: 57600, Name: "RETRANSMITTED_OUT_BYTES", Type: FieldTypes["unsigned32"]},
+		ElementKey{0, 57652}:   InfoElementEntry{FieldID: 57652, Name: "HTTP_URL", Type: FieldTypes["string"]},
+		ElementKey{0, 57653}:   InfoElementEntry{FieldID: 57653, Name: "HTTP_RET_CODE", Type: FieldTypes["unsigned16"]},
+		ElementKey{0, 57654}:   InfoElementEntry{FieldID: 57654, Name: "HTTP_REFERER", Type: FieldTypes["string"]},
+		ElementKey{0, 57655}:   InfoElementEntry{FieldID: 57655, Name: "HTTP_UA", Type: FieldTypes["string"]},
+		ElementKey{0, 57656}:   InfoElementEntry{FieldID: 57656, Name: "HTTP_MIME", Type: FieldTypes["string"]},
+		ElementKey{0, 57657}:   InfoElementEntry{FieldID: 57657, Name: "SMTP_MAIL_FROM", Type: FieldTypes["string"]},
+		ElementKey{0, 57658}:   InfoElementEntry{FieldID: 57658, Name: "SMTP_RCPT_TO", Type: FieldTypes["string"]},
+		ElementKey{0, 57659}:   InfoElementEntry{FieldID: 57659, Name: "HTTP_HOST", Type: FieldTypes["string"]},
+		ElementKey{0, 57660}:   InfoElementEntry{FieldID: 57660, Name: "SSL_SERVER_NAME", Type: FieldTypes["string"]},
+		ElementKey{0, 57677}:   InfoElementEntry{FieldID: 57677, Name: "DNS_QUERY", Type: FieldTypes["string"]},
+		ElementKey{0, 57678}:   InfoElementEntry{FieldID: 57678, Name: "DNS_QUERY_ID", Type: FieldTypes["unsigned16"]},
+		ElementKey{0, 57679}:   InfoElementEntry{FieldID: 57679, Name: "DNS_QUERY_TYPE", Type: FieldTypes["unsigned16"]},
+		ElementKey{0, 57680}:   InfoElementEntry{FieldID: 57680, Name: "DNS_RET_CODE", Type: FieldTypes["unsigned8"]},
+		ElementKey{0, 57681}:   InfoElementEntry{FieldID: 57681, Name: "DNS_NUM_ANSWERS", Type: FieldTypes["unsigned8"]},
+	},
+	// Palo Alto Networks (25461) PAN-OS Netflow v9 App-ID and User-ID
+	"paloalto": {
+		ElementKey{0, 56701}: InfoElementEntry{FieldID: 56701, Name: "applicationId", Type: FieldTypes["string"]},
+		ElementKey{0, 56702}: InfoElementEntry{FieldID: 56702, Name: "userId", Type: FieldTypes["string"]},
+	},
+	// Barracuda Networks (10704) CloudGen firewall extended IPFIX
+	"barracuda": {
+		ElementKey{10704, 1}:  InfoElementEntry{FieldID: 1, Name: "timestamp", Type: FieldTypes["dateTimeSeconds"]},
+		ElementKey{10704, 2}:  InfoElementEntry{FieldID: 2, Name: "logOp", Type: FieldTypes["unsigned8"]},
+		ElementKey{10704, 3}:  InfoElementEntry{FieldID: 3, Name: "trafficType", Type: FieldTypes["unsigned8"]},
+		ElementKey{10704, 4}:  InfoElementEntry{FieldID: 4, Name: "fwRule", Type: FieldTypes["string"]},
+		ElementKey{10704, 5}:  InfoElementEntry{FieldID: 5, Name: "serviceName", Type: FieldTypes["string"]},
+		ElementKey{10704, 6}:  InfoElementEntry{FieldID: 6, Name: "reason", Type: FieldTypes["unsigned32"]},
+		ElementKey{10704, 7}:  InfoElementEntry{FieldID: 7, Name: "reasonText", Type: FieldTypes["string"]},
+		ElementKey{10704, 8}:  InfoElementEntry{FieldID: 8, Name: "bindIPv4Address", Type: FieldTypes["ipv4Address"]},
+		ElementKey{10704, 9}:  InfoElementEntry{FieldID: 9, Name: "bindTransportPort", Type: FieldTypes["unsigned16"]},
+		ElementKey{10704, 10}: InfoElementEntry{FieldID: 10, Name: "connIPv4Address", Type: FieldTypes["ipv4Address"]},
+		ElementKey{10704, 11}: InfoElementEntry{FieldID: 11, Name: "connTransportPort", Type: FieldTypes["unsigned16"]},
+		ElementKey{10704, 12}: InfoElementEntry{FieldID: 12, Name: "auditCounter", Type: FieldTypes["unsigned32"]},
+	},
+	// Gigamon (26866) application metadata, HTTP, SSL and DNS
+	"gigamon": {
+		ElementKey{26866, 1}:   InfoElementEntry{FieldID: 1, Name: "httpReqUrl", Type: FieldTypes["string"]},
+		ElementKey{26866, 2}:   InfoElementEntry{FieldID: 2, Name: "httpRspStatus", Type: FieldTypes["unsigned16"]},
+		ElementKey{26866, 101}: InfoElementEntry{FieldID: 101, Name: "sslCertificateIssuerCommonName", Type: FieldTypes["string"]},
+		ElementKey{26866, 102}: InfoElementEntry{FieldID: 102, Name: "sslCertificateSubjectCommonName", Type: FieldTypes["string"]},
+		ElementKey{26866, 103}: InfoElementEntry{FieldID: 103, Name: "sslCertificateIssuer", Type: FieldTypes["string"]},
+		ElementKey{26866, 104}: InfoElementEntry{FieldID: 104, Name: "sslCertificateSubject", Type: FieldTypes["string"]},
+		ElementKey{26866, 112}: InfoElementEntry{FieldID: 112, Name: "sslServerNameIndication", Type: FieldTypes["string"]},
+		ElementKey{26866, 113}: InfoElementEntry{FieldID: 113, Name: "sslServerVersion", Type: FieldTypes["unsigned16"]},
+		ElementKey{26866, 114}: InfoElementEntry{FieldID: 114, Name: "sslServerCipher", Type: FieldTypes["unsigned16"]},
+		ElementKey{26866, 201}: InfoElementEntry{FieldID: 201, Name: "dnsIdentifier", Type: FieldTypes["unsigned16"]},
+		ElementKey{26866, 202}: InfoElementEntry{FieldID: 202, Name: "dnsOpCode", Type: FieldTypes["unsigned8"]},
+		ElementKey{26866, 203}: InfoElementEntry{FieldID: 203, Name: "dnsResponseCode", Type: FieldTypes["unsigned8"]},
+		ElementKey{26866, 204}: InfoElementEntry{FieldID: 204, Name: "dnsQueryName", Type: FieldTypes["string"]},
+		ElementKey{26866, 205}: InfoElementEntry{FieldID: 205, Name: "dnsResponseName", Type: FieldTypes["string"]},
+		ElementKey{26866, 206}: InfoElementEntry{FieldID: 206, Name: "dnsResponseTTL", Type: FieldTypes["unsigned32"]},
+		ElementKey{26866, 207}: InfoElementEntry{FieldID: 207, Name: "dnsResponseIPv4Address", Type: FieldTypes["ipv4Address"]},
+		ElementKey{26866, 208}: InfoElementEntry{FieldID: 208, Name: "dnsResponseIPv6Address", Type: FieldTypes["ipv6Address"]},
+		ElementKey{26866, 214}: InfoElementEntry{FieldID: 214, Name: "dnsQueryType", Type: FieldTypes["unsigned16"]},
+		ElementKey{26866, 215}: InfoElementEntry{FieldID: 215, Name: "dnsQueryClass", Type: FieldTypes["unsigned16"]},
+	},
+}
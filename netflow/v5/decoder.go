@@ -0,0 +1,352 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    decoder.go
+//: details: decodes netflow version 5 packets
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package netflow5
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/VerizonDigital/vflow/reader"
+)
+
+const (
+	// recordLen is the fixed size of the netflow v5 flow record
+	recordLen = 48
+
+	// maxRecords is the maximum number of records in a packet
+	maxRecords = 30
+)
+
+// PacketHeader represents Netflow v5 packet header
+type PacketHeader struct {
+	Version          uint16 // NetFlow export format version number
+	Count            uint16 // Number of flows exported in this packet (1-30)
+	SysUpTime        uint32 // Current time in milliseconds since the export device booted
+	UNIXSecs         uint32 // Current count of seconds since 0000 UTC 1970
+	UNIXNSecs        uint32 // Residual nanoseconds since 0000 UTC 1970
+	SeqNum           uint32 // Sequence counter of total flows seen
+	EngineType       uint8  // Type of flow-switching engine
+	EngineID         uint8  // Slot number of the flow-switching engine
+	SamplingInterval uint16 // First two bits hold the sampling mode; remaining 14 bits hold value of sampling interval
+}
+
+// FlowRecord represents Netflow v5 flow record
+type FlowRecord struct {
+	SrcAddr  net.IP // Source IP address
+	DstAddr  net.IP // Destination IP address
+	NextHop  net.IP // IP address of next hop router
+	Input    uint16 // SNMP index of input interface
+	Output   uint16 // SNMP index of output interface
+	DPkts    uint32 // Packets in the flow
+	DOctets  uint32 // Total number of Layer 3 bytes in the packets of the flow
+	First    uint32 // SysUptime at start of flow
+	Last     uint32 // SysUptime at the time the last packet of the flow was received
+	SrcPort  uint16 // TCP/UDP source port number or equivalent
+	DstPort  uint16 // TCP/UDP destination port number or equivalent
+	TCPFlags uint8  // Cumulative OR of TCP flags
+	Prot     uint8  // IP protocol type (for example, TCP = 6; UDP = 17)
+	Tos      uint8  // IP type of service (ToS)
+	SrcAS    uint16 // Autonomous system number of the source, either origin or peer
+	DstAS    uint16 // Autonomous system number of the destination, either origin or peer
+	SrcMask  uint8  // Source address prefix mask bits
+	DstMask  uint8  // Destination address prefix mask bits
+}
+
+// DecodedField represents a decoded field
+type DecodedField struct {
+	ID    uint16
+	Value interface{}
+}
+
+// Decoder represents Netflow payload and remote address
+type Decoder struct {
+	raddr  net.IP
+	reader *reader.Reader
+}
+
+// Message represents Netflow decoded data
+type Message struct {
+	AgentID  string
+	Header   PacketHeader
+	DataSets [][]DecodedField
+}
+
+var errInvalidLength = errors.New("invalid netflow v5 packet length")
+
+//   The Packet Header format is specified as:
+//
+//    0                   1                   2                   3
+//    0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//   +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//   |       Version Number          |            Count              |
+//   +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//   |                           sysUpTime                           |
+//   +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//   |                           UNIX Secs                           |
+//   +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//   |                           UNIX NSecs                          |
+//   +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//   |                       Sequence Number                         |
+//   +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//   | Engine Type   |   Engine ID   |      Sampling Interval        |
+//   +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+func (h *PacketHeader) unmarshal(r *reader.Reader) error {
+	var err error
+
+	if h.Version, err = r.Uint16(); err != nil {
+		return err
+	}
+
+	if h.Count, err = r.Uint16(); err != nil {
+		return err
+	}
+
+	if h.SysUpTime, err = r.Uint32(); err != nil {
+		return err
+	}
+
+	if h.UNIXSecs, err = r.Uint32(); err != nil {
+		return err
+	}
+
+	if h.UNIXNSecs, err = r.Uint32(); err != nil {
+		return err
+	}
+
+	if h.SeqNum, err = r.Uint32(); err != nil {
+		return err
+	}
+
+	if h.EngineType, err = r.Uint8(); err != nil {
+		return err
+	}
+
+	if h.EngineID, err = r.Uint8(); err != nil {
+		return err
+	}
+
+	if h.SamplingInterval, err = r.Uint16(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (h *PacketHeader) validate() error {
+	if h.Version != 5 {
+		return fmt.Errorf("invalid netflow version (%d)", h.Version)
+	}
+
+	if h.Count < 1 || h.Count > maxRecords {
+		return fmt.Errorf("invalid netflow v5 record count (%d)", h.Count)
+	}
+
+	return nil
+}
+
+// 0                   1                   2                   3
+// 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |                     Source IP Address                         |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |                   Destination IP Address                      |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |                      Next Hop Address                         |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |       Input Interface         |      Output Interface         |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |                          Packets                              |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |                           Octets                              |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |                   First Switched (sysUpTime)                  |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |                   Last Switched (sysUpTime)                   |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |         Source Port           |      Destination Port         |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |    Pad 1      |  TCP Flags    |   Protocol    |      ToS      |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |          Source AS            |       Destination AS          |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |  Source Mask  |   Dest Mask   |            Pad 2              |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+func (f *FlowRecord) unmarshal(r *reader.Reader) error {
+	var (
+		b   []byte
+		err error
+	)
+
+	if b, err = r.Read(4); err != nil {
+		return err
+	}
+	f.SrcAddr = net.IP(b)
+
+	if b, err = r.Read(4); err != nil {
+		return err
+	}
+	f.DstAddr = net.IP(b)
+
+	if b, err = r.Read(4); err != nil {
+		return err
+	}
+	f.NextHop = net.IP(b)
+
+	if f.Input, err = r.Uint16(); err != nil {
+		return err
+	}
+
+	if f.Output, err = r.Uint16(); err != nil {
+		return err
+	}
+
+	if f.DPkts, err = r.Uint32(); err != nil {
+		return err
+	}
+
+	if f.DOctets, err = r.Uint32(); err != nil {
+		return err
+	}
+
+	if f.First, err = r.Uint32(); err != nil {
+		return err
+	}
+
+	if f.Last, err = r.Uint32(); err != nil {
+		return err
+	}
+
+	if f.SrcPort, err = r.Uint16(); err != nil {
+		return err
+	}
+
+	if f.DstPort, err = r.Uint16(); err != nil {
+		return err
+	}
+
+	// pad1
+	if _, err = r.Uint8(); err != nil {
+		return err
+	}
+
+	if f.TCPFlags, err = r.Uint8(); err != nil {
+		return err
+	}
+
+	if f.Prot, err = r.Uint8(); err != nil {
+		return err
+	}
+
+	if f.Tos, err = r.Uint8(); err != nil {
+		return err
+	}
+
+	if f.SrcAS, err = r.Uint16(); err != nil {
+		return err
+	}
+
+	if f.DstAS, err = r.Uint16(); err != nil {
+		return err
+	}
+
+	if f.SrcMask, err = r.Uint8(); err != nil {
+		return err
+	}
+
+	if f.DstMask, err = r.Uint8(); err != nil {
+		return err
+	}
+
+	// pad2
+	_, err = r.Uint16()
+
+	return err
+}
+
+// fields converts the flow record to the netflow v9 / IPFIX
+// field ids so the output is the same as the other decoders
+func (f *FlowRecord) fields() []DecodedField {
+	return []DecodedField{
+		{ID: 8, Value: f.SrcAddr},
+		{ID: 12, Value: f.DstAddr},
+		{ID: 15, Value: f.NextHop},
+		{ID: 10, Value: f.Input},
+		{ID: 14, Value: f.Output},
+		{ID: 2, Value: f.DPkts},
+		{ID: 1, Value: f.DOctets},
+		{ID: 22, Value: f.First},
+		{ID: 21, Value: f.Last},
+		{ID: 7, Value: f.SrcPort},
+		{ID: 11, Value: f.DstPort},
+		{ID: 6, Value: f.TCPFlags},
+		{ID: 4, Value: f.Prot},
+		{ID: 5, Value: f.Tos},
+		{ID: 16, Value: f.SrcAS},
+		{ID: 17, Value: f.DstAS},
+		{ID: 9, Value: f.SrcMask},
+		{ID: 13, Value: f.DstMask},
+	}
+}
+
+// NewDecoder constructs a decoder
+func NewDecoder(raddr net.IP, b []byte) *Decoder {
+	return &Decoder{raddr, reader.NewReader(b)}
+}
+
+// Decode decodes the flow records
+func (d *Decoder) Decode() (*Message, error) {
+	var (
+		msg = new(Message)
+		fr  FlowRecord
+	)
+
+	// Netflow v5 Packet Header decoding
+	if err := msg.Header.unmarshal(d.reader); err != nil {
+		return nil, err
+	}
+	// Netflow v5 Packet Header validation
+	if err := msg.Header.validate(); err != nil {
+		return nil, err
+	}
+
+	// there is no template in netflow v5, the length is fixed
+	if d.reader.Len() < int(msg.Header.Count)*recordLen {
+		return nil, errInvalidLength
+	}
+
+	// Add source IP address as Agent ID
+	msg.AgentID = d.raddr.String()
+
+	for i := uint16(0); i < msg.Header.Count; i++ {
+		if err := fr.unmarshal(d.reader); err != nil {
+			return msg, err
+		}
+
+		msg.DataSets = append(msg.DataSets, fr.fields())
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,171 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    decoder_test.go
+//: details: netflow v5 decoder tests and benchmarks
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package netflow5
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"testing"
+)
+
+// netflow v5 packet with two flow records
+var v5Packet = []byte{
+	// header
+	0x0, 0x5, 0x0, 0x2, 0x0, 0x1, 0xe2, 0x40, 0x5a, 0x1b, 0x2c, 0x3d,
+	0x0, 0x0, 0x3, 0xe8, 0x0, 0x0, 0x0, 0x64, 0x1, 0x2, 0x40, 0x64,
+	// record 1
+	0xc0, 0xa8, 0x1, 0xa, 0xa, 0x0, 0x0, 0x1, 0xc0, 0xa8, 0x1, 0x1,
+	0x0, 0x3, 0x0, 0x4, 0x0, 0x0, 0x0, 0xa, 0x0, 0x0, 0x5, 0xdc,
+	0x0, 0x1, 0xe0, 0x0, 0x0, 0x1, 0xe2, 0x0, 0xc3, 0x50, 0x1, 0xbb,
+	0x0, 0x1b, 0x6, 0x0, 0xfd, 0xe8, 0x3b, 0x41, 0x18, 0x8, 0x0, 0x0,
+	// record 2
+	0xa, 0x0, 0x0, 0x1, 0xc0, 0xa8, 0x1, 0xa, 0xc0, 0xa8, 0x1, 0x1,
+	0x0, 0x4, 0x0, 0x3, 0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0, 0x48,
+	0x0, 0x1, 0xe0, 0x0, 0x0, 0x1, 0xe0, 0x0, 0x0, 0x35, 0xd4, 0x31,
+	0x0, 0x0, 0x11, 0x0, 0x3b, 0x41, 0xfd, 0xe8, 0x8, 0x18, 0x0, 0x0,
+}
+
+func TestDecodeNoData(t *testing.T) {
+	ip := net.ParseIP("127.0.0.1")
+	d := NewDecoder(ip, []byte{})
+	if _, err := d.Decode(); err == nil {
+		t.Error("expected err but nothing")
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	ip := net.ParseIP("127.0.0.1")
+	d := NewDecoder(ip, v5Packet[:len(v5Packet)-10])
+	if _, err := d.Decode(); err != errInvalidLength {
+		t.Error("expected invalid length error, got", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	ip := net.ParseIP("127.0.0.1")
+	d := NewDecoder(ip, v5Packet)
+	msg, err := d.Decode()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if msg.Header.Count != 2 {
+		t.Error("expected count 2, got", msg.Header.Count)
+	}
+
+	if msg.Header.SamplingInterval&0x3fff != 100 {
+		t.Error("expected sampling interval 100, got", msg.Header.SamplingInterval&0x3fff)
+	}
+
+	if l := len(msg.DataSets); l != 2 {
+		t.Fatal("expected 2 datasets, got", l)
+	}
+
+	expect := map[uint16]interface{}{
+		8:  "192.168.1.10",
+		12: "10.0.0.1",
+		7:  uint16(50000),
+		11: uint16(443),
+		1:  uint32(1500),
+		2:  uint32(10),
+		4:  uint8(6),
+		16: uint16(65000),
+		17: uint16(15169),
+	}
+
+	for _, f := range msg.DataSets[0] {
+		v, ok := expect[f.ID]
+		if !ok {
+			continue
+		}
+
+		if ip, ok := f.Value.(net.IP); ok {
+			if ip.String() != v {
+				t.Errorf("expect ID %d value %v, got %v", f.ID, v, ip)
+			}
+		} else if f.Value != v {
+			t.Errorf("expect ID %d value %v, got %v", f.ID, v, f.Value)
+		}
+	}
+}
+
+func TestJSONMarshal(t *testing.T) {
+	var (
+		buf = new(bytes.Buffer)
+		msg struct {
+			AgentID  string
+			Header   PacketHeader
+			DataSets [][]struct {
+				I uint16
+				V interface{}
+			}
+		}
+	)
+
+	d := NewDecoder(net.ParseIP("127.0.0.1"), v5Packet)
+	m, err := d.Decode()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	b, err := m.JSONMarshal(buf)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if err = json.Unmarshal(b, &msg); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if msg.AgentID != "127.0.0.1" {
+		t.Error("expect AgentID 127.0.0.1, got", msg.AgentID)
+	}
+
+	if msg.Header.Version != 5 {
+		t.Error("expect Version 5, got", msg.Header.Version)
+	}
+
+	if len(msg.DataSets) != 2 || msg.DataSets[1][0].V != "10.0.0.1" {
+		t.Error("unexpected data sets", msg.DataSets)
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	ip := net.ParseIP("127.0.0.1")
+	for i := 0; i < b.N; i++ {
+		d := NewDecoder(ip, v5Packet)
+		d.Decode()
+	}
+}
+
+func TestJSONMarshalUnknownType(t *testing.T) {
+	m := &Message{
+		AgentID:  "127.0.0.1",
+		DataSets: [][]DecodedField{{{ID: 8, Value: struct{}{}}, {ID: 12, Value: net.IP{10, 0, 0, 1}}}},
+	}
+
+	if _, err := m.JSONMarshal(new(bytes.Buffer)); err != errUknownMarshalDataType {
+		t.Error("expect the unknown data type error, got", err)
+	}
+}
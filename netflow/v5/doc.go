@@ -0,0 +1,2 @@
+// Package netflow5 decodes netflow version v5 packets
+package netflow5
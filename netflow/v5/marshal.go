@@ -0,0 +1,141 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    marshal.go
+//: details: encoding of each decoded netflow v5 flow records
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package netflow5
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"strconv"
+)
+
+var errUknownMarshalDataType = errors.New("unknown data type to marshal")
+
+// JSONMarshal encodes netflow v5 message
+func (m *Message) JSONMarshal(b *bytes.Buffer) ([]byte, error) {
+	b.WriteString("{")
+
+	// encode agent id
+	m.encodeAgent(b)
+
+	// encode header
+	m.encodeHeader(b)
+
+	// encode data sets
+	if err := m.encodeDataSet(b); err != nil {
+		return nil, err
+	}
+
+	b.WriteString("}")
+
+	return b.Bytes(), nil
+}
+
+func (m *Message) encodeDataSet(b *bytes.Buffer) error {
+	var (
+		length   int
+		dsLength int
+	)
+
+	b.WriteString("\"DataSets\":")
+	dsLength = len(m.DataSets)
+
+	b.WriteByte('[')
+
+	for i := range m.DataSets {
+		length = len(m.DataSets[i])
+
+		b.WriteByte('[')
+		for j := range m.DataSets[i] {
+			b.WriteString("{\"I\":")
+			b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].ID), 10))
+			b.WriteString(",\"V\":")
+			if err := m.writeValue(b, i, j); err != nil {
+				return err
+			}
+
+			if j < length-1 {
+				b.WriteString("},")
+			} else {
+				b.WriteByte('}')
+			}
+		}
+
+		if i < dsLength-1 {
+			b.WriteString("],")
+		} else {
+			b.WriteByte(']')
+		}
+	}
+
+	b.WriteByte(']')
+
+	return nil
+}
+
+func (m *Message) encodeHeader(b *bytes.Buffer) {
+	b.WriteString("\"Header\":{\"Version\":")
+	b.WriteString(strconv.FormatInt(int64(m.Header.Version), 10))
+	b.WriteString(",\"Count\":")
+	b.WriteString(strconv.FormatInt(int64(m.Header.Count), 10))
+	b.WriteString(",\"SysUpTime\":")
+	b.WriteString(strconv.FormatInt(int64(m.Header.SysUpTime), 10))
+	b.WriteString(",\"UNIXSecs\":")
+	b.WriteString(strconv.FormatInt(int64(m.Header.UNIXSecs), 10))
+	b.WriteString(",\"UNIXNSecs\":")
+	b.WriteString(strconv.FormatInt(int64(m.Header.UNIXNSecs), 10))
+	b.WriteString(",\"SeqNum\":")
+	b.WriteString(strconv.FormatInt(int64(m.Header.SeqNum), 10))
+	b.WriteString(",\"EngineType\":")
+	b.WriteString(strconv.FormatInt(int64(m.Header.EngineType), 10))
+	b.WriteString(",\"EngineID\":")
+	b.WriteString(strconv.FormatInt(int64(m.Header.EngineID), 10))
+	b.WriteString(",\"SamplingInterval\":")
+	b.WriteString(strconv.FormatInt(int64(m.Header.SamplingInterval), 10))
+	b.WriteString("},")
+}
+
+func (m *Message) encodeAgent(b *bytes.Buffer) {
+	b.WriteString("\"AgentID\":\"")
+	b.WriteString(m.AgentID)
+	b.WriteString("\",")
+}
+
+func (m *Message) writeValue(b *bytes.Buffer, i, j int) error {
+	switch m.DataSets[i][j].Value.(type) {
+	case uint8:
+		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(uint8)), 10))
+	case uint16:
+		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(uint16)), 10))
+	case uint32:
+		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(uint32)), 10))
+	case net.IP:
+		b.WriteByte('"')
+		b.WriteString(m.DataSets[i][j].Value.(net.IP).String())
+		b.WriteByte('"')
+	default:
+		return errUknownMarshalDataType
+	}
+
+	return nil
+}
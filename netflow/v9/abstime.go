@@ -0,0 +1,62 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    abstime.go
+//: details: netflow v9 system uptime to absolute time
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package netflow9
+
+// the absolute flow start and end fields in unix milliseconds
+const (
+	flowStartMilliseconds = 152
+	flowEndMilliseconds   = 153
+)
+
+// UnixMilli returns the unix milliseconds of the system uptime in
+// milliseconds, the uptime before the header's uptime wraparound
+// (49.7 days) still resolves to the past and the uptime slightly
+// after the header's uptime resolves to the future
+func (h PacketHeader) UnixMilli(upTime uint32) int64 {
+	return int64(h.UNIXSecs)*1000 - int64(int32(h.SysUpTime-upTime))
+}
+
+// AbsoluteTime converts the FIRST_SWITCHED and LAST_SWITCHED fields
+// of the data records to the flowStartMilliseconds and flowEndMilliseconds
+// in unix milliseconds
+func (m *Message) AbsoluteTime() {
+	for _, fields := range m.DataSets {
+		for i := range fields {
+			v, ok := fields[i].Value.(uint32)
+			if !ok {
+				continue
+			}
+
+			switch fields[i].ID {
+			case 22:
+				fields[i].ID = flowStartMilliseconds
+			case 21:
+				fields[i].ID = flowEndMilliseconds
+			default:
+				continue
+			}
+
+			fields[i].Value = uint64(m.Header.UnixMilli(v))
+		}
+	}
+}
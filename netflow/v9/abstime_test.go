@@ -0,0 +1,74 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    abstime_test.go
+//: details: netflow v9 flow time fields to absolute time tests
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package netflow9
+
+import "testing"
+
+func TestAbsoluteTime(t *testing.T) {
+	m := &Message{
+		Header: PacketHeader{SysUpTime: 10000, UNIXSecs: 1493918653},
+		DataSets: [][]DecodedField{
+			{
+				{ID: 22, Value: uint32(4000)},
+				{ID: 21, Value: uint32(9000)},
+				{ID: 8, Value: uint32(1)},
+			},
+			{
+				// started before the uptime wraparound
+				{ID: 22, Value: uint32(0xffffff00)},
+				{ID: 21, Value: uint32(10000)},
+			},
+			{
+				// switched after the header's uptime
+				{ID: 22, Value: uint32(9500)},
+				{ID: 21, Value: uint32(10500)},
+			},
+		},
+	}
+
+	m.AbsoluteTime()
+
+	expected := [][]DecodedField{
+		{
+			{ID: 152, Value: uint64(1493918647000)},
+			{ID: 153, Value: uint64(1493918652000)},
+			{ID: 8, Value: uint32(1)},
+		},
+		{
+			{ID: 152, Value: uint64(1493918642744)},
+			{ID: 153, Value: uint64(1493918653000)},
+		},
+		{
+			{ID: 152, Value: uint64(1493918652500)},
+			{ID: 153, Value: uint64(1493918653500)},
+		},
+	}
+
+	for i := range expected {
+		for j := range expected[i] {
+			if m.DataSets[i][j] != expected[i][j] {
+				t.Errorf("record %d field %d expect %#v, got %#v", i, j, expected[i][j], m.DataSets[i][j])
+			}
+		}
+	}
+}
@@ -28,6 +28,9 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	"../../../vflow/ipfix"
 	"../../../vflow/reader"
@@ -82,17 +85,34 @@ type DecodedField struct {
 
 // Decoder represents Netflow payload and remote address
 type Decoder struct {
-	raddr  net.IP
-	reader *reader.Reader
+	raddr    net.IP
+	reader   *reader.Reader
+	port     int
+	pending  *Pending
+	sampling *ipfix.Sampling
+	rcvd     int64
 }
 
 // Message represents Netflow decoded data
 type Message struct {
 	AgentID      string
+	ReceiveTime  int64 // collector receive time in unix milliseconds
 	Header       PacketHeader
 	TemplaRecord TemplateRecord
 	SetHeaders    []SetHeader
 	DataSets     [][]DecodedField
+
+	// SamplingRates represents the effective sampling rate of
+	// the data sets once the sampling table is set, zero unknown
+	SamplingRates []uint32
+
+	// Options represents the data sets that decoded by an options
+	// template, they're the exporter's metadata and not the flows
+	Options []bool
+
+	// Pending represents the buffered messages that
+	// decoded once their template arrived by this message
+	Pending []*Message
 }
 
 //   The Packet Header format is specified as:
@@ -303,7 +323,7 @@ func (tr *TemplateRecord) unmarshalOpts(r *reader.Reader) error {
 			return err
 		}
 
-		tr.ScopeFieldSpecifiers = append(tr.FieldSpecifiers, tf)
+		tr.ScopeFieldSpecifiers = append(tr.ScopeFieldSpecifiers, tf)
 	}
 
 	for i := th.OptionLen / 4; i > 0; i-- {
@@ -326,20 +346,21 @@ func (d *Decoder) decodeData(tr TemplateRecord) ([]DecodedField, error) {
 
 	r := d.reader
 
-	for i := 0; i < len(tr.FieldSpecifiers); i++ {
-		b, err = r.Read(int(tr.FieldSpecifiers[i].Length))
+	// the scope fields come first in the options data record
+	for i := 0; i < len(tr.ScopeFieldSpecifiers); i++ {
+		b, err = r.Read(int(tr.ScopeFieldSpecifiers[i].Length))
 		if err != nil {
 			return nil, err
 		}
 
 		m, ok := ipfix.InfoModel[ipfix.ElementKey{
 			0,
-			tr.FieldSpecifiers[i].ElementID,
+			tr.ScopeFieldSpecifiers[i].ElementID,
 		}]
 
 		if !ok {
-			return nil, nonfatalError(fmt.Errorf("Netflow element key (%d) not exist",
-				tr.FieldSpecifiers[i].ElementID))
+			return nil, nonfatalError(fmt.Errorf("Netflow element key (%d) not exist (scope)",
+				tr.ScopeFieldSpecifiers[i].ElementID))
 		}
 
 		fields = append(fields, DecodedField{
@@ -348,20 +369,20 @@ func (d *Decoder) decodeData(tr TemplateRecord) ([]DecodedField, error) {
 		})
 	}
 
-	for i := 0; i < len(tr.ScopeFieldSpecifiers); i++ {
-		b, err = r.Read(int(tr.ScopeFieldSpecifiers[i].Length))
+	for i := 0; i < len(tr.FieldSpecifiers); i++ {
+		b, err = r.Read(int(tr.FieldSpecifiers[i].Length))
 		if err != nil {
 			return nil, err
 		}
 
 		m, ok := ipfix.InfoModel[ipfix.ElementKey{
 			0,
-			tr.ScopeFieldSpecifiers[i].ElementID,
+			tr.FieldSpecifiers[i].ElementID,
 		}]
 
 		if !ok {
-			return nil, nonfatalError(fmt.Errorf("Netflow element key (%d) not exist (scope)",
-				tr.ScopeFieldSpecifiers[i].ElementID))
+			return nil, nonfatalError(fmt.Errorf("Netflow element key (%d) not exist",
+				tr.FieldSpecifiers[i].ElementID))
 		}
 
 		fields = append(fields, DecodedField{
@@ -375,7 +396,45 @@ func (d *Decoder) decodeData(tr TemplateRecord) ([]DecodedField, error) {
 
 // NewDecoder constructs a decoder
 func NewDecoder(raddr net.IP, b []byte) *Decoder {
-	return &Decoder{raddr, reader.NewReader(b)}
+	return &Decoder{raddr: raddr, reader: reader.NewReader(b)}
+}
+
+// SetSourcePort sets the exporter's source port, it includes
+// in the templates scope next to the source id
+func (d *Decoder) SetSourcePort(port int) {
+	d.port = port
+}
+
+// exporter returns the exporter identity as string
+func (d *Decoder) exporter() string {
+	if d.port != 0 {
+		return net.JoinHostPort(d.raddr.String(), strconv.Itoa(d.port))
+	}
+
+	return d.raddr.String()
+}
+
+// tplScope returns the templates scope of the source id
+func (d *Decoder) tplScope(srcID uint32) TemplateScope {
+	return TemplateScope{Addr: d.raddr, SrcID: srcID, Port: d.port}
+}
+
+// SetReceiveTime sets the time that the collector received the
+// packet, the buffered data sets keep their own receive time
+func (d *Decoder) SetReceiveTime(t time.Time) {
+	d.rcvd = t.UnixNano() / int64(time.Millisecond)
+}
+
+// SetSampling sets the sampling table to learn the sampling rates
+// from the options data and annotate the data records by them
+func (d *Decoder) SetSampling(s *ipfix.Sampling) {
+	d.sampling = s
+}
+
+// SetPending sets the pending buffer to keep the data sets
+// that their template hasn't arrived yet
+func (d *Decoder) SetPending(p *Pending) {
+	d.pending = p
 }
 
 // Decode decodes the flow records
@@ -393,6 +452,7 @@ func (d *Decoder) Decode(mem MemCache) (*Message, error) {
 
 	// Add source IP address as Agent ID
 	msg.AgentID = d.raddr.String()
+	msg.ReceiveTime = d.rcvd
 
 	// In case there are multiple non-fatal errors, collect them and report all of them.
 	// The rest of the received sets will still be interpreted, until a fatal error is encountered.
@@ -409,6 +469,8 @@ func (d *Decoder) Decode(mem MemCache) (*Message, error) {
 		}
 	}
 
+	d.annotateSampling(msg)
+
 	return msg, combineErrors(decodeErrors...)
 }
 
@@ -428,8 +490,12 @@ func (d *Decoder) decodeSet(mem MemCache, msg *Message) error {
 	// This check is somewhat redundant with the switch-clause below, but the retrieve() operation should not be executed inside the loop.
 	if setHeader.FlowSetID > 255 {
 		var ok bool
-		tr, ok = mem.retrieve(setHeader.FlowSetID, d.raddr)
+		tr, ok = mem.retrieve(setHeader.FlowSetID, d.tplScope(msg.Header.SrcID))
 		if !ok {
+			if d.pending != nil {
+				return d.bufferSet(msg, setHeader)
+			}
+
 			err = nonfatalError(fmt.Errorf("%s unknown netflow template id# %d",
 				d.raddr.String(),
 				setHeader.FlowSetID,
@@ -448,7 +514,8 @@ func (d *Decoder) decodeSet(mem MemCache, msg *Message) error {
 				err = tr.unmarshalOpts(d.reader)
 			}
 			if err == nil {
-				mem.insert(tr.TemplateID, d.raddr, tr)
+				mem.insert(tr.TemplateID, d.tplScope(msg.Header.SrcID), tr)
+				d.decodePending(msg, tr)
 			}
 
 			//TODO add template record to message
@@ -461,8 +528,12 @@ func (d *Decoder) decodeSet(mem MemCache, msg *Message) error {
 			var data []DecodedField
 			data, err = d.decodeData(tr)
 			if err == nil {
+				if len(tr.ScopeFieldSpecifiers) > 0 {
+					d.learnSampling(msg.Header.SrcID, data)
+				}
 				msg.DataSets = append(msg.DataSets, data)
 				msg.SetHeaders = append(msg.SetHeaders,*setHeader)
+				msg.Options = append(msg.Options, len(tr.ScopeFieldSpecifiers) > 0)
 			}
 		}
 	}
@@ -479,6 +550,71 @@ func (d *Decoder) decodeSet(mem MemCache, msg *Message) error {
 	return err
 }
 
+// bufferSet keeps the data set in the pending buffer
+func (d *Decoder) bufferSet(msg *Message, setHeader *SetHeader) error {
+	b, err := d.reader.Read(int(setHeader.Length) - 4)
+	if err != nil {
+		return err
+	}
+
+	d.pending.add(d.exporter(), pendingKey{msg.Header.SrcID, setHeader.FlowSetID}, pendingSet{
+		header:      msg.Header,
+		agentID:     msg.AgentID,
+		receiveTime: msg.ReceiveTime,
+		data:        append([]byte{}, b...),
+		timestamp:   time.Now(),
+	})
+
+	return nil
+}
+
+// decodePending decodes the buffered data sets of the template
+func (d *Decoder) decodePending(msg *Message, tr TemplateRecord) {
+	if d.pending == nil {
+		return
+	}
+
+	sets := d.pending.take(d.exporter(), pendingKey{msg.Header.SrcID, tr.TemplateID})
+	if len(sets) < 1 {
+		return
+	}
+
+	// the padding is shorter than the minimum record length
+	minLen := 0
+	for _, f := range tr.ScopeFieldSpecifiers {
+		minLen += int(f.Length)
+	}
+	for _, f := range tr.FieldSpecifiers {
+		minLen += int(f.Length)
+	}
+
+	for _, s := range sets {
+		pd := &Decoder{raddr: d.raddr, reader: reader.NewReader(s.data), port: d.port, sampling: d.sampling}
+		pm := &Message{AgentID: s.agentID, ReceiveTime: s.receiveTime, Header: s.header}
+
+		for minLen > 0 && pd.reader.Len() >= minLen {
+			data, err := pd.decodeData(tr)
+			if err != nil {
+				break
+			}
+			if len(tr.ScopeFieldSpecifiers) > 0 {
+				pd.learnSampling(s.header.SrcID, data)
+			}
+			pm.DataSets = append(pm.DataSets, data)
+			pm.SetHeaders = append(pm.SetHeaders, SetHeader{tr.TemplateID, uint16(len(s.data) + 4)})
+			pm.Options = append(pm.Options, len(tr.ScopeFieldSpecifiers) > 0)
+		}
+
+		if len(pm.DataSets) < 1 {
+			atomic.AddUint64(&d.pending.stats.DroppedCount, 1)
+			continue
+		}
+
+		atomic.AddUint64(&d.pending.stats.DecodedCount, 1)
+		msg.Pending = append(msg.Pending, pm)
+	}
+}
+
 func combineErrors(errorSlice ...error) (err error) {
 	switch len(errorSlice) {
 	case 0:
@@ -36,3 +36,35 @@ func TestDecodeNoData(t *testing.T) {
 		t.Error("expected err but nothing")
 	}
 }
+
+func TestDecodeOptionsData(t *testing.T) {
+	// options template 257 with the system and interface scope fields
+	// and the sampling interval, followed by its options data record
+	body := []byte{
+		0x0, 0x9, 0x0, 0x2, 0x0, 0x0, 0x27, 0x10, 0x59, 0xb, 0x1f, 0xbd,
+		0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0, 0x7,
+		0x0, 0x1, 0x0, 0x18, 0x1, 0x1, 0x0, 0x8, 0x0, 0x4,
+		0x0, 0x1, 0x0, 0x4, 0x0, 0x2, 0x0, 0x4, 0x0, 0x22, 0x0, 0x4, 0x0, 0x0,
+		0x1, 0x1, 0x0, 0x10, 0xc0, 0x0, 0x2, 0x1, 0x0, 0x0, 0x0, 0x5, 0x0, 0x0, 0x3, 0xe8,
+	}
+
+	d := NewDecoder(net.ParseIP("192.0.2.1"), body)
+	m, err := d.Decode(NewMemCache())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if len(m.DataSets) != 1 || len(m.DataSets[0]) != 3 {
+		t.Fatal("expect an options data record with 3 fields, got", m.DataSets)
+	}
+
+	for i, id := range []uint16{1, 2, 34} {
+		if m.DataSets[0][i].ID != id {
+			t.Errorf("expect field %d id %d, got %d", i, id, m.DataSets[0][i].ID)
+		}
+	}
+
+	if m.DataSets[0][2].Value != uint32(1000) {
+		t.Error("expect the sampling interval 1000, got", m.DataSets[0][2].Value)
+	}
+}
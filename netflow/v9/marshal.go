@@ -28,12 +28,22 @@ import (
 	"errors"
 	"net"
 	"strconv"
+
+	"../../../vflow/ipfix"
 )
 
 var errUknownMarshalDataType = errors.New("unknown data type to marshal")
 
 // JSONMarshal encodes netflow v9 message
 func (m *Message) JSONMarshal(b *bytes.Buffer) ([]byte, error) {
+	return m.JSONMarshalFormat(b, ipfix.JSONCompact)
+}
+
+// JSONMarshalFormat encodes netflow v9 message, the data
+// records encode in the format
+func (m *Message) JSONMarshalFormat(b *bytes.Buffer, format ipfix.JSONFormat) ([]byte, error) {
+	var err error
+
 	b.WriteString("{")
 
 	// encode agent id
@@ -43,10 +53,18 @@ func (m *Message) JSONMarshal(b *bytes.Buffer) ([]byte, error) {
 	m.encodeHeader(b)
 
 	// encode data sets
-	if err := m.encodeDataSet(b); err != nil {
+	if format == ipfix.JSONCompact {
+		err = m.encodeDataSet(b)
+	} else {
+		err = m.encodeDataSetFlat(b, format == ipfix.JSONNamed)
+	}
+
+	if err != nil {
 		return nil, err
 	}
 
+	m.encodeSamplingRates(b)
+
 	b.WriteString("}")
 
 	return b.Bytes(), nil
@@ -54,7 +72,6 @@ func (m *Message) JSONMarshal(b *bytes.Buffer) ([]byte, error) {
 
 func (m *Message) encodeDataSet(b *bytes.Buffer) error {
 	var (
-		length   int
 		dsLength int
 		err      error
 	)
@@ -65,26 +82,10 @@ func (m *Message) encodeDataSet(b *bytes.Buffer) error {
 	b.WriteByte('[')
 
 	for i := range m.DataSets {
-		length = len(m.DataSets[i])
-
-		b.WriteByte('[')
-		for j := range m.DataSets[i] {
-			b.WriteString("{\"I\":")
-			b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].ID), 10))
-			b.WriteString(",\"V\":")
-			err = m.writeValue(b, i, j)
-
-			if j < length-1 {
-				b.WriteString("},")
-			} else {
-				b.WriteByte('}')
-			}
-		}
+		err = m.writeFields(b, i)
 
 		if i < dsLength-1 {
-			b.WriteString("],")
-		} else {
-			b.WriteByte(']')
+			b.WriteByte(',')
 		}
 	}
 
@@ -93,37 +94,47 @@ func (m *Message) encodeDataSet(b *bytes.Buffer) error {
 	return err
 }
 
-func (m *Message) encodeDataSetFlat(b *bytes.Buffer) error {
+// writeFields encodes the i-th data record as an array of the fields
+func (m *Message) writeFields(b *bytes.Buffer, i int) error {
 	var (
-		length   int
-		dsLength int
-		err      error
+		length = len(m.DataSets[i])
+		err    error
 	)
 
+	b.WriteByte('[')
+	for j := range m.DataSets[i] {
+		b.WriteString("{\"I\":")
+		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].ID), 10))
+		b.WriteString(",\"V\":")
+		err = m.writeValue(b, i, j)
+
+		if j < length-1 {
+			b.WriteString("},")
+		} else {
+			b.WriteByte('}')
+		}
+	}
+	b.WriteByte(']')
+
+	return err
+}
+
+// encodeDataSetFlat encodes the data records as objects, the keys
+// are the element ids or the element names if it's named
+func (m *Message) encodeDataSetFlat(b *bytes.Buffer, named bool) error {
+	var err error
+
 	b.WriteString("\"DataSets\":")
-	dsLength = len(m.DataSets)
 
 	b.WriteByte('[')
 
 	for i := range m.DataSets {
-		length = len(m.DataSets[i])
-
-		b.WriteByte('{')
-		for j := range m.DataSets[i] {
-			b.WriteByte('"')
-			b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].ID), 10))
-			b.WriteString("\":")
-			err = m.writeValue(b, i, j)
-
-			if j < length-1 {
-				b.WriteByte(',')
-			} else {
-				b.WriteByte('}')
-			}
+		if i > 0 {
+			b.WriteByte(',')
 		}
 
-		if i < dsLength-1 {
-			b.WriteString(",")
+		if e := m.writeObject(b, i, named); e != nil {
+			err = e
 		}
 	}
 
@@ -132,6 +143,72 @@ func (m *Message) encodeDataSetFlat(b *bytes.Buffer) error {
 	return err
 }
 
+// writeObject encodes the i-th data record as an object
+func (m *Message) writeObject(b *bytes.Buffer, i int, named bool) error {
+	keys := make([]string, len(m.DataSets[i]))
+	for j := range m.DataSets[i] {
+		keys[j] = fieldKey(m.DataSets[i][j].ID, named)
+	}
+
+	return ipfix.WriteObject(b, keys, func(j int) error {
+		return m.writeValue(b, i, j)
+	})
+}
+
+// JSONMarshalRecord encodes the i-th data record of the netflow v9
+// message as a message by itself, the exporter, export time, system
+// uptime, sequence number and source id are folded in
+func (m *Message) JSONMarshalRecord(b *bytes.Buffer, i int, format ipfix.JSONFormat) ([]byte, error) {
+	var err error
+
+	b.WriteString("{")
+
+	// encode agent id
+	m.encodeAgent(b)
+
+	b.WriteString("\"SysUpTime\":")
+	b.WriteString(strconv.FormatInt(int64(m.Header.SysUpTime), 10))
+	b.WriteString(",\"UNIXSecs\":")
+	b.WriteString(strconv.FormatInt(int64(m.Header.UNIXSecs), 10))
+	b.WriteString(",\"SeqNum\":")
+	b.WriteString(strconv.FormatInt(int64(m.Header.SeqNum), 10))
+	b.WriteString(",\"SrcID\":")
+	b.WriteString(strconv.FormatInt(int64(m.Header.SrcID), 10))
+
+	if i < len(m.SamplingRates) {
+		b.WriteString(",\"SamplingRate\":")
+		b.WriteString(strconv.FormatUint(uint64(m.SamplingRates[i]), 10))
+	}
+
+	b.WriteString(",\"Record\":")
+
+	if format == ipfix.JSONCompact {
+		err = m.writeFields(b, i)
+	} else {
+		err = m.writeObject(b, i, format == ipfix.JSONNamed)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	b.WriteString("}")
+
+	return b.Bytes(), nil
+}
+
+// fieldKey returns the element name if it's named and known,
+// otherwise the element id
+func fieldKey(id uint16, named bool) string {
+	if named {
+		if m, ok := ipfix.InfoModel[ipfix.ElementKey{EnterpriseNo: 0, ElementID: id}]; ok && m.Name != "" {
+			return m.Name
+		}
+	}
+
+	return strconv.FormatUint(uint64(id), 10)
+}
+
 func (m *Message) encodeHeader(b *bytes.Buffer) {
 	b.WriteString("\"Header\":{\"Version\":")
 	b.WriteString(strconv.FormatInt(int64(m.Header.Version), 10))
@@ -148,16 +225,38 @@ func (m *Message) encodeHeader(b *bytes.Buffer) {
 	b.WriteString("},")
 }
 
+// encodeSamplingRates encodes the data sets sampling rates if they're annotated
+func (m *Message) encodeSamplingRates(b *bytes.Buffer) {
+	if len(m.SamplingRates) < 1 {
+		return
+	}
+
+	b.WriteString(",\"SamplingRates\":[")
+	for i, r := range m.SamplingRates {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatUint(uint64(r), 10))
+	}
+	b.WriteByte(']')
+}
+
 func (m *Message) encodeAgent(b *bytes.Buffer) {
 	b.WriteString("\"AgentID\":\"")
 	b.WriteString(m.AgentID)
 	b.WriteString("\",")
+
+	if m.ReceiveTime != 0 {
+		b.WriteString("\"ReceiveTime\":")
+		b.WriteString(strconv.FormatInt(m.ReceiveTime, 10))
+		b.WriteByte(',')
+	}
 }
 
 func (m *Message) writeValue(b *bytes.Buffer, i, j int) error {
 	switch m.DataSets[i][j].Value.(type) {
 	case uint:
-		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(uint)), 10))
+		b.WriteString(strconv.FormatUint(uint64(m.DataSets[i][j].Value.(uint)), 10))
 	case uint8:
 		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(uint8)), 10))
 	case uint16:
@@ -165,7 +264,7 @@ func (m *Message) writeValue(b *bytes.Buffer, i, j int) error {
 	case uint32:
 		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(uint32)), 10))
 	case uint64:
-		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(uint64)), 10))
+		b.WriteString(strconv.FormatUint(m.DataSets[i][j].Value.(uint64), 10))
 	case int:
 		b.WriteString(strconv.FormatInt(int64(m.DataSets[i][j].Value.(int)), 10))
 	case int8:
@@ -180,10 +279,10 @@ func (m *Message) writeValue(b *bytes.Buffer, i, j int) error {
 		b.WriteString(strconv.FormatFloat(float64(m.DataSets[i][j].Value.(float32)), 'E', -1, 32))
 	case float64:
 		b.WriteString(strconv.FormatFloat(m.DataSets[i][j].Value.(float64), 'E', -1, 64))
+	case bool:
+		b.WriteString(strconv.FormatBool(m.DataSets[i][j].Value.(bool)))
 	case string:
-		b.WriteByte('"')
-		b.WriteString(m.DataSets[i][j].Value.(string))
-		b.WriteByte('"')
+		ipfix.WriteString(b, m.DataSets[i][j].Value.(string))
 	case net.IP:
 		b.WriteByte('"')
 		b.WriteString(m.DataSets[i][j].Value.(net.IP).String())
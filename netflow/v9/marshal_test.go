@@ -0,0 +1,106 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    marshal_test.go
+//: details: netflow v9 JSON marshal tests
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package netflow9
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+
+	"../../../vflow/ipfix"
+)
+
+func TestJSONMarshalNamed(t *testing.T) {
+	msg := Message{
+		AgentID:  "192.0.2.1",
+		DataSets: [][]DecodedField{{{ID: 8, Value: net.IP{10, 0, 0, 1}}, {ID: 60000, Value: uint8(1)}}},
+	}
+
+	b, err := msg.JSONMarshalFormat(new(bytes.Buffer), ipfix.JSONNamed)
+	if err != nil {
+		t.Fatal("unexpected error happened:", err)
+	}
+
+	if !strings.Contains(string(b), `"DataSets":[{"sourceIPv4Address":"10.0.0.1","60000":1}]`) {
+		t.Error("expected the named data sets, got", string(b))
+	}
+}
+
+func TestJSONMarshalRecord(t *testing.T) {
+	msg := Message{
+		AgentID:  "192.0.2.1",
+		Header:   PacketHeader{SysUpTime: 357280, UNIXSecs: 1493918653, SeqNum: 14, SrcID: 87},
+		DataSets: [][]DecodedField{{{ID: 8, Value: net.IP{10, 0, 0, 1}}}},
+	}
+
+	b, err := msg.JSONMarshalRecord(new(bytes.Buffer), 0, ipfix.JSONFlat)
+	if err != nil {
+		t.Fatal("unexpected error happened:", err)
+	}
+
+	expected := `{"AgentID":"192.0.2.1","SysUpTime":357280,"UNIXSecs":1493918653,"SeqNum":14,"SrcID":87,"Record":{"8":"10.0.0.1"}}`
+	if string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, b)
+	}
+}
+
+func TestJSONMarshalValues(t *testing.T) {
+	msg := Message{
+		AgentID:  "192.0.2.1",
+		DataSets: [][]DecodedField{{{ID: 1, Value: uint64(1<<63 + 1)}, {ID: 276, Value: true}}},
+	}
+
+	b, err := msg.JSONMarshalFormat(new(bytes.Buffer), ipfix.JSONFlat)
+	if err != nil {
+		t.Fatal("unexpected error happened:", err)
+	}
+
+	if !strings.Contains(string(b), `"DataSets":[{"1":9223372036854775809,"276":true}]`) {
+		t.Error("expected the unsigned and boolean values, got", string(b))
+	}
+}
+
+func TestJSONMarshalRepeated(t *testing.T) {
+	msg := Message{
+		AgentID: "192.0.2.1",
+		DataSets: [][]DecodedField{
+			{{ID: 70, Value: uint32(16)}, {ID: 2, Value: uint32(5)}, {ID: 70, Value: uint32(17)}},
+		},
+	}
+
+	b, err := msg.JSONMarshalFormat(new(bytes.Buffer), ipfix.JSONFlat)
+	if err != nil {
+		t.Fatal("unexpected error happened:", err)
+	}
+
+	if !strings.Contains(string(b), `"DataSets":[{"70":[16,17],"2":5}]`) {
+		t.Error("expected the repeated fields as array, got", string(b))
+	}
+
+	var v map[string]interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		t.Error("unexpected error happened:", err)
+	}
+}
@@ -0,0 +1,162 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    memcache_test.go
+//: details: netflow v9 memory cache tests
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package netflow9
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMemCacheScope(t *testing.T) {
+	var tpl TemplateRecord
+	ip := net.ParseIP("127.0.0.1")
+	mCache := NewMemCache()
+
+	scopes := []TemplateScope{
+		{Addr: ip, SrcID: 1},
+		{Addr: ip, SrcID: 2},
+		{Addr: ip.To4(), SrcID: 2, Port: 2055},
+	}
+
+	for i, scope := range scopes {
+		tpl.TemplateID = 256
+		tpl.FieldCount = uint16(i + 1)
+		mCache.insert(256, scope, tpl)
+	}
+
+	for i, scope := range scopes {
+		v, ok := mCache.retrieve(256, scope)
+		if !ok {
+			t.Fatal("expected mCache retrieve status true, got", ok)
+		}
+		if v.FieldCount != uint16(i+1) {
+			t.Error("expected field count", i+1, "got", v.FieldCount)
+		}
+	}
+}
+
+func TestMemCacheLegacyDump(t *testing.T) {
+	legacy := `{"Cache":[{"Templates":{"12345":{"Template":{"TemplateID":256,"FieldCount":1},` +
+		`"Timestamp":1,"Scope":{"Addr":"192.0.2.1","SrcID":1,"Port":0}}}}],"ShardNo":32}`
+
+	f, err := ioutil.TempFile("", "vflow-templates")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	f.WriteString(legacy)
+	f.Close()
+	defer os.Remove(f.Name())
+
+	mCache := GetCache(f.Name())
+
+	v, ok := mCache.retrieve(256, TemplateScope{Addr: net.ParseIP("192.0.2.1"), SrcID: 1})
+	if !ok || v.FieldCount != 1 {
+		t.Error("expected migrated template id#:256, got", v.TemplateID, ok)
+	}
+
+	if err := mCache.Dump(f.Name()); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	mCache = GetCache(f.Name())
+	if _, ok := mCache.retrieve(256, TemplateScope{Addr: net.ParseIP("192.0.2.1"), SrcID: 1}); !ok {
+		t.Error("expected template id#:256 after dump")
+	}
+}
+
+func TestMemCacheBaselineDump(t *testing.T) {
+	// the templates dump of the releases before the scoped templates
+	mCache := GetCache("testdata/legacy.templates")
+
+	if _, ok := mCache.retrieve(256, TemplateScope{Addr: net.ParseIP("192.0.2.3"), SrcID: 7}); ok {
+		t.Error("unexpected baseline template id#:256 for another exporter")
+	}
+
+	// the exporter claims its template by the legacy hash
+	v, ok := mCache.retrieve(256, TemplateScope{Addr: net.ParseIP("192.0.2.1"), SrcID: 7})
+	if !ok || v.FieldCount != 3 {
+		t.Fatal("expected the baseline template id#:256, got", v.TemplateID, ok)
+	}
+
+	if err := mCache.Dump("testdata/legacy.templates.tmp"); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	defer os.Remove("testdata/legacy.templates.tmp")
+
+	mCache = GetCache("testdata/legacy.templates.tmp")
+	if _, ok := mCache.retrieve(256, TemplateScope{Addr: net.ParseIP("192.0.2.1"), SrcID: 7}); !ok {
+		t.Error("expected the claimed template id#:256 after dump")
+	}
+
+	if _, ok := mCache.retrieve(256, TemplateScope{Addr: net.ParseIP("192.0.2.3"), SrcID: 7}); ok {
+		t.Error("unexpected template id#:256 for another exporter after dump")
+	}
+}
+
+func TestMemCacheLegacyDrop(t *testing.T) {
+	mCache := GetCache("testdata/legacy.templates")
+
+	if err := mCache.Dump("testdata/legacy.templates.tmp"); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	defer os.Remove("testdata/legacy.templates.tmp")
+
+	// the legacy templates that no exporter claimed aren't saved
+	mCache = GetCache("testdata/legacy.templates.tmp")
+	if _, ok := mCache.retrieve(256, TemplateScope{Addr: net.ParseIP("192.0.2.1"), SrcID: 7}); ok {
+		t.Error("unexpected unclaimed template id#:256 after dump")
+	}
+}
+
+func TestMemCacheExpire(t *testing.T) {
+	var tpl TemplateRecord
+	scope := TemplateScope{Addr: net.ParseIP("192.0.2.1"), SrcID: 1}
+	mCache := NewMemCache()
+
+	tpl.TemplateID = 256
+	mCache.insert(256, scope, tpl)
+	mCache.insert(256, scope, tpl)
+	mCache.insert(257, scope, tpl)
+
+	shard, key := mCache.getShard(257, scope)
+	data := shard.Templates[key]
+	data.Timestamp -= 3600
+	shard.Templates[key] = data
+
+	evicted := mCache.Expire(time.Minute)
+	if evicted["192.0.2.1"] != 1 {
+		t.Error("expected 1 evicted template, got", evicted)
+	}
+
+	if _, ok := mCache.retrieve(257, scope); ok {
+		t.Error("expected template id#:257 expired")
+	}
+
+	expected := MemCacheStats{InsertCount: 2, RefreshCount: 1, EvictCount: 1}
+	if stats := mCache.Stats(); stats != expected {
+		t.Errorf("expected stats %v, got %v", expected, stats)
+	}
+}
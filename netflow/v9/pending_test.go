@@ -0,0 +1,97 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    pending_test.go
+//: details: netflow v9 pending buffer tests
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package netflow9
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+var (
+	// netflow v9 packet including data flowset id 256 with one record
+	pendingData = []byte{
+		0x0, 0x9, 0x0, 0x1, 0x0, 0x0, 0x3, 0xe8, 0x5c, 0x0, 0x0, 0x0,
+		0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0, 0x7,
+		0x1, 0x0, 0x0, 0xc, 0x0, 0x0, 0x5, 0xdc, 0x0, 0x50, 0x6, 0x0,
+	}
+
+	// netflow v9 packet including template flowset id 256
+	pendingTpl = []byte{
+		0x0, 0x9, 0x0, 0x1, 0x0, 0x0, 0x3, 0xe9, 0x5c, 0x0, 0x0, 0x1,
+		0x0, 0x0, 0x0, 0x2, 0x0, 0x0, 0x0, 0x7,
+		0x0, 0x0, 0x0, 0x14, 0x1, 0x0, 0x0, 0x3, 0x0, 0x1, 0x0, 0x4,
+		0x0, 0x7, 0x0, 0x2, 0x0, 0x4, 0x0, 0x1,
+	}
+)
+
+func TestDecodePending(t *testing.T) {
+	var (
+		ip      = net.ParseIP("127.0.0.1")
+		mCache  = NewMemCache()
+		pending = NewPending(time.Minute, 1024)
+	)
+
+	d := NewDecoder(ip, pendingData)
+	d.SetPending(pending)
+	m, err := d.Decode(mCache)
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	if len(m.DataSets) != 0 {
+		t.Error("expect no data sets, got", len(m.DataSets))
+	}
+
+	d = NewDecoder(ip, pendingTpl)
+	d.SetPending(pending)
+	m, err = d.Decode(mCache)
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	if len(m.Pending) != 1 {
+		t.Fatal("expect 1 pending message, got", len(m.Pending))
+	}
+
+	pm := m.Pending[0]
+	if len(pm.DataSets) != 1 || len(pm.DataSets[0]) != 3 {
+		t.Error("expect 1 data set with 3 fields, got", pm.DataSets)
+	}
+
+	if pm.Header.SeqNum != 1 {
+		t.Error("expect the buffered message header, got", pm.Header.SeqNum)
+	}
+
+	if s := pending.Stats(); s.BufferedCount != 1 || s.DecodedCount != 1 {
+		t.Error("unexpected pending stats", s)
+	}
+}
+
+func TestDecodePendingDisabled(t *testing.T) {
+	ip := net.ParseIP("127.0.0.1")
+	d := NewDecoder(ip, pendingData)
+	if _, err := d.Decode(NewMemCache()); err == nil {
+		t.Error("expect unknown template error")
+	}
+}
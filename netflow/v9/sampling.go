@@ -0,0 +1,93 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    sampling.go
+//: details: netflow v9 sampling rates from the options data
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package netflow9
+
+import (
+	"../../../vflow/ipfix"
+)
+
+// learnSampling keeps the sampling rate of an options data record,
+// the FLOW_SAMPLER_ID (48) and FLOW_SAMPLER_RANDOM_INTERVAL (50)
+// or the SAMPLING_INTERVAL (34) of the source id
+func (d *Decoder) learnSampling(srcID uint32, fields []DecodedField) {
+	if d.sampling == nil {
+		return
+	}
+
+	if id, rate := ipfix.SamplerFields(ipfixFields(fields)); rate > 0 {
+		d.sampling.Learn(ipfix.SamplerKey{Exporter: d.raddr.String(), DomainID: srcID, ID: id}, rate)
+	}
+}
+
+// annotateSampling sets the effective sampling rate of the data records,
+// it's after the packet decoded as the options data can be after them.
+// the options data records aren't sampled, their rate stays zero
+func (d *Decoder) annotateSampling(msg *Message) {
+	if d.sampling == nil {
+		return
+	}
+
+	for _, m := range append([]*Message{msg}, msg.Pending...) {
+		m.SamplingRates = make([]uint32, len(m.DataSets))
+		for i, fields := range m.DataSets {
+			if m.IsOptions(i) {
+				continue
+			}
+
+			id, rate := ipfix.SamplerFields(ipfixFields(fields))
+			key := ipfix.SamplerKey{Exporter: m.AgentID, DomainID: m.Header.SrcID, ID: id}
+			m.SamplingRates[i] = d.sampling.Rate(key, rate)
+		}
+	}
+}
+
+// IsOptions returns true if the i-th data set decoded by an options template
+func (m *Message) IsOptions(i int) bool {
+	return i < len(m.Options) && m.Options[i]
+}
+
+// Upscale scales the data records counters by their sampling rate,
+// the counters of the records with unknown sampling stay as they are
+func (m *Message) Upscale() {
+	for i, fields := range m.DataSets {
+		if i >= len(m.SamplingRates) || m.SamplingRates[i] < 2 {
+			continue
+		}
+
+		for j := range fields {
+			if v, ok := ipfix.UpscaleCounter(fields[j].ID, fields[j].Value, m.SamplingRates[i]); ok {
+				fields[j].Value = v
+			}
+		}
+	}
+}
+
+// ipfixFields returns the fields as IPFIX IANA fields
+func ipfixFields(fields []DecodedField) []ipfix.DecodedField {
+	f := make([]ipfix.DecodedField, len(fields))
+	for i := range fields {
+		f[i] = ipfix.DecodedField{ID: fields[i].ID, Value: fields[i].Value}
+	}
+
+	return f
+}
@@ -0,0 +1,104 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    sampling_test.go
+//: details: exporters sampling table from the options data
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package netflow9
+
+import (
+	"net"
+	"testing"
+
+	"../../../vflow/ipfix"
+)
+
+// netflow v9 packet including the sampler options template 257, the data
+// template 256, a data record of the sampler 2 and the sampler options data
+var samplingData = []byte{
+	0x0, 0x9, 0x0, 0x4, 0x0, 0x0, 0x27, 0x10, 0x59, 0xb, 0x1f, 0xbd,
+	0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x0, 0x7,
+	0x0, 0x1, 0x0, 0x18, 0x1, 0x1, 0x0, 0x4, 0x0, 0x8, 0x0, 0x1, 0x0, 0x4,
+	0x0, 0x30, 0x0, 0x1, 0x0, 0x32, 0x0, 0x4, 0x0, 0x0,
+	0x0, 0x0, 0x0, 0x14, 0x1, 0x0, 0x0, 0x3, 0x0, 0x8, 0x0, 0x4,
+	0x0, 0x1, 0x0, 0x4, 0x0, 0x30, 0x0, 0x1,
+	0x1, 0x0, 0x0, 0x10, 0xa, 0x0, 0x0, 0x1, 0x0, 0x0, 0x5, 0xdc, 0x2, 0x0, 0x0, 0x0,
+	0x1, 0x1, 0x0, 0x10, 0x0, 0x0, 0x0, 0x0, 0x2, 0x0, 0x0, 0x3, 0xe8, 0x0, 0x0, 0x0,
+}
+
+func TestSampling(t *testing.T) {
+	var (
+		ip  = net.ParseIP("192.0.2.1")
+		mem = NewMemCache()
+		s   = ipfix.NewSampling()
+	)
+
+	d := NewDecoder(ip, samplingData)
+	d.SetSampling(s)
+	m, err := d.Decode(mem)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if len(m.DataSets) != 2 || len(m.SamplingRates) != 2 {
+		t.Fatal("expect 2 data records with sampling rates, got", m.DataSets, m.SamplingRates)
+	}
+
+	// the options data is after the data record
+	if m.SamplingRates[0] != 1000 {
+		t.Error("expect the sampler rate 1000, got", m.SamplingRates[0])
+	}
+
+	if r := s.Rates()[ipfix.SamplerKey{Exporter: "192.0.2.1", DomainID: 7, ID: 2}]; r != 1000 {
+		t.Error("expect the learned sampler rate 1000, got", r)
+	}
+
+	// the sampler options data record isn't sampled
+	if !m.IsOptions(1) || m.SamplingRates[1] != 0 {
+		t.Error("expect the options data without sampling rate, got", m.SamplingRates[1])
+	}
+
+	m.Upscale()
+
+	if m.DataSets[0][1].Value != uint64(1500000) {
+		t.Error("expect IN_BYTES upscaled, got", m.DataSets[0][1].Value)
+	}
+}
+
+func TestSamplingOptions(t *testing.T) {
+	s := ipfix.NewSampling()
+	s.Learn(ipfix.SamplerKey{Exporter: "192.0.2.1", DomainID: 7}, 1000)
+
+	d := NewDecoder(net.ParseIP("192.0.2.1"), nil)
+	d.SetSampling(s)
+
+	m := &Message{
+		AgentID:  "192.0.2.1",
+		Header:   PacketHeader{SrcID: 7},
+		DataSets: [][]DecodedField{{{ID: 2, Value: uint32(5)}, {ID: 34, Value: uint32(1000)}}},
+		Options:  []bool{true},
+	}
+
+	d.annotateSampling(m)
+	m.Upscale()
+
+	if m.SamplingRates[0] != 0 || m.DataSets[0][0].Value != uint32(5) {
+		t.Error("expect the options data record as it is, got", m.SamplingRates, m.DataSets[0])
+	}
+}
@@ -26,9 +26,14 @@ import "errors"
 
 // The header protocol describes the format of the sampled header
 const (
-	headerProtocolEthernet uint32 = 1
-	headerProtocolIPv4     uint32 = 11
-	headerProtocolIPv6     uint32 = 12
+	// HeaderProtocolEthernet is ISO 8802-3 ethernet header
+	HeaderProtocolEthernet uint32 = 1
+
+	// HeaderProtocolIPv4 is IPv4 header without layer two
+	HeaderProtocolIPv4 uint32 = 11
+
+	// HeaderProtocolIPv6 is IPv6 header without layer two
+	HeaderProtocolIPv6 uint32 = 12
 )
 
 // Packet represents layer 2,3,4 available info
@@ -58,15 +63,15 @@ func (p *Packet) Decoder(data []byte, protocol uint32) (*Packet, error) {
 	p.data = data
 
 	switch protocol {
-	case headerProtocolEthernet:
+	case HeaderProtocolEthernet:
 		err = p.decodeEthernetHeader()
 		return p, err
-	case headerProtocolIPv4:
+	case HeaderProtocolIPv4:
 		err = p.decodeIPv4Header()
 		if err != nil {
 			return p, err
 		}
-	case headerProtocolIPv6:
+	case HeaderProtocolIPv6:
 		err = p.decodeIPv6Header()
 		if err != nil {
 			return p, err
@@ -83,6 +88,11 @@ func (p *Packet) Decoder(data []byte, protocol uint32) (*Packet, error) {
 	return p, nil
 }
 
+// Payload returns the remaining data after the decoded layers
+func (p *Packet) Payload() []byte {
+	return p.data
+}
+
 func (p *Packet) decodeEthernetHeader() error {
 	var (
 		err error
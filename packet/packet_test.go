@@ -39,7 +39,7 @@ func TestDecodeEthernetIPv4TCP(t *testing.T) {
 		0x2f, 0x5e, 0x3e, 0x15, 0xcf, 0xf5, 0x62,
 	}
 	p := NewPacket()
-	_, err := p.Decoder(b, headerProtocolEthernet)
+	_, err := p.Decoder(b, HeaderProtocolEthernet)
 	if err != nil {
 		t.Error("unexpected error", err)
 	}
@@ -61,6 +61,6 @@ func BenchmarkDecodeEthernetIPv4TCP(b *testing.B) {
 	}
 	for i := 0; i < b.N; i++ {
 		p := NewPacket()
-		p.Decoder(data, headerProtocolEthernet)
+		p.Decoder(data, HeaderProtocolEthernet)
 	}
 }
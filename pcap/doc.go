@@ -0,0 +1,2 @@
+// Package pcap reads the classic pcap and the pcapng capture files
+package pcap
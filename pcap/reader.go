@@ -0,0 +1,382 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    reader.go
+//: details: pcap and pcapng capture file reader
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package pcap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+// The link layer header types, http://www.tcpdump.org/linktypes.html
+const (
+	// LinkTypeNull is BSD loopback encapsulation
+	LinkTypeNull uint32 = 0
+
+	// LinkTypeEthernet is IEEE 802.3 Ethernet
+	LinkTypeEthernet uint32 = 1
+
+	// LinkTypeRaw is raw IP; the packet begins with an IPv4 or IPv6 header
+	LinkTypeRaw uint32 = 101
+
+	// LinkTypeLinuxSLL is Linux "cooked" capture encapsulation
+	LinkTypeLinuxSLL uint32 = 113
+
+	// LinkTypeIPv4 is raw IPv4
+	LinkTypeIPv4 uint32 = 228
+
+	// LinkTypeIPv6 is raw IPv6
+	LinkTypeIPv6 uint32 = 229
+)
+
+const (
+	magicMicroseconds = 0xa1b2c3d4
+	magicNanoseconds  = 0xa1b23c4d
+	magicByteOrder    = 0x1a2b3c4d
+
+	blockTypeSHB = 0x0a0d0d0a
+	blockTypeIDB = 0x00000001
+	blockTypePB  = 0x00000002
+	blockTypeSPB = 0x00000003
+	blockTypeEPB = 0x00000006
+
+	optionEndOfOpt  = 0
+	optionTSResol   = 9
+	maxBlockLength  = 16 * 1024 * 1024
+	fileHeaderLen   = 24
+	recordHeaderLen = 16
+)
+
+// Packet represents a captured packet
+type Packet struct {
+	Timestamp time.Time
+	LinkType  uint32
+	Data      []byte
+}
+
+// Reader represents a capture file reader
+type Reader struct {
+	r        *bufio.Reader
+	order    binary.ByteOrder
+	ng       bool
+	linkType uint32
+	unit     time.Duration
+	ifaces   []iface
+}
+
+type iface struct {
+	linkType uint32
+	tsUnits  uint64
+}
+
+var (
+	errUnknownFormat = errors.New("unknown capture file format")
+	errBlockLength   = errors.New("invalid pcapng block length")
+	errRecordLength  = errors.New("invalid pcap record length")
+	errInterfaceID   = errors.New("invalid pcapng interface id")
+)
+
+// NewReader constructs a reader and consumes the file header,
+// the capture format is detected by the magic number
+func NewReader(r io.Reader) (*Reader, error) {
+	rd := &Reader{r: bufio.NewReader(r)}
+
+	magic, err := rd.r.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+
+	if binary.BigEndian.Uint32(magic) == blockTypeSHB {
+		rd.ng = true
+		if err := rd.readSHB(); err != nil {
+			return nil, err
+		}
+
+		return rd, nil
+	}
+
+	if err := rd.readFileHeader(); err != nil {
+		return nil, err
+	}
+
+	return rd, nil
+}
+
+// Next returns the next packet in capture order,
+// it returns io.EOF at the end of the file
+func (r *Reader) Next() (*Packet, error) {
+	if r.ng {
+		return r.nextBlock()
+	}
+
+	return r.nextRecord()
+}
+
+func (r *Reader) readFileHeader() error {
+	b := make([]byte, fileHeaderLen)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		return err
+	}
+
+	switch {
+	case binary.LittleEndian.Uint32(b) == magicMicroseconds:
+		r.order, r.unit = binary.LittleEndian, time.Microsecond
+	case binary.BigEndian.Uint32(b) == magicMicroseconds:
+		r.order, r.unit = binary.BigEndian, time.Microsecond
+	case binary.LittleEndian.Uint32(b) == magicNanoseconds:
+		r.order, r.unit = binary.LittleEndian, time.Nanosecond
+	case binary.BigEndian.Uint32(b) == magicNanoseconds:
+		r.order, r.unit = binary.BigEndian, time.Nanosecond
+	default:
+		return errUnknownFormat
+	}
+
+	r.linkType = r.order.Uint32(b[20:24]) & 0x0fffffff
+
+	return nil
+}
+
+func (r *Reader) nextRecord() (*Packet, error) {
+	hdr := make([]byte, recordHeaderLen)
+	if _, err := io.ReadFull(r.r, hdr); err != nil {
+		return nil, err
+	}
+
+	sec := r.order.Uint32(hdr[0:4])
+	frac := r.order.Uint32(hdr[4:8])
+	capLen := r.order.Uint32(hdr[8:12])
+
+	if capLen > maxBlockLength {
+		return nil, errRecordLength
+	}
+
+	data := make([]byte, capLen)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return &Packet{
+		Timestamp: time.Unix(int64(sec), int64(frac)*int64(r.unit)),
+		LinkType:  r.linkType,
+		Data:      data,
+	}, nil
+}
+
+func (r *Reader) nextBlock() (*Packet, error) {
+	for {
+		blockType, body, err := r.readBlock()
+		if err != nil {
+			return nil, err
+		}
+
+		switch blockType {
+		case blockTypeSHB:
+			// a new section resets the byte order and the interfaces
+			if err := r.parseSHB(body); err != nil {
+				return nil, err
+			}
+		case blockTypeIDB:
+			r.parseIDB(body)
+		case blockTypeEPB:
+			return r.parseEPB(body)
+		case blockTypePB:
+			return r.parsePB(body)
+		case blockTypeSPB:
+			return r.parseSPB(body)
+		}
+	}
+}
+
+func (r *Reader) readSHB() error {
+	blockType, body, err := r.readBlock()
+	if err != nil {
+		return err
+	}
+
+	if blockType != blockTypeSHB {
+		return errUnknownFormat
+	}
+
+	return r.parseSHB(body)
+}
+
+// readBlock returns the block type and the block body without
+// the trailing total length, the byte order of a section header
+// block is resolved before its length is read
+func (r *Reader) readBlock() (uint32, []byte, error) {
+	hdr := make([]byte, 8)
+	if _, err := io.ReadFull(r.r, hdr); err != nil {
+		return 0, nil, err
+	}
+
+	order := r.order
+	if binary.BigEndian.Uint32(hdr[0:4]) == blockTypeSHB {
+		bom, err := r.r.Peek(4)
+		if err != nil {
+			return 0, nil, io.ErrUnexpectedEOF
+		}
+
+		switch {
+		case binary.BigEndian.Uint32(bom) == magicByteOrder:
+			order = binary.BigEndian
+		case binary.LittleEndian.Uint32(bom) == magicByteOrder:
+			order = binary.LittleEndian
+		default:
+			return 0, nil, errUnknownFormat
+		}
+	}
+
+	blockType := order.Uint32(hdr[0:4])
+	length := order.Uint32(hdr[4:8])
+	if length < 12 || length%4 != 0 || length > maxBlockLength {
+		return 0, nil, errBlockLength
+	}
+
+	body := make([]byte, length-8)
+	if _, err := io.ReadFull(r.r, body); err != nil {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+
+	return blockType, body[:len(body)-4], nil
+}
+
+func (r *Reader) parseSHB(b []byte) error {
+	if len(b) < 16 {
+		return errBlockLength
+	}
+
+	if binary.BigEndian.Uint32(b[0:4]) == magicByteOrder {
+		r.order = binary.BigEndian
+	} else {
+		r.order = binary.LittleEndian
+	}
+
+	r.ifaces = r.ifaces[:0]
+
+	return nil
+}
+
+func (r *Reader) parseIDB(b []byte) {
+	if len(b) < 8 {
+		return
+	}
+
+	i := iface{
+		linkType: uint32(r.order.Uint16(b[0:2])),
+		tsUnits:  1e6,
+	}
+
+	// options: code(2) length(2) value padded to 32 bits
+	opts := b[8:]
+	for len(opts) >= 4 {
+		code := r.order.Uint16(opts[0:2])
+		length := int(r.order.Uint16(opts[2:4]))
+		if code == optionEndOfOpt || len(opts) < 4+length {
+			break
+		}
+
+		if code == optionTSResol && length == 1 {
+			tsresol := opts[4]
+			if tsresol&0x80 != 0 {
+				i.tsUnits = 1 << (tsresol & 0x7f)
+			} else {
+				i.tsUnits = 1
+				for n := uint8(0); n < tsresol; n++ {
+					i.tsUnits *= 10
+				}
+			}
+		}
+
+		opts = opts[4+(length+3)&^3:]
+	}
+
+	r.ifaces = append(r.ifaces, i)
+}
+
+func (r *Reader) parseEPB(b []byte) (*Packet, error) {
+	if len(b) < 20 {
+		return nil, errBlockLength
+	}
+
+	id := r.order.Uint32(b[0:4])
+	ts := uint64(r.order.Uint32(b[4:8]))<<32 | uint64(r.order.Uint32(b[8:12]))
+	capLen := r.order.Uint32(b[12:16])
+
+	return r.packet(id, ts, capLen, b[20:])
+}
+
+func (r *Reader) parsePB(b []byte) (*Packet, error) {
+	if len(b) < 20 {
+		return nil, errBlockLength
+	}
+
+	id := uint32(r.order.Uint16(b[0:2]))
+	ts := uint64(r.order.Uint32(b[4:8]))<<32 | uint64(r.order.Uint32(b[8:12]))
+	capLen := r.order.Uint32(b[12:16])
+
+	return r.packet(id, ts, capLen, b[20:])
+}
+
+func (r *Reader) parseSPB(b []byte) (*Packet, error) {
+	if len(b) < 4 {
+		return nil, errBlockLength
+	}
+
+	// the simple packet block doesn't have the captured length
+	// and the timestamp, it's always from the first interface
+	capLen := r.order.Uint32(b[0:4])
+	if int(capLen) > len(b)-4 {
+		capLen = uint32(len(b) - 4)
+	}
+
+	if len(r.ifaces) < 1 {
+		return nil, errInterfaceID
+	}
+
+	return &Packet{
+		LinkType: r.ifaces[0].linkType,
+		Data:     b[4 : 4+capLen],
+	}, nil
+}
+
+func (r *Reader) packet(id uint32, ts uint64, capLen uint32, data []byte) (*Packet, error) {
+	if int(id) >= len(r.ifaces) {
+		return nil, errInterfaceID
+	}
+
+	if int(capLen) > len(data) {
+		return nil, errBlockLength
+	}
+
+	i := r.ifaces[id]
+	sec := ts / i.tsUnits
+	nsec := (ts % i.tsUnits) * 1e9 / i.tsUnits
+
+	return &Packet{
+		Timestamp: time.Unix(int64(sec), int64(nsec)),
+		LinkType:  i.linkType,
+		Data:      data[:capLen],
+	}, nil
+}
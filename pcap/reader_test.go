@@ -0,0 +1,128 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    reader_test.go
+//: details: pcap and pcapng reader tests
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package pcap
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+// classic pcap, little endian, microseconds, ethernet with one 4 bytes packet
+var pcapFile = []byte{
+	0xd4, 0xc3, 0xb2, 0xa1, 0x02, 0x00, 0x04, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00,
+	// record header
+	0x10, 0x27, 0x00, 0x00, 0x40, 0x42, 0x0f, 0x00,
+	0x04, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00,
+	0xde, 0xad, 0xbe, 0xef,
+}
+
+// pcapng, big endian, SHB + IDB (raw ip, tsresol 9) + EPB with 4 bytes packet
+var pcapngFile = []byte{
+	// section header block
+	0x0a, 0x0d, 0x0d, 0x0a, 0x00, 0x00, 0x00, 0x1c,
+	0x1a, 0x2b, 0x3c, 0x4d, 0x00, 0x01, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0x00, 0x00, 0x00, 0x1c,
+	// interface description block
+	0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x20,
+	0x00, 0x65, 0x00, 0x00, 0x00, 0x00, 0xff, 0xff,
+	0x00, 0x09, 0x00, 0x01, 0x09, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x20,
+	// custom block should be skipped
+	0x00, 0x00, 0x0b, 0xad, 0x00, 0x00, 0x00, 0x10,
+	0x01, 0x02, 0x03, 0x04, 0x00, 0x00, 0x00, 0x10,
+	// enhanced packet block
+	0x00, 0x00, 0x00, 0x06, 0x00, 0x00, 0x00, 0x24,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02,
+	0x54, 0x0b, 0xe4, 0x05, 0x00, 0x00, 0x00, 0x04,
+	0x00, 0x00, 0x00, 0x04, 0xca, 0xfe, 0xba, 0xbe,
+	0x00, 0x00, 0x00, 0x24,
+}
+
+func TestReaderPcap(t *testing.T) {
+	r, err := NewReader(bytes.NewReader(pcapFile))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	p, err := r.Next()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if p.LinkType != LinkTypeEthernet {
+		t.Error("expect link type 1, got", p.LinkType)
+	}
+
+	if !p.Timestamp.Equal(time.Unix(10000, 1e9)) {
+		t.Error("unexpected timestamp", p.Timestamp)
+	}
+
+	if !bytes.Equal(p.Data, []byte{0xde, 0xad, 0xbe, 0xef}) {
+		t.Error("unexpected data", p.Data)
+	}
+
+	if _, err = r.Next(); err != io.EOF {
+		t.Error("expect io.EOF, got", err)
+	}
+}
+
+func TestReaderPcapng(t *testing.T) {
+	r, err := NewReader(bytes.NewReader(pcapngFile))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	p, err := r.Next()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if p.LinkType != LinkTypeRaw {
+		t.Error("expect link type 101, got", p.LinkType)
+	}
+
+	// 0x2540be405 nanoseconds
+	if !p.Timestamp.Equal(time.Unix(10, 5)) {
+		t.Error("unexpected timestamp", p.Timestamp)
+	}
+
+	if !bytes.Equal(p.Data, []byte{0xca, 0xfe, 0xba, 0xbe}) {
+		t.Error("unexpected data", p.Data)
+	}
+
+	if _, err = r.Next(); err != io.EOF {
+		t.Error("expect io.EOF, got", err)
+	}
+}
+
+func TestReaderUnknownFormat(t *testing.T) {
+	_, err := NewReader(bytes.NewReader(make([]byte, 24)))
+	if err != errUnknownFormat {
+		t.Error("expect unknown format error, got", err)
+	}
+}
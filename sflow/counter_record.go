@@ -0,0 +1,207 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    counter_record.go
+//: details: sFlow counter records as messages and their per second rates
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package sflow
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CounterRecord represents a counter record of a counter sample with
+// its agent and data source, it's a message by itself
+type CounterRecord struct {
+	AgentID      string // agent IP address
+	AgentSubID   uint32 // agent sub id
+	SysUpTime    uint32 // agent uptime in milliseconds
+	ColTime      int64  // collected time
+	SequenceNo   uint32 // counter sample sequence number
+	SourceIDType uint32 // data source type, 0 is ifIndex
+	SourceIDIdx  uint32 // data source index
+	Type         string // counter record e.g. GenInt
+	Counters     Record
+	Rates        map[string]float64 `json:",omitempty"`
+}
+
+// CounterRates represents the previous counter records of the data
+// sources to compute the per second rates of the consecutive records
+type CounterRates struct {
+	sources map[counterKey]counterState
+	sync.Mutex
+}
+
+type counterKey struct {
+	agent        string
+	agentSubID   uint32
+	sourceIDType uint32
+	sourceIDIdx  uint32
+	record       string
+}
+
+type counterState struct {
+	upTime  uint32
+	colTime int64
+	values  map[string]counterValue
+}
+
+type counterValue struct {
+	value uint64
+	bits  int
+}
+
+// CounterRecords returns the counter records of the datagram's
+// counter samples, a record per data source and counter record
+func (d *SFDatagram) CounterRecords() []CounterRecord {
+	var records []CounterRecord
+
+	for _, c := range d.Counters {
+		var (
+			seq, idType, idx uint32
+			rs               map[string]Record
+		)
+
+		switch cs := c.(type) {
+		case *CounterSample:
+			seq, idType, idx, rs = cs.SequenceNo, uint32(cs.SourceIDType), cs.SourceIDIdx, cs.Records
+		case *ExpandedCounterSample:
+			seq, idType, idx, rs = cs.SequenceNo, cs.SourceIDType, cs.SourceIDIdx, cs.Records
+		default:
+			continue
+		}
+
+		names := make([]string, 0, len(rs))
+		for name := range rs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			records = append(records, CounterRecord{
+				AgentID:      d.IPAddress.String(),
+				AgentSubID:   d.AgentSubID,
+				SysUpTime:    d.SysUpTime,
+				ColTime:      d.ColTime,
+				SequenceNo:   seq,
+				SourceIDType: idType,
+				SourceIDIdx:  idx,
+				Type:         name,
+				Counters:     rs[name],
+			})
+		}
+	}
+
+	return records
+}
+
+// NewCounterRates constructs the counter rates
+func NewCounterRates() *CounterRates {
+	return &CounterRates{
+		sources: make(map[counterKey]counterState),
+	}
+}
+
+// Rates sets the per second rates of the counters since the previous
+// record of the data source by the agent uptime, the first record
+// and the record after the agent restarted have no rates. the 32 bits
+// counters can wrap around once between the records.
+func (c *CounterRates) Rates(r *CounterRecord) {
+	var (
+		key = counterKey{r.AgentID, r.AgentSubID, r.SourceIDType, r.SourceIDIdx, r.Type}
+		cur = counterState{r.SysUpTime, r.ColTime, counterValues(r.Counters)}
+	)
+
+	c.Lock()
+	prev, ok := c.sources[key]
+	c.sources[key] = cur
+	c.Unlock()
+
+	if !ok || cur.upTime <= prev.upTime {
+		return
+	}
+
+	seconds := float64(cur.upTime-prev.upTime) / 1000
+	rates := make(map[string]float64, len(cur.values))
+
+	for name, v := range cur.values {
+		p, ok := prev.values[name]
+		if !ok {
+			continue
+		}
+
+		var delta uint64
+		if v.bits == 32 {
+			delta = uint64(uint32(v.value) - uint32(p.value))
+		} else if v.value >= p.value {
+			delta = v.value - p.value
+		} else {
+			continue
+		}
+
+		rates[name] = float64(delta) / seconds
+	}
+
+	if len(rates) > 0 {
+		r.Rates = rates
+	}
+}
+
+// Sweep drops the data sources that haven't had a record since the max age
+func (c *CounterRates) Sweep(maxAge time.Duration) {
+	deadline := time.Now().Add(-maxAge).Unix()
+
+	c.Lock()
+	defer c.Unlock()
+
+	for key, s := range c.sources {
+		if s.colTime < deadline {
+			delete(c.sources, key)
+		}
+	}
+}
+
+// counterValues returns the counters of the counter record, they're
+// the fields tagged as sflow:"counter", the rest are gauges or ids
+func counterValues(r Record) map[string]counterValue {
+	v := reflect.Indirect(reflect.ValueOf(r))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	values := make(map[string]counterValue, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Tag.Get("sflow") != "counter" {
+			continue
+		}
+
+		switch f := v.Field(i); f.Kind() {
+		case reflect.Uint32:
+			values[field.Name] = counterValue{f.Uint(), 32}
+		case reflect.Uint64:
+			values[field.Name] = counterValue{f.Uint(), 64}
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,136 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    counter_record_test.go
+//: details: sFlow counter records as messages and their per second rates
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package sflow
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestCounterRecords(t *testing.T) {
+	d := &SFDatagram{
+		IPAddress: net.ParseIP("192.0.2.1"),
+		SysUpTime: 10000,
+		Counters: []Counter{
+			&CounterSample{
+				SequenceNo:  5,
+				SourceIDIdx: 3,
+				Records: map[string]Record{
+					"GenInt": &GenericInterfaceCounters{Index: 3, InOctets: 1000},
+					"EthInt": &EthernetInterfaceCounters{FCSErrors: 1},
+				},
+			},
+		},
+	}
+
+	records := d.CounterRecords()
+	if len(records) != 2 {
+		t.Fatal("expect 2 counter records, got", len(records))
+	}
+
+	if records[0].Type != "EthInt" || records[1].Type != "GenInt" {
+		t.Error("expect the records sorted by type, got", records[0].Type, records[1].Type)
+	}
+
+	if records[1].AgentID != "192.0.2.1" || records[1].SourceIDIdx != 3 || records[1].SequenceNo != 5 {
+		t.Error("unexpected counter record", records[1])
+	}
+}
+
+func TestCounterRates(t *testing.T) {
+	var (
+		c    = NewCounterRates()
+		prev = CounterRecord{
+			AgentID:   "192.0.2.1",
+			SysUpTime: 10000,
+			ColTime:   time.Now().Unix(),
+			Type:      "GenInt",
+			Counters:  &GenericInterfaceCounters{Speed: 1e9, InOctets: 1000, InUnicastPackets: 0xfffffff0},
+		}
+		cur = prev
+	)
+
+	c.Rates(&prev)
+	if prev.Rates != nil {
+		t.Error("expect no rates for the first record, got", prev.Rates)
+	}
+
+	cur.SysUpTime = 20000
+	cur.Counters = &GenericInterfaceCounters{Speed: 1e9, InOctets: 21000, InUnicastPackets: 0x10}
+	c.Rates(&cur)
+
+	if cur.Rates["InOctets"] != 2000 {
+		t.Error("expect InOctets rate 2000, got", cur.Rates["InOctets"])
+	}
+
+	// the 32 bits counter wrapped around
+	if cur.Rates["InUnicastPackets"] != 3.2 {
+		t.Error("expect InUnicastPackets rate 3.2, got", cur.Rates["InUnicastPackets"])
+	}
+
+	if _, ok := cur.Rates["Speed"]; ok {
+		t.Error("expect no rate for the speed")
+	}
+
+	// the agent restarted
+	cur.SysUpTime = 1000
+	cur.Rates = nil
+	c.Rates(&cur)
+	if cur.Rates != nil {
+		t.Error("expect no rates after the agent restarted, got", cur.Rates)
+	}
+
+	c.Sweep(-time.Hour)
+	if len(c.sources) != 0 {
+		t.Error("expect the data sources swept, got", len(c.sources))
+	}
+}
+
+func TestCounterValues(t *testing.T) {
+	tests := []struct {
+		record   Record
+		counters []string
+	}{
+		{&HostCPU{Uptime: 100, CPUNum: 4, CPUUser: 10, Contexts: 20}, []string{"CPUUser", "Contexts"}},
+		{&VirtCPU{State: 1, CPUTime: 10, NrVirtCPU: 2}, []string{"CPUTime"}},
+		{&VirtNetIO{BytesIn: 10}, []string{"BytesIn", "PktsOut"}},
+		{&VirtMemory{Memory: 10}, nil},
+		{&ProcessorCounters{CPU5s: 10}, nil},
+	}
+
+	for i, test := range tests {
+		values := counterValues(test.record)
+		for _, name := range test.counters {
+			if _, ok := values[name]; !ok {
+				t.Errorf("test %d expect the counter %s, got %v", i, name, values)
+			}
+		}
+
+		for _, name := range []string{"Uptime", "CPUNum", "State", "NrVirtCPU", "Memory", "CPU5s"} {
+			if _, ok := values[name]; ok {
+				t.Errorf("test %d expect the gauge %s skipped", i, name)
+			}
+		}
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"errors"
 	"io"
 	"net"
+	"strings"
 	"time"
 )
 
@@ -39,12 +40,29 @@ const (
 
 	//DataExpandedFlowSample defines expanded flow sampling
 	DataExpandedFlowSample = 3
+
+	// DataExpandedCounterSample defines expanded counter sampling
+	DataExpandedCounterSample = 4
 )
 
 // SFDecoder represents sFlow decoder
 type SFDecoder struct {
-	reader io.ReadSeeker
-	filter []uint32 // Filter data format(s)
+	reader  io.ReadSeeker
+	filter  []uint32 // Filter data format(s)
+	skipped skippedRecords
+}
+
+// skippedRecords represents the malformed records of a sample
+// that skipped while the rest of the sample decoded
+type skippedRecords []error
+
+func (e skippedRecords) Error() string {
+	s := make([]string, len(e))
+	for i := range e {
+		s[i] = e[i].Error()
+	}
+
+	return strings.Join(s, "; ")
 }
 
 // SFDatagram represents sFlow datagram
@@ -103,6 +121,7 @@ func (d *SFDecoder) SFDecode() (*SFDatagram, error) {
 
 	datagram.Samples = []Sample{}
 	datagram.Counters = []Counter{}
+	d.skipped = nil
 
 	for i := uint32(0); i < datagram.SamplesNo; i++ {
 		sfTypeFormat, sfDataLength, err := d.getSampleInfo()
@@ -117,11 +136,11 @@ func (d *SFDecoder) SFDecode() (*SFDatagram, error) {
 
 		switch sfTypeFormat {
 		case DataFlowSample:
-			d, err := decodeFlowSample(d.reader)
-			if err != nil {
+			s, err := decodeFlowSample(d.reader)
+			if err = d.skip(err); err != nil {
 				return datagram, err
 			}
-			datagram.Samples = append(datagram.Samples, d)
+			datagram.Samples = append(datagram.Samples, s)
 		case DataCounterSample:
 			d, err := decodeFlowCounter(d.reader)
 			if err != nil {
@@ -129,11 +148,17 @@ func (d *SFDecoder) SFDecode() (*SFDatagram, error) {
 			}
 			datagram.Counters = append(datagram.Counters, d)
 		case DataExpandedFlowSample:
-			d, err := decodeExpandedFlowSample(d.reader)
+			s, err := decodeExpandedFlowSample(d.reader)
+			if err = d.skip(err); err != nil {
+				return datagram, err
+			}
+			datagram.Samples = append(datagram.Samples, s)
+		case DataExpandedCounterSample:
+			d, err := decodeExpandedFlowCounter(d.reader)
 			if err != nil {
 				return datagram, err
 			}
-			datagram.Samples = append(datagram.Samples, d)
+			datagram.Counters = append(datagram.Counters, d)
 		default:
 			d.reader.Seek(int64(sfDataLength), 1)
 		}
@@ -143,6 +168,23 @@ func (d *SFDecoder) SFDecode() (*SFDatagram, error) {
 	return datagram, nil
 }
 
+// Skipped returns the malformed records that skipped by the last
+// decode, their samples decoded without them
+func (d *SFDecoder) Skipped() []error {
+	return d.skipped
+}
+
+// skip keeps the skipped records of a sample, the other errors
+// are returned as they are
+func (d *SFDecoder) skip(err error) error {
+	if s, ok := err.(skippedRecords); ok {
+		d.skipped = append(d.skipped, s...)
+		return nil
+	}
+
+	return err
+}
+
 func (d *SFDecoder) sfHeaderDecode() (*SFDatagram, error) {
 	var (
 		datagram = &SFDatagram{}
@@ -22,6 +22,7 @@ func decodeExpandedFlowSample(r io.ReadSeeker) (*ExpandedFlowSample, error) {
 		efs         = new(ExpandedFlowSample)
 		rTypeFormat uint32
 		rTypeLength uint32
+		skipped     skippedRecords
 		err         error
 	)
 
@@ -61,10 +62,26 @@ func decodeExpandedFlowSample(r io.ReadSeeker) (*ExpandedFlowSample, error) {
 
 			efs.Records["ExtRouter"] = d
 		default:
-			r.Seek(int64(rTypeLength), 1)
+			name, d, err := decodeExtData(r, rTypeFormat, rTypeLength)
+			if e, ok := err.(recordError); ok {
+				skipped = append(skipped, e)
+				continue
+			}
+
+			if err != nil {
+				return efs, err
+			}
+
+			if name != "" {
+				efs.Records[name] = d
+			}
 		}
 	}
 
+	if len(skipped) > 0 {
+		return efs, skipped
+	}
+
 	return efs, nil
 }
 
@@ -0,0 +1,630 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    extended_data.go
+//: details: sFlow extended gateway, user, URL, MPLS, NAT and tunnel flow records
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package sflow
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+)
+
+const (
+	// SFDataExtGateway is sFlow Extended Gateway Data number
+	SFDataExtGateway = 1003
+
+	// SFDataExtUser is sFlow Extended User Data number
+	SFDataExtUser = 1004
+
+	// SFDataExtURL is sFlow Extended URL Data number
+	SFDataExtURL = 1005
+
+	// SFDataExtMPLS is sFlow Extended MPLS Data number
+	SFDataExtMPLS = 1006
+
+	// SFDataExtNAT is sFlow Extended NAT Data number
+	SFDataExtNAT = 1007
+
+	// SFDataExtMPLSTunnel is sFlow Extended MPLS Tunnel number
+	SFDataExtMPLSTunnel = 1008
+
+	// SFDataExtMPLSVC is sFlow Extended MPLS Virtual Circuit number
+	SFDataExtMPLSVC = 1009
+
+	// SFDataExtMPLSFTN is sFlow Extended MPLS FEC to NHLFE number
+	SFDataExtMPLSFTN = 1010
+
+	// SFDataExtL2TunnelEgress is sFlow Extended L2 Tunnel Egress number
+	SFDataExtL2TunnelEgress = 1021
+
+	// SFDataExtL2TunnelIngress is sFlow Extended L2 Tunnel Ingress number
+	SFDataExtL2TunnelIngress = 1022
+
+	// SFDataExtIPv4TunnelEgress is sFlow Extended IPv4 Tunnel Egress number
+	SFDataExtIPv4TunnelEgress = 1023
+
+	// SFDataExtIPv4TunnelIngress is sFlow Extended IPv4 Tunnel Ingress number
+	SFDataExtIPv4TunnelIngress = 1024
+
+	// SFDataExtIPv6TunnelEgress is sFlow Extended IPv6 Tunnel Egress number
+	SFDataExtIPv6TunnelEgress = 1025
+
+	// SFDataExtIPv6TunnelIngress is sFlow Extended IPv6 Tunnel Ingress number
+	SFDataExtIPv6TunnelIngress = 1026
+
+	// SFDataExtDecapsulateEgress is sFlow Extended Decapsulate Egress number
+	SFDataExtDecapsulateEgress = 1027
+
+	// SFDataExtDecapsulateIngress is sFlow Extended Decapsulate Ingress number
+	SFDataExtDecapsulateIngress = 1028
+
+	// SFDataExtVNIEgress is sFlow Extended VNI Egress number
+	SFDataExtVNIEgress = 1029
+
+	// SFDataExtVNIIngress is sFlow Extended VNI Ingress number
+	SFDataExtVNIIngress = 1030
+)
+
+// ExtGatewayData represents extended gateway (BGP) data
+type ExtGatewayData struct {
+	NextHop     net.IP          // BGP next hop
+	AS          uint32          // AS number of this router
+	SrcAS       uint32          // AS number of the source
+	SrcPeerAS   uint32          // AS number of the source peer
+	DstASPath   []ASPathSegment // AS path to the destination
+	Communities []uint32
+	LocalPref   uint32
+}
+
+// ASPathSegment represents a BGP AS path segment
+type ASPathSegment struct {
+	Type uint32 // 1 is AS set and 2 is AS sequence
+	AS   []uint32
+}
+
+// ExtUserData represents extended user data
+type ExtUserData struct {
+	SrcCharset uint32 // MIBenum of the source user charset
+	SrcUser    string
+	DstCharset uint32 // MIBenum of the destination user charset
+	DstUser    string
+}
+
+// ExtURLData represents extended URL data
+type ExtURLData struct {
+	Direction uint32 // 1 is source and 2 is destination address
+	URL       string
+	Host      string
+}
+
+// ExtMPLSData represents extended MPLS data
+type ExtMPLSData struct {
+	NextHop  net.IP
+	InStack  []uint32 // label stack of the received packet
+	OutStack []uint32 // label stack of the forwarded packet
+}
+
+// ExtNATData represents extended NAT data
+type ExtNATData struct {
+	SrcAddress net.IP // source address after translation
+	DstAddress net.IP // destination address after translation
+}
+
+// ExtMPLSTunnelData represents extended MPLS tunnel data
+type ExtMPLSTunnelData struct {
+	Name string // tunnel LSP name
+	ID   uint32
+	COS  uint32
+}
+
+// ExtMPLSVCData represents extended MPLS virtual circuit data
+type ExtMPLSVCData struct {
+	InstanceName string
+	ID           uint32
+	LabelCOS     uint32
+}
+
+// ExtMPLSFTNData represents extended MPLS FEC to NHLFE data
+type ExtMPLSFTNData struct {
+	Descr string
+	Mask  uint32
+}
+
+// SampledEthernet represents the ethernet header of a tunnel
+type SampledEthernet struct {
+	Length uint32 // length of MAC packet in bytes
+	SrcMAC string
+	DstMAC string
+	Type   uint32 // ethernet packet type
+}
+
+// SampledIP represents the IPv4 or IPv6 header of a tunnel
+type SampledIP struct {
+	Length   uint32 // length of IP packet in bytes
+	Protocol uint32 // IP protocol type
+	SrcIP    net.IP
+	DstIP    net.IP
+	SrcPort  uint32
+	DstPort  uint32
+	TCPFlags uint32
+	TOS      uint32 // TOS or the IPv6 priority
+}
+
+// ExtDecapsulateData represents the offset of the inner header
+// of a decapsulated tunnel in the sampled header
+type ExtDecapsulateData struct {
+	InnerHeaderOffset uint32
+}
+
+// ExtVNIData represents the virtual network identifier of a tunnel
+// e.g. VXLAN VNI or GRE key
+type ExtVNIData struct {
+	VNI uint32
+}
+
+var (
+	errAddressTypeUnknown = errors.New("the sflow address type is unknown")
+)
+
+// recordError represents a malformed record, its bytes are consumed
+// so the sample decodes on without it
+type recordError struct {
+	format uint32
+	err    error
+}
+
+func (e recordError) Error() string {
+	return fmt.Sprintf("sflow record format %d skipped: %v", e.format, e.err)
+}
+
+// decodeExtData decodes the extended flow records except the switch and
+// router data, it returns the record name and an empty name if the record
+// isn't supported
+func decodeExtData(r io.Reader, format, length uint32) (string, Record, error) {
+	var (
+		name string
+		d    Record
+		err  error
+	)
+
+	if length > maxRecordLength {
+		return "", nil, errDataLengthUnknown
+	}
+
+	// each record decodes within its own length
+	buf := make([]byte, length)
+	if err = read(r, &buf); err != nil {
+		return "", nil, err
+	}
+	rr := bytes.NewReader(buf)
+
+	switch format {
+	case SFDataExtGateway:
+		name = "ExtGateway"
+		d, err = decodeExtGatewayData(rr)
+	case SFDataExtUser:
+		name = "ExtUser"
+		d, err = decodeExtUserData(rr)
+	case SFDataExtURL:
+		name = "ExtURL"
+		d, err = decodeExtURLData(rr)
+	case SFDataExtMPLS:
+		name = "ExtMPLS"
+		d, err = decodeExtMPLSData(rr)
+	case SFDataExtNAT:
+		name = "ExtNAT"
+		d, err = decodeExtNATData(rr)
+	case SFDataExtMPLSTunnel:
+		name = "ExtMPLSTunnel"
+		d, err = decodeExtMPLSTunnelData(rr)
+	case SFDataExtMPLSVC:
+		name = "ExtMPLSVC"
+		d, err = decodeExtMPLSVCData(rr)
+	case SFDataExtMPLSFTN:
+		name = "ExtMPLSFTN"
+		d, err = decodeExtMPLSFTNData(rr)
+	case SFDataExtL2TunnelEgress:
+		name = "ExtL2TunnelEgress"
+		d, err = decodeSampledEthernet(rr)
+	case SFDataExtL2TunnelIngress:
+		name = "ExtL2TunnelIngress"
+		d, err = decodeSampledEthernet(rr)
+	case SFDataExtIPv4TunnelEgress:
+		name = "ExtIPv4TunnelEgress"
+		d, err = decodeSampledIP(rr, net.IPv4len)
+	case SFDataExtIPv4TunnelIngress:
+		name = "ExtIPv4TunnelIngress"
+		d, err = decodeSampledIP(rr, net.IPv4len)
+	case SFDataExtIPv6TunnelEgress:
+		name = "ExtIPv6TunnelEgress"
+		d, err = decodeSampledIP(rr, net.IPv6len)
+	case SFDataExtIPv6TunnelIngress:
+		name = "ExtIPv6TunnelIngress"
+		d, err = decodeSampledIP(rr, net.IPv6len)
+	case SFDataExtDecapsulateEgress:
+		name = "ExtDecapsulateEgress"
+		d, err = decodeExtDecapsulateData(rr)
+	case SFDataExtDecapsulateIngress:
+		name = "ExtDecapsulateIngress"
+		d, err = decodeExtDecapsulateData(rr)
+	case SFDataExtVNIEgress:
+		name = "ExtVNIEgress"
+		d, err = decodeExtVNIData(rr)
+	case SFDataExtVNIIngress:
+		name = "ExtVNIIngress"
+		d, err = decodeExtVNIData(rr)
+	}
+
+	if err != nil {
+		return "", nil, recordError{format, err}
+	}
+
+	return name, d, nil
+}
+
+func decodeExtGatewayData(r io.Reader) (*ExtGatewayData, error) {
+	var eg = new(ExtGatewayData)
+
+	if err := eg.unmarshal(r); err != nil {
+		return nil, err
+	}
+
+	return eg, nil
+}
+
+func (eg *ExtGatewayData) unmarshal(r io.Reader) error {
+	var (
+		segmentsNo uint32
+		err        error
+	)
+
+	if eg.NextHop, err = readAddress(r); err != nil {
+		return err
+	}
+
+	fields := []interface{}{
+		&eg.AS,
+		&eg.SrcAS,
+		&eg.SrcPeerAS,
+		&segmentsNo,
+	}
+
+	for _, field := range fields {
+		if err = read(r, field); err != nil {
+			return err
+		}
+	}
+
+	for i := uint32(0); i < segmentsNo; i++ {
+		var segment ASPathSegment
+
+		if err = read(r, &segment.Type); err != nil {
+			return err
+		}
+
+		if segment.AS, err = readUint32s(r); err != nil {
+			return err
+		}
+
+		eg.DstASPath = append(eg.DstASPath, segment)
+	}
+
+	if eg.Communities, err = readUint32s(r); err != nil {
+		return err
+	}
+
+	err = read(r, &eg.LocalPref)
+
+	return err
+}
+
+// DstAS returns the destination AS, the last AS of the AS path
+// and the router AS if the destination is local
+func (eg *ExtGatewayData) DstAS() uint32 {
+	for i := len(eg.DstASPath) - 1; i >= 0; i-- {
+		if n := len(eg.DstASPath[i].AS); n > 0 {
+			return eg.DstASPath[i].AS[n-1]
+		}
+	}
+
+	return eg.AS
+}
+
+func decodeExtUserData(r io.Reader) (*ExtUserData, error) {
+	var (
+		eu  = new(ExtUserData)
+		err error
+	)
+
+	if err = read(r, &eu.SrcCharset); err != nil {
+		return nil, err
+	}
+
+	if eu.SrcUser, err = readString(r); err != nil {
+		return nil, err
+	}
+
+	if err = read(r, &eu.DstCharset); err != nil {
+		return nil, err
+	}
+
+	if eu.DstUser, err = readString(r); err != nil {
+		return nil, err
+	}
+
+	return eu, nil
+}
+
+func decodeExtURLData(r io.Reader) (*ExtURLData, error) {
+	var (
+		eu  = new(ExtURLData)
+		err error
+	)
+
+	if err = read(r, &eu.Direction); err != nil {
+		return nil, err
+	}
+
+	if eu.URL, err = readString(r); err != nil {
+		return nil, err
+	}
+
+	// the host was added later, the older agents don't send it
+	if eu.Host, err = readString(r); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return eu, nil
+}
+
+func decodeExtMPLSData(r io.Reader) (*ExtMPLSData, error) {
+	var (
+		em  = new(ExtMPLSData)
+		err error
+	)
+
+	if em.NextHop, err = readAddress(r); err != nil {
+		return nil, err
+	}
+
+	if em.InStack, err = readUint32s(r); err != nil {
+		return nil, err
+	}
+
+	if em.OutStack, err = readUint32s(r); err != nil {
+		return nil, err
+	}
+
+	return em, nil
+}
+
+func decodeExtNATData(r io.Reader) (*ExtNATData, error) {
+	var (
+		en  = new(ExtNATData)
+		err error
+	)
+
+	if en.SrcAddress, err = readAddress(r); err != nil {
+		return nil, err
+	}
+
+	if en.DstAddress, err = readAddress(r); err != nil {
+		return nil, err
+	}
+
+	return en, nil
+}
+
+func decodeExtMPLSTunnelData(r io.Reader) (*ExtMPLSTunnelData, error) {
+	var (
+		et  = new(ExtMPLSTunnelData)
+		err error
+	)
+
+	if et.Name, err = readString(r); err != nil {
+		return nil, err
+	}
+
+	if err = read(r, &et.ID); err != nil {
+		return nil, err
+	}
+
+	if err = read(r, &et.COS); err != nil {
+		return nil, err
+	}
+
+	return et, nil
+}
+
+func decodeExtMPLSVCData(r io.Reader) (*ExtMPLSVCData, error) {
+	var (
+		ev  = new(ExtMPLSVCData)
+		err error
+	)
+
+	if ev.InstanceName, err = readString(r); err != nil {
+		return nil, err
+	}
+
+	if err = read(r, &ev.ID); err != nil {
+		return nil, err
+	}
+
+	if err = read(r, &ev.LabelCOS); err != nil {
+		return nil, err
+	}
+
+	return ev, nil
+}
+
+func decodeExtMPLSFTNData(r io.Reader) (*ExtMPLSFTNData, error) {
+	var (
+		ef  = new(ExtMPLSFTNData)
+		err error
+	)
+
+	if ef.Descr, err = readString(r); err != nil {
+		return nil, err
+	}
+
+	if err = read(r, &ef.Mask); err != nil {
+		return nil, err
+	}
+
+	return ef, nil
+}
+
+func decodeSampledEthernet(r io.Reader) (*SampledEthernet, error) {
+	var (
+		se  = new(SampledEthernet)
+		err error
+	)
+
+	if err = read(r, &se.Length); err != nil {
+		return nil, err
+	}
+
+	if se.SrcMAC, err = readMAC(r); err != nil {
+		return nil, err
+	}
+
+	if se.DstMAC, err = readMAC(r); err != nil {
+		return nil, err
+	}
+
+	if err = read(r, &se.Type); err != nil {
+		return nil, err
+	}
+
+	return se, nil
+}
+
+func decodeSampledIP(r io.Reader, ipLen int) (*SampledIP, error) {
+	var (
+		si  = new(SampledIP)
+		err error
+	)
+
+	if err = read(r, &si.Length); err != nil {
+		return nil, err
+	}
+
+	if err = read(r, &si.Protocol); err != nil {
+		return nil, err
+	}
+
+	si.SrcIP = make(net.IP, ipLen)
+	if err = read(r, si.SrcIP); err != nil {
+		return nil, err
+	}
+
+	si.DstIP = make(net.IP, ipLen)
+	if err = read(r, si.DstIP); err != nil {
+		return nil, err
+	}
+
+	fields := []interface{}{
+		&si.SrcPort,
+		&si.DstPort,
+		&si.TCPFlags,
+		&si.TOS,
+	}
+
+	for _, field := range fields {
+		if err = read(r, field); err != nil {
+			return nil, err
+		}
+	}
+
+	return si, nil
+}
+
+func decodeExtDecapsulateData(r io.Reader) (*ExtDecapsulateData, error) {
+	var ed = new(ExtDecapsulateData)
+
+	if err := read(r, ed); err != nil {
+		return nil, err
+	}
+
+	return ed, nil
+}
+
+func decodeExtVNIData(r io.Reader) (*ExtVNIData, error) {
+	var ev = new(ExtVNIData)
+
+	if err := read(r, ev); err != nil {
+		return nil, err
+	}
+
+	return ev, nil
+}
+
+// readAddress reads a sFlow address, the address type and the IPv4
+// or IPv6 address, the unknown address type has no address
+func readAddress(r io.Reader) (net.IP, error) {
+	var addrType uint32
+
+	if err := read(r, &addrType); err != nil {
+		return nil, err
+	}
+
+	var ip net.IP
+
+	switch addrType {
+	case 0:
+		return nil, nil
+	case 1:
+		ip = make(net.IP, net.IPv4len)
+	case 2:
+		ip = make(net.IP, net.IPv6len)
+	default:
+		return nil, errAddressTypeUnknown
+	}
+
+	if err := read(r, ip); err != nil {
+		return nil, err
+	}
+
+	return ip, nil
+}
+
+// readUint32s reads a XDR array of unsigned integers
+func readUint32s(r io.Reader) ([]uint32, error) {
+	var n uint32
+
+	if err := read(r, &n); err != nil {
+		return nil, err
+	}
+
+	if n > maxRecordLength/4 {
+		return nil, errDataLengthUnknown
+	}
+
+	values := make([]uint32, n)
+	if err := read(r, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
@@ -0,0 +1,149 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    extended_data_test.go
+//: details: sFlow extended flow records decoding tests
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package sflow
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecodeExtGatewayData(t *testing.T) {
+	var (
+		gateway = []interface{}{uint32(SFDataExtGateway), uint32(56),
+			uint32(1), []byte{192, 0, 2, 254}, uint32(65000), uint32(15133), uint32(174),
+			uint32(1), uint32(2), uint32(2), uint32(3356), uint32(2914),
+			uint32(2), uint32(0xfde80001), uint32(0xfde80002), uint32(100)}
+		url = []interface{}{uint32(SFDataExtURL), uint32(28),
+			uint32(2), uint32(5), []byte("/main\x00\x00\x00"), uint32(5), []byte("a.com\x00\x00\x00")}
+		sample = []interface{}{uint32(DataFlowSample), uint32(132),
+			uint32(1), []byte{0, 0, 0, 1}, uint32(1024), uint32(5000), uint32(0),
+			uint32(1), uint32(2), uint32(2)}
+	)
+
+	sample = append(sample, gateway...)
+	sample = append(sample, url...)
+
+	d := NewSFDecoder(bytes.NewReader(counterDatagram(sample)), nil)
+	datagram, err := d.SFDecode()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	fs := datagram.Samples[0].(*FlowSample)
+
+	eg, ok := fs.Records["ExtGateway"].(*ExtGatewayData)
+	if !ok {
+		t.Fatal("expect extended gateway record, got", fs.Records)
+	}
+
+	if eg.NextHop.String() != "192.0.2.254" || eg.SrcAS != 15133 || eg.SrcPeerAS != 174 || eg.LocalPref != 100 {
+		t.Error("unexpected extended gateway", eg)
+	}
+
+	if len(eg.Communities) != 2 || eg.Communities[1] != 0xfde80002 {
+		t.Error("unexpected communities", eg.Communities)
+	}
+
+	if eg.DstAS() != 2914 {
+		t.Error("expect destination AS 2914, got", eg.DstAS())
+	}
+
+	eu := fs.Records["ExtURL"].(*ExtURLData)
+	if eu.URL != "/main" || eu.Host != "a.com" || eu.Direction != 2 {
+		t.Error("unexpected extended url", eu)
+	}
+}
+
+func TestDecodeExtTunnelData(t *testing.T) {
+	var (
+		tunnel = []interface{}{uint32(SFDataExtIPv4TunnelIngress), uint32(32),
+			uint32(1450), uint32(17), []byte{10, 0, 0, 1}, []byte{10, 0, 0, 2},
+			uint32(49152), uint32(4789), uint32(0), uint32(0)}
+		vni  = []interface{}{uint32(SFDataExtVNIIngress), uint32(4), uint32(5001)}
+		mpls = []interface{}{uint32(SFDataExtMPLS), uint32(24),
+			uint32(1), []byte{10, 0, 0, 254}, uint32(1), uint32(16001), uint32(1), uint32(24001)}
+		sample = []interface{}{uint32(DataExpandedFlowSample), uint32(124),
+			uint32(1), []byte{0, 0, 0, 1}, uint32(1024), uint32(5000), uint32(0),
+			uint32(0), uint32(1), uint32(0), uint32(2), uint32(3)}
+	)
+
+	sample = append(sample, tunnel...)
+	sample = append(sample, vni...)
+	sample = append(sample, mpls...)
+
+	d := NewSFDecoder(bytes.NewReader(counterDatagram(sample)), nil)
+	datagram, err := d.SFDecode()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	fs := datagram.Samples[0].(*ExpandedFlowSample)
+
+	ip := fs.Records["ExtIPv4TunnelIngress"].(*SampledIP)
+	if ip.SrcIP.String() != "10.0.0.1" || ip.DstIP.String() != "10.0.0.2" || ip.DstPort != 4789 {
+		t.Error("unexpected tunnel ingress", ip)
+	}
+
+	if v := fs.Records["ExtVNIIngress"].(*ExtVNIData); v.VNI != 5001 {
+		t.Error("expect VNI 5001, got", v.VNI)
+	}
+
+	em := fs.Records["ExtMPLS"].(*ExtMPLSData)
+	if em.NextHop.String() != "10.0.0.254" || em.InStack[0] != 16001 || em.OutStack[0] != 24001 {
+		t.Error("unexpected extended mpls", em)
+	}
+}
+
+func TestDecodeExtDataMalformed(t *testing.T) {
+	var (
+		// extended gateway with an unknown next hop address type
+		gateway = []interface{}{uint32(SFDataExtGateway), uint32(8), uint32(9), uint32(0)}
+		url     = []interface{}{uint32(SFDataExtURL), uint32(28),
+			uint32(2), uint32(5), []byte("/main\x00\x00\x00"), uint32(5), []byte("a.com\x00\x00\x00")}
+		sample = []interface{}{uint32(DataFlowSample), uint32(84),
+			uint32(1), []byte{0, 0, 0, 1}, uint32(1024), uint32(5000), uint32(0),
+			uint32(1), uint32(2), uint32(2)}
+	)
+
+	sample = append(sample, gateway...)
+	sample = append(sample, url...)
+
+	d := NewSFDecoder(bytes.NewReader(counterDatagram(sample)), nil)
+	datagram, err := d.SFDecode()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	fs := datagram.Samples[0].(*FlowSample)
+	if _, ok := fs.Records["ExtGateway"]; ok {
+		t.Error("expect the malformed extended gateway skipped")
+	}
+
+	if _, ok := fs.Records["ExtURL"].(*ExtURLData); !ok {
+		t.Error("expect the extended url after the skipped record, got", fs.Records)
+	}
+
+	if len(d.Skipped()) != 1 {
+		t.Error("expect a skipped record, got", d.Skipped())
+	}
+}
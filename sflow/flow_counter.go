@@ -22,7 +22,11 @@
 
 package sflow
 
-import "io"
+import (
+	"bytes"
+	"io"
+	"net"
+)
 
 const (
 	// SFGenericInterfaceCounters is Generic interface counters - see RFC 2233
@@ -40,6 +44,12 @@ const (
 	// SFVLANCounters is VLAN counters
 	SFVLANCounters = 5
 
+	// SFLAGPortStats is LAG port statistics - see IEEE 802.3ad
+	SFLAGPortStats = 7
+
+	// SFSFPCounters is optical SFP/QSFP transceiver counters
+	SFSFPCounters = 10
+
 	// SFProcessorCounters is processor counters
 	SFProcessorCounters = 1001
 )
@@ -51,87 +61,87 @@ type GenericInterfaceCounters struct {
 	Speed               uint64
 	Direction           uint32
 	Status              uint32
-	InOctets            uint64
-	InUnicastPackets    uint32
-	InMulticastPackets  uint32
-	InBroadcastPackets  uint32
-	InDiscards          uint32
-	InErrors            uint32
-	InUnknownProtocols  uint32
-	OutOctets           uint64
-	OutUnicastPackets   uint32
-	OutMulticastPackets uint32
-	OutBroadcastPackets uint32
-	OutDiscards         uint32
-	OutErrors           uint32
+	InOctets            uint64 `sflow:"counter"`
+	InUnicastPackets    uint32 `sflow:"counter"`
+	InMulticastPackets  uint32 `sflow:"counter"`
+	InBroadcastPackets  uint32 `sflow:"counter"`
+	InDiscards          uint32 `sflow:"counter"`
+	InErrors            uint32 `sflow:"counter"`
+	InUnknownProtocols  uint32 `sflow:"counter"`
+	OutOctets           uint64 `sflow:"counter"`
+	OutUnicastPackets   uint32 `sflow:"counter"`
+	OutMulticastPackets uint32 `sflow:"counter"`
+	OutBroadcastPackets uint32 `sflow:"counter"`
+	OutDiscards         uint32 `sflow:"counter"`
+	OutErrors           uint32 `sflow:"counter"`
 	PromiscuousMode     uint32
 }
 
 // EthernetInterfaceCounters represents Ethernet Interface Counters RFC2358
 type EthernetInterfaceCounters struct {
-	AlignmentErrors           uint32
-	FCSErrors                 uint32
-	SingleCollisionFrames     uint32
-	MultipleCollisionFrames   uint32
-	SQETestErrors             uint32
-	DeferredTransmissions     uint32
-	LateCollisions            uint32
-	ExcessiveCollisions       uint32
-	InternalMACTransmitErrors uint32
-	CarrierSenseErrors        uint32
-	FrameTooLongs             uint32
-	InternalMACReceiveErrors  uint32
-	SymbolErrors              uint32
+	AlignmentErrors           uint32 `sflow:"counter"`
+	FCSErrors                 uint32 `sflow:"counter"`
+	SingleCollisionFrames     uint32 `sflow:"counter"`
+	MultipleCollisionFrames   uint32 `sflow:"counter"`
+	SQETestErrors             uint32 `sflow:"counter"`
+	DeferredTransmissions     uint32 `sflow:"counter"`
+	LateCollisions            uint32 `sflow:"counter"`
+	ExcessiveCollisions       uint32 `sflow:"counter"`
+	InternalMACTransmitErrors uint32 `sflow:"counter"`
+	CarrierSenseErrors        uint32 `sflow:"counter"`
+	FrameTooLongs             uint32 `sflow:"counter"`
+	InternalMACReceiveErrors  uint32 `sflow:"counter"`
+	SymbolErrors              uint32 `sflow:"counter"`
 }
 
 // TokenRingCounters represents Token Ring Counters - see RFC 1748
 type TokenRingCounters struct {
-	LineErrors         uint32
-	BurstErrors        uint32
-	ACErrors           uint32
-	AbortTransErrors   uint32
-	InternalErrors     uint32
-	LostFrameErrors    uint32
-	ReceiveCongestions uint32
-	FrameCopiedErrors  uint32
-	TokenErrors        uint32
-	SoftErrors         uint32
-	HardErrors         uint32
-	SignalLoss         uint32
-	TransmitBeacons    uint32
-	Recoverys          uint32
-	LobeWires          uint32
-	Removes            uint32
-	Singles            uint32
-	FreqErrors         uint32
+	LineErrors         uint32 `sflow:"counter"`
+	BurstErrors        uint32 `sflow:"counter"`
+	ACErrors           uint32 `sflow:"counter"`
+	AbortTransErrors   uint32 `sflow:"counter"`
+	InternalErrors     uint32 `sflow:"counter"`
+	LostFrameErrors    uint32 `sflow:"counter"`
+	ReceiveCongestions uint32 `sflow:"counter"`
+	FrameCopiedErrors  uint32 `sflow:"counter"`
+	TokenErrors        uint32 `sflow:"counter"`
+	SoftErrors         uint32 `sflow:"counter"`
+	HardErrors         uint32 `sflow:"counter"`
+	SignalLoss         uint32 `sflow:"counter"`
+	TransmitBeacons    uint32 `sflow:"counter"`
+	Recoverys          uint32 `sflow:"counter"`
+	LobeWires          uint32 `sflow:"counter"`
+	Removes            uint32 `sflow:"counter"`
+	Singles            uint32 `sflow:"counter"`
+	FreqErrors         uint32 `sflow:"counter"`
 }
 
 // VGCounters represents 100 BaseVG interface counters - see RFC 2020
 type VGCounters struct {
-	InHighPriorityFrames    uint32
-	InHighPriorityOctets    uint64
-	InNormPriorityFrames    uint32
-	InNormPriorityOctets    uint64
-	InIPMErrors             uint32
-	InOversizeFrameErrors   uint32
-	InDataErrors            uint32
-	InNullAddressedFrames   uint32
-	OutHighPriorityFrames   uint32
-	OutHighPriorityOctets   uint64
-	TransitionIntoTrainings uint32
-	HCInHighPriorityOctets  uint64
-	HCInNormPriorityOctets  uint64
-	HCOutHighPriorityOctets uint64
+	InHighPriorityFrames    uint32 `sflow:"counter"`
+	InHighPriorityOctets    uint64 `sflow:"counter"`
+	InNormPriorityFrames    uint32 `sflow:"counter"`
+	InNormPriorityOctets    uint64 `sflow:"counter"`
+	InIPMErrors             uint32 `sflow:"counter"`
+	InOversizeFrameErrors   uint32 `sflow:"counter"`
+	InDataErrors            uint32 `sflow:"counter"`
+	InNullAddressedFrames   uint32 `sflow:"counter"`
+	OutHighPriorityFrames   uint32 `sflow:"counter"`
+	OutHighPriorityOctets   uint64 `sflow:"counter"`
+	TransitionIntoTrainings uint32 `sflow:"counter"`
+	HCInHighPriorityOctets  uint64 `sflow:"counter"`
+	HCInNormPriorityOctets  uint64 `sflow:"counter"`
+	HCOutHighPriorityOctets uint64 `sflow:"counter"`
 }
 
 // VlanCounters represents VLAN Counters
 type VlanCounters struct {
 	ID               uint32
-	Octets           uint64
-	UnicastPackets   uint32
-	MulticastPackets uint32
-	BroadcastPackets uint32
-	Discards         uint32
+	Octets           uint64 `sflow:"counter"`
+	UnicastPackets   uint32 `sflow:"counter"`
+	MulticastPackets uint32 `sflow:"counter"`
+	BroadcastPackets uint32 `sflow:"counter"`
+	Discards         uint32 `sflow:"counter"`
 }
 
 // ProcessorCounters represents Processor Information
@@ -143,6 +153,48 @@ type ProcessorCounters struct {
 	FreeMemory  uint64
 }
 
+// LAGPortStats represents IEEE 802.3ad LAG port statistics
+type LAGPortStats struct {
+	ActorSystemID        string
+	PartnerOperSystemID  string
+	AttachedAggID        uint32
+	ActorAdminState      byte
+	ActorOperState       byte
+	PartnerAdminState    byte
+	PartnerOperState     byte
+	LACPDUsRx            uint32 `sflow:"counter"`
+	MarkerPDUsRx         uint32 `sflow:"counter"`
+	MarkerResponsePDUsRx uint32 `sflow:"counter"`
+	UnknownRx            uint32 `sflow:"counter"`
+	IllegalRx            uint32 `sflow:"counter"`
+	LACPDUsTx            uint32 `sflow:"counter"`
+	MarkerPDUsTx         uint32 `sflow:"counter"`
+	MarkerResponsePDUsTx uint32 `sflow:"counter"`
+}
+
+// SFPCounters represents optical SFP/QSFP transceiver counters
+type SFPCounters struct {
+	ModuleID            uint32
+	ModuleNumLanes      uint32 // total number of lanes in the module
+	ModuleSupplyVoltage uint32 // millivolts
+	ModuleTemperature   int32  // thousandths of a degree Celsius
+	Lanes               []SFPLane
+}
+
+// SFPLane represents an optical lane of SFP/QSFP transceiver
+type SFPLane struct {
+	Index         uint32
+	TxBiasCurrent uint32 // microamps
+	TxPower       uint32 // microwatts
+	TxPowerMin    uint32
+	TxPowerMax    uint32
+	TxWavelength  uint32 // nanometers
+	RxPower       uint32 // microwatts
+	RxPowerMin    uint32
+	RxPowerMax    uint32
+	RxWavelength  uint32 // nanometers
+}
+
 // CounterSample represents the periodic sampling or polling of counters associated with a Data Source
 type CounterSample struct {
 	SequenceNo   uint32
@@ -152,72 +204,162 @@ type CounterSample struct {
 	Records      map[string]Record
 }
 
+// ExpandedCounterSample represents counter sample with the expanded
+// data source, the source id type and index are 32 bits
+type ExpandedCounterSample struct {
+	SequenceNo   uint32
+	SourceIDType uint32
+	SourceIDIdx  uint32
+	RecordsNo    uint32
+	Records      map[string]Record
+}
+
+// maxRecordLength is the largest counter record that fits in a datagram
+const maxRecordLength = 65535
+
 func decodeFlowCounter(r io.ReadSeeker) (*CounterSample, error) {
 	var (
-		cs          = new(CounterSample)
-		rTypeFormat uint32
-		rTypeLength uint32
-		err         error
+		cs  = new(CounterSample)
+		err error
 	)
 
 	if err = cs.unmarshal(r); err != nil {
 		return nil, err
 	}
 
-	cs.Records = make(map[string]Record)
+	cs.Records, err = decodeCounterRecords(r, cs.RecordsNo)
 
-	for i := uint32(0); i < cs.RecordsNo; i++ {
+	return cs, err
+}
+
+func decodeExpandedFlowCounter(r io.ReadSeeker) (*ExpandedCounterSample, error) {
+	var (
+		cs  = new(ExpandedCounterSample)
+		err error
+	)
+
+	if err = cs.unmarshal(r); err != nil {
+		return nil, err
+	}
+
+	cs.Records, err = decodeCounterRecords(r, cs.RecordsNo)
+
+	return cs, err
+}
+
+// decodeCounterRecords decodes the counter records of a counter sample,
+// each record decodes within its own length so the records with newer
+// optional fields and the unknown records don't misalign the next ones
+func decodeCounterRecords(r io.Reader, n uint32) (map[string]Record, error) {
+	var (
+		records     = make(map[string]Record)
+		rTypeFormat uint32
+		rTypeLength uint32
+		d           Record
+		err         error
+	)
+
+	for i := uint32(0); i < n; i++ {
 		if err = read(r, &rTypeFormat); err != nil {
-			return nil, err
+			return records, err
 		}
 		if err = read(r, &rTypeLength); err != nil {
-			return nil, err
+			return records, err
 		}
 
-		switch rTypeFormat {
+		if rTypeLength > maxRecordLength {
+			return records, errDataLengthUnknown
+		}
+
+		buf := make([]byte, rTypeLength)
+		if err = read(r, &buf); err != nil {
+			return records, err
+		}
+		rr := bytes.NewReader(buf)
+		name := ""
 
+		switch rTypeFormat {
 		case SFGenericInterfaceCounters:
-			d, err := decodeGenericIntCounters(r)
-			if err != nil {
-				return cs, err
-			}
-			cs.Records["GenInt"] = d
+			name = "GenInt"
+			d, err = decodeGenericIntCounters(rr)
 		case SFEthernetInterfaceCounters:
-			d, err := decodeEthIntCounters(r)
-			if err != nil {
-				return cs, err
-			}
-			cs.Records["EthInt"] = d
+			name = "EthInt"
+			d, err = decodeEthIntCounters(rr)
 		case SFTokenRingInterfaceCounters:
-			d, err := decodeTokenRingCounters(r)
-			if err != nil {
-				return cs, err
-			}
-			cs.Records["TRInt"] = d
+			name = "TRInt"
+			d, err = decodeTokenRingCounters(rr)
 		case SF100BaseVGInterfaceCounters:
-			d, err := decodeVGCounters(r)
-			if err != nil {
-				return cs, err
-			}
-			cs.Records["VGInt"] = d
+			name = "VGInt"
+			d, err = decodeVGCounters(rr)
 		case SFVLANCounters:
-			d, err := decodeVlanCounters(r)
-			if err != nil {
-				return cs, err
-			}
-			cs.Records["Vlan"] = d
+			name = "Vlan"
+			d, err = decodeVlanCounters(rr)
+		case SFLAGPortStats:
+			name = "LAG"
+			d, err = decodeLAGPortStats(rr)
+		case SFSFPCounters:
+			name = "SFP"
+			d, err = decodeSFPCounters(rr)
 		case SFProcessorCounters:
-			d, err := decodedProcessorCounters(r)
-			if err != nil {
-				return cs, err
-			}
-			cs.Records["Proc"] = d
-		default:
-			r.Seek(int64(rTypeLength), 1)
+			name = "Proc"
+			d, err = decodedProcessorCounters(rr)
+		case SFHostDescr:
+			name = "HostDescr"
+			d, err = decodeHostDescr(rr)
+		case SFHostAdapters:
+			name = "HostAdapters"
+			d, err = decodeHostAdapters(rr)
+		case SFHostParent:
+			name = "HostParent"
+			d, err = decodeHostParent(rr)
+		case SFHostCPU:
+			name = "HostCPU"
+			d, err = decodeHostCPU(rr)
+		case SFHostMemory:
+			name = "HostMemory"
+			d, err = decodeHostMemory(rr)
+		case SFHostDiskIO:
+			name = "HostDiskIO"
+			d, err = decodeHostDiskIO(rr)
+		case SFHostNetIO:
+			name = "HostNetIO"
+			d, err = decodeHostNetIO(rr)
+		case SFVirtNode:
+			name = "VirtNode"
+			d, err = decodeVirtNode(rr)
+		case SFVirtCPU:
+			name = "VirtCPU"
+			d, err = decodeVirtCPU(rr)
+		case SFVirtMemory:
+			name = "VirtMemory"
+			d, err = decodeVirtMemory(rr)
+		case SFVirtDiskIO:
+			name = "VirtDiskIO"
+			d, err = decodeVirtDiskIO(rr)
+		case SFVirtNetIO:
+			name = "VirtNetIO"
+			d, err = decodeVirtNetIO(rr)
+		case SFAppOperations:
+			name = "AppOperations"
+			d, err = decodeAppOperations(rr)
+		case SFAppResources:
+			name = "AppResources"
+			d, err = decodeAppResources(rr)
+		case SFAppWorkers:
+			name = "AppWorkers"
+			d, err = decodeAppWorkers(rr)
+		}
+
+		if err != nil {
+			return records, err
+		}
+
+		if name != "" {
+			records[name] = d
 		}
 	}
 
-	return cs, nil
+	return records, nil
 }
 
 func decodeGenericIntCounters(r io.Reader) (*GenericInterfaceCounters, error) {
@@ -463,3 +605,123 @@ func (cs *CounterSample) unmarshal(r io.Reader) error {
 
 	return err
 }
+
+func decodeLAGPortStats(r io.Reader) (*LAGPortStats, error) {
+	var lag = new(LAGPortStats)
+
+	if err := lag.unmarshal(r); err != nil {
+		return nil, err
+	}
+
+	return lag, nil
+}
+
+func (lag *LAGPortStats) unmarshal(r io.Reader) error {
+	var err error
+
+	if lag.ActorSystemID, err = readMAC(r); err != nil {
+		return err
+	}
+
+	if lag.PartnerOperSystemID, err = readMAC(r); err != nil {
+		return err
+	}
+
+	fields := []interface{}{
+		&lag.AttachedAggID,
+		&lag.ActorAdminState,
+		&lag.ActorOperState,
+		&lag.PartnerAdminState,
+		&lag.PartnerOperState,
+		&lag.LACPDUsRx,
+		&lag.MarkerPDUsRx,
+		&lag.MarkerResponsePDUsRx,
+		&lag.UnknownRx,
+		&lag.IllegalRx,
+		&lag.LACPDUsTx,
+		&lag.MarkerPDUsTx,
+		&lag.MarkerResponsePDUsTx,
+	}
+
+	for _, field := range fields {
+		if err = read(r, field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeSFPCounters(r io.Reader) (*SFPCounters, error) {
+	var sfp = new(SFPCounters)
+
+	if err := sfp.unmarshal(r); err != nil {
+		return nil, err
+	}
+
+	return sfp, nil
+}
+
+func (sfp *SFPCounters) unmarshal(r io.Reader) error {
+	var (
+		lanesNo uint32
+		err     error
+	)
+
+	fields := []interface{}{
+		&sfp.ModuleID,
+		&sfp.ModuleNumLanes,
+		&sfp.ModuleSupplyVoltage,
+		&sfp.ModuleTemperature,
+		&lanesNo,
+	}
+
+	for _, field := range fields {
+		if err = read(r, field); err != nil {
+			return err
+		}
+	}
+
+	// a lane is 40 bytes, the record length bounds the lanes
+	if lanesNo > maxRecordLength/40 {
+		return errDataLengthUnknown
+	}
+
+	sfp.Lanes = make([]SFPLane, lanesNo)
+	for i := range sfp.Lanes {
+		if err = read(r, &sfp.Lanes[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cs *ExpandedCounterSample) unmarshal(r io.Reader) error {
+	var err error
+
+	fields := []interface{}{
+		&cs.SequenceNo,
+		&cs.SourceIDType,
+		&cs.SourceIDIdx,
+		&cs.RecordsNo,
+	}
+
+	for _, field := range fields {
+		if err = read(r, field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readMAC reads a mac address, it's 6 bytes padded to 8 bytes
+func readMAC(r io.Reader) (string, error) {
+	buf := make([]byte, 8)
+	if err := read(r, &buf); err != nil {
+		return "", err
+	}
+
+	return net.HardwareAddr(buf[:6]).String(), nil
+}
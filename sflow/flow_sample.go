@@ -202,6 +202,7 @@ func decodeFlowSample(r io.ReadSeeker) (*FlowSample, error) {
 		fs          = new(FlowSample)
 		rTypeFormat uint32
 		rTypeLength uint32
+		skipped     skippedRecords
 		err         error
 	)
 
@@ -241,10 +242,26 @@ func decodeFlowSample(r io.ReadSeeker) (*FlowSample, error) {
 
 			fs.Records["ExtRouter"] = d
 		default:
-			r.Seek(int64(rTypeLength), 1)
+			name, d, err := decodeExtData(r, rTypeFormat, rTypeLength)
+			if e, ok := err.(recordError); ok {
+				skipped = append(skipped, e)
+				continue
+			}
+
+			if err != nil {
+				return fs, err
+			}
+
+			if name != "" {
+				fs.Records[name] = d
+			}
 		}
 	}
 
+	if len(skipped) > 0 {
+		return fs, skipped
+	}
+
 	return fs, nil
 }
 
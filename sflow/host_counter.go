@@ -0,0 +1,550 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    host_counter.go
+//: details: sFlow host, virtual node and application counter structures
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package sflow
+
+import (
+	"fmt"
+	"io"
+)
+
+const (
+	// SFHostDescr is physical or virtual host description
+	SFHostDescr = 2000
+
+	// SFHostAdapters is physical or virtual network adapters
+	SFHostAdapters = 2001
+
+	// SFHostParent is the parent of a virtual host
+	SFHostParent = 2002
+
+	// SFHostCPU is physical server CPU counters
+	SFHostCPU = 2003
+
+	// SFHostMemory is physical server memory counters
+	SFHostMemory = 2004
+
+	// SFHostDiskIO is physical server disk I/O counters
+	SFHostDiskIO = 2005
+
+	// SFHostNetIO is physical server network I/O counters
+	SFHostNetIO = 2006
+
+	// SFVirtNode is hypervisor counters
+	SFVirtNode = 2100
+
+	// SFVirtCPU is virtual domain CPU counters
+	SFVirtCPU = 2101
+
+	// SFVirtMemory is virtual domain memory counters
+	SFVirtMemory = 2102
+
+	// SFVirtDiskIO is virtual domain disk I/O counters
+	SFVirtDiskIO = 2103
+
+	// SFVirtNetIO is virtual domain network I/O counters
+	SFVirtNetIO = 2104
+
+	// SFAppOperations is application operations counters
+	SFAppOperations = 2202
+
+	// SFAppResources is application resources counters
+	SFAppResources = 2203
+
+	// SFAppWorkers is application workers counters
+	SFAppWorkers = 2206
+)
+
+// HostDescr represents physical or virtual host description
+type HostDescr struct {
+	Hostname    string
+	UUID        string
+	MachineType uint32 // enum machine_type e.g. 3 is x86_64
+	OSName      uint32 // enum os_name e.g. 2 is linux
+	OSRelease   string
+}
+
+// HostAdapters represents the network adapters of a host
+type HostAdapters struct {
+	Adapters []HostAdapter
+}
+
+// HostAdapter represents a network adapter and its mac addresses
+type HostAdapter struct {
+	IfIndex      uint32
+	MACAddresses []string
+}
+
+// HostParent represents the container of a virtual host
+type HostParent struct {
+	ContainerType  uint32 // sFlowDataSource type
+	ContainerIndex uint32 // sFlowDataSource index
+}
+
+// HostCPU represents physical server CPU counters, the times are
+// in milliseconds and the load averages are the number of processes
+type HostCPU struct {
+	LoadOne      float32
+	LoadFive     float32
+	LoadFifteen  float32
+	ProcRun      uint32
+	ProcTotal    uint32
+	CPUNum       uint32
+	CPUSpeed     uint32 // MHz
+	Uptime       uint32 // seconds
+	CPUUser      uint32 `sflow:"counter"`
+	CPUNice      uint32 `sflow:"counter"`
+	CPUSystem    uint32 `sflow:"counter"`
+	CPUIdle      uint32 `sflow:"counter"`
+	CPUWio       uint32 `sflow:"counter"`
+	CPUIntr      uint32 `sflow:"counter"`
+	CPUSintr     uint32 `sflow:"counter"`
+	Interrupts   uint32 `sflow:"counter"`
+	Contexts     uint32 `sflow:"counter"`
+	CPUSteal     uint32 `sflow:"counter"`
+	CPUGuest     uint32 `sflow:"counter"`
+	CPUGuestNice uint32 `sflow:"counter"`
+}
+
+// HostMemory represents physical server memory counters in bytes
+type HostMemory struct {
+	MemTotal   uint64
+	MemFree    uint64
+	MemShared  uint64
+	MemBuffers uint64
+	MemCached  uint64
+	SwapTotal  uint64
+	SwapFree   uint64
+	PageIn     uint32 `sflow:"counter"`
+	PageOut    uint32 `sflow:"counter"`
+	SwapIn     uint32 `sflow:"counter"`
+	SwapOut    uint32 `sflow:"counter"`
+}
+
+// HostDiskIO represents physical server disk I/O counters
+type HostDiskIO struct {
+	DiskTotal    uint64
+	DiskFree     uint64
+	PartMaxUsed  uint32 // hundredths of a percent
+	Reads        uint32 `sflow:"counter"`
+	BytesRead    uint64 `sflow:"counter"`
+	ReadTime     uint32 `sflow:"counter"` // milliseconds
+	Writes       uint32 `sflow:"counter"`
+	BytesWritten uint64 `sflow:"counter"`
+	WriteTime    uint32 `sflow:"counter"` // milliseconds
+}
+
+// HostNetIO represents physical server network I/O counters
+type HostNetIO struct {
+	BytesIn  uint64 `sflow:"counter"`
+	PktsIn   uint32 `sflow:"counter"`
+	ErrsIn   uint32 `sflow:"counter"`
+	DropsIn  uint32 `sflow:"counter"`
+	BytesOut uint64 `sflow:"counter"`
+	PktsOut  uint32 `sflow:"counter"`
+	ErrsOut  uint32 `sflow:"counter"`
+	DropsOut uint32 `sflow:"counter"`
+}
+
+// VirtNode represents hypervisor counters
+type VirtNode struct {
+	MHz        uint32
+	CPUs       uint32
+	Memory     uint64
+	MemoryFree uint64
+	NumDomains uint32
+}
+
+// VirtCPU represents virtual domain CPU counters
+type VirtCPU struct {
+	State     uint32 // virDomainState
+	CPUTime   uint32 `sflow:"counter"` // milliseconds
+	NrVirtCPU uint32
+}
+
+// VirtMemory represents virtual domain memory counters in bytes
+type VirtMemory struct {
+	Memory    uint64
+	MaxMemory uint64
+}
+
+// VirtDiskIO represents virtual domain disk I/O counters
+type VirtDiskIO struct {
+	Capacity   uint64
+	Allocation uint64
+	Available  uint64
+	RdReq      uint32 `sflow:"counter"`
+	RdBytes    uint64 `sflow:"counter"`
+	WrReq      uint32 `sflow:"counter"`
+	WrBytes    uint64 `sflow:"counter"`
+	Errs       uint32 `sflow:"counter"`
+}
+
+// VirtNetIO represents virtual domain network I/O counters
+type VirtNetIO HostNetIO
+
+// AppOperations represents the application operations by their status
+type AppOperations struct {
+	Application    string
+	Success        uint32 `sflow:"counter"`
+	Other          uint32 `sflow:"counter"`
+	Timeout        uint32 `sflow:"counter"`
+	InternalError  uint32 `sflow:"counter"`
+	BadRequest     uint32 `sflow:"counter"`
+	Forbidden      uint32 `sflow:"counter"`
+	TooLarge       uint32 `sflow:"counter"`
+	NotImplemented uint32 `sflow:"counter"`
+	NotFound       uint32 `sflow:"counter"`
+	Unavailable    uint32 `sflow:"counter"`
+	Unauthorized   uint32 `sflow:"counter"`
+}
+
+// AppResources represents the application resources
+type AppResources struct {
+	UserTime   uint32 `sflow:"counter"` // milliseconds
+	SystemTime uint32 `sflow:"counter"` // milliseconds
+	MemUsed    uint64
+	MemMax     uint64
+	FDOpen     uint32
+	FDMax      uint32
+	ConnOpen   uint32
+	ConnMax    uint32
+}
+
+// AppWorkers represents the application workers
+type AppWorkers struct {
+	WorkersActive uint32
+	WorkersIdle   uint32
+	WorkersMax    uint32
+	ReqDelayed    uint32 `sflow:"counter"`
+	ReqDropped    uint32 `sflow:"counter"`
+}
+
+func decodeHostDescr(r io.Reader) (*HostDescr, error) {
+	var hd = new(HostDescr)
+
+	if err := hd.unmarshal(r); err != nil {
+		return nil, err
+	}
+
+	return hd, nil
+}
+
+func (hd *HostDescr) unmarshal(r io.Reader) error {
+	var err error
+
+	if hd.Hostname, err = readString(r); err != nil {
+		return err
+	}
+
+	uuid := make([]byte, 16)
+	if err = read(r, &uuid); err != nil {
+		return err
+	}
+	hd.UUID = fmt.Sprintf("%x-%x-%x-%x-%x", uuid[:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:])
+
+	if err = read(r, &hd.MachineType); err != nil {
+		return err
+	}
+
+	if err = read(r, &hd.OSName); err != nil {
+		return err
+	}
+
+	hd.OSRelease, err = readString(r)
+
+	return err
+}
+
+func decodeHostAdapters(r io.Reader) (*HostAdapters, error) {
+	var ha = new(HostAdapters)
+
+	if err := ha.unmarshal(r); err != nil {
+		return nil, err
+	}
+
+	return ha, nil
+}
+
+func (ha *HostAdapters) unmarshal(r io.Reader) error {
+	var (
+		adaptersNo uint32
+		macsNo     uint32
+		err        error
+	)
+
+	if err = read(r, &adaptersNo); err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < adaptersNo; i++ {
+		var adapter HostAdapter
+
+		if err = read(r, &adapter.IfIndex); err != nil {
+			return err
+		}
+
+		if err = read(r, &macsNo); err != nil {
+			return err
+		}
+
+		for j := uint32(0); j < macsNo; j++ {
+			mac, err := readMAC(r)
+			if err != nil {
+				return err
+			}
+			adapter.MACAddresses = append(adapter.MACAddresses, mac)
+		}
+
+		ha.Adapters = append(ha.Adapters, adapter)
+	}
+
+	return nil
+}
+
+func decodeHostParent(r io.Reader) (*HostParent, error) {
+	var hp = new(HostParent)
+
+	if err := read(r, hp); err != nil {
+		return nil, err
+	}
+
+	return hp, nil
+}
+
+func decodeHostCPU(r io.Reader) (*HostCPU, error) {
+	var hc = new(HostCPU)
+
+	if err := hc.unmarshal(r); err != nil {
+		return nil, err
+	}
+
+	return hc, nil
+}
+
+func (hc *HostCPU) unmarshal(r io.Reader) error {
+	var err error
+
+	fields := []interface{}{
+		&hc.LoadOne,
+		&hc.LoadFive,
+		&hc.LoadFifteen,
+		&hc.ProcRun,
+		&hc.ProcTotal,
+		&hc.CPUNum,
+		&hc.CPUSpeed,
+		&hc.Uptime,
+		&hc.CPUUser,
+		&hc.CPUNice,
+		&hc.CPUSystem,
+		&hc.CPUIdle,
+		&hc.CPUWio,
+		&hc.CPUIntr,
+		&hc.CPUSintr,
+		&hc.Interrupts,
+		&hc.Contexts,
+	}
+
+	for _, field := range fields {
+		if err = read(r, field); err != nil {
+			return err
+		}
+	}
+
+	// the steal and guest times were added later, the older
+	// agents don't send them
+	optional := []interface{}{
+		&hc.CPUSteal,
+		&hc.CPUGuest,
+		&hc.CPUGuestNice,
+	}
+
+	for _, field := range optional {
+		if err = read(r, field); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeHostMemory(r io.Reader) (*HostMemory, error) {
+	var hm = new(HostMemory)
+
+	if err := read(r, hm); err != nil {
+		return nil, err
+	}
+
+	return hm, nil
+}
+
+func decodeHostDiskIO(r io.Reader) (*HostDiskIO, error) {
+	var hd = new(HostDiskIO)
+
+	if err := read(r, hd); err != nil {
+		return nil, err
+	}
+
+	return hd, nil
+}
+
+func decodeHostNetIO(r io.Reader) (*HostNetIO, error) {
+	var hn = new(HostNetIO)
+
+	if err := read(r, hn); err != nil {
+		return nil, err
+	}
+
+	return hn, nil
+}
+
+func decodeVirtNode(r io.Reader) (*VirtNode, error) {
+	var vn = new(VirtNode)
+
+	if err := read(r, vn); err != nil {
+		return nil, err
+	}
+
+	return vn, nil
+}
+
+func decodeVirtCPU(r io.Reader) (*VirtCPU, error) {
+	var vc = new(VirtCPU)
+
+	if err := read(r, vc); err != nil {
+		return nil, err
+	}
+
+	return vc, nil
+}
+
+func decodeVirtMemory(r io.Reader) (*VirtMemory, error) {
+	var vm = new(VirtMemory)
+
+	if err := read(r, vm); err != nil {
+		return nil, err
+	}
+
+	return vm, nil
+}
+
+func decodeVirtDiskIO(r io.Reader) (*VirtDiskIO, error) {
+	var vd = new(VirtDiskIO)
+
+	if err := read(r, vd); err != nil {
+		return nil, err
+	}
+
+	return vd, nil
+}
+
+func decodeVirtNetIO(r io.Reader) (*VirtNetIO, error) {
+	var vn = new(VirtNetIO)
+
+	if err := read(r, vn); err != nil {
+		return nil, err
+	}
+
+	return vn, nil
+}
+
+func decodeAppOperations(r io.Reader) (*AppOperations, error) {
+	var ao = new(AppOperations)
+
+	if err := ao.unmarshal(r); err != nil {
+		return nil, err
+	}
+
+	return ao, nil
+}
+
+func (ao *AppOperations) unmarshal(r io.Reader) error {
+	var err error
+
+	if ao.Application, err = readString(r); err != nil {
+		return err
+	}
+
+	fields := []interface{}{
+		&ao.Success,
+		&ao.Other,
+		&ao.Timeout,
+		&ao.InternalError,
+		&ao.BadRequest,
+		&ao.Forbidden,
+		&ao.TooLarge,
+		&ao.NotImplemented,
+		&ao.NotFound,
+		&ao.Unavailable,
+		&ao.Unauthorized,
+	}
+
+	for _, field := range fields {
+		if err = read(r, field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeAppResources(r io.Reader) (*AppResources, error) {
+	var ar = new(AppResources)
+
+	if err := read(r, ar); err != nil {
+		return nil, err
+	}
+
+	return ar, nil
+}
+
+func decodeAppWorkers(r io.Reader) (*AppWorkers, error) {
+	var aw = new(AppWorkers)
+
+	if err := read(r, aw); err != nil {
+		return nil, err
+	}
+
+	return aw, nil
+}
+
+// readString reads a XDR string, the length and the bytes padded to 4 bytes
+func readString(r io.Reader) (string, error) {
+	var n uint32
+
+	if err := read(r, &n); err != nil {
+		return "", err
+	}
+
+	if n > maxRecordLength {
+		return "", errDataLengthUnknown
+	}
+
+	buf := make([]byte, (n+3)&^3)
+	if err := read(r, &buf); err != nil {
+		return "", err
+	}
+
+	return string(buf[:n]), nil
+}
@@ -0,0 +1,133 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    host_counter_test.go
+//: details: sFlow host, LAG and expanded counter samples decoding tests
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package sflow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func counterDatagram(samples ...[]interface{}) []byte {
+	b := new(bytes.Buffer)
+
+	header := []interface{}{
+		uint32(5), uint32(1), []byte{192, 0, 2, 1},
+		uint32(0), uint32(1), uint32(10000), uint32(len(samples)),
+	}
+
+	for _, v := range header {
+		binary.Write(b, binary.BigEndian, v)
+	}
+
+	for _, sample := range samples {
+		for _, v := range sample {
+			binary.Write(b, binary.BigEndian, v)
+		}
+	}
+
+	return b.Bytes()
+}
+
+func TestDecodeHostCounters(t *testing.T) {
+	var (
+		// host_cpu without the optional steal and guest times
+		hostCPU = []interface{}{uint32(SFHostCPU), uint32(68),
+			float32(0.5), float32(0.25), float32(0.125), uint32(2), uint32(300),
+			uint32(8), uint32(2400), uint32(86400), uint32(1000), uint32(0),
+			uint32(500), uint32(9000), uint32(0), uint32(0), uint32(0),
+			uint32(123), uint32(456)}
+		hostDescr = []interface{}{uint32(SFHostDescr), uint32(40),
+			uint32(4), []byte("web1"),
+			[]byte{0x4c, 0x4c, 0x45, 0x44, 0x00, 0x4a, 0x10, 0x80, 0x80, 0x4c, 0xb2, 0xc0, 0x4f, 0x4d, 0x47, 0x31},
+			uint32(3), uint32(2), uint32(4), []byte("4.19")}
+		unknown = []interface{}{uint32(2207), uint32(4), uint32(1)}
+		sample  = []interface{}{uint32(DataCounterSample), uint32(148),
+			uint32(7), []byte{2, 0, 0, 1}, uint32(3)}
+	)
+
+	sample = append(sample, hostCPU...)
+	sample = append(sample, unknown...)
+	sample = append(sample, hostDescr...)
+
+	d := NewSFDecoder(bytes.NewReader(counterDatagram(sample)), nil)
+	datagram, err := d.SFDecode()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cs := datagram.Counters[0].(*CounterSample)
+	if cs.SourceIDType != 2 || cs.SourceIDIdx != 1 || len(cs.Records) != 2 {
+		t.Fatal("unexpected counter sample", cs)
+	}
+
+	cpu := cs.Records["HostCPU"].(*HostCPU)
+	if cpu.LoadOne != 0.5 || cpu.CPUNum != 8 || cpu.Contexts != 456 || cpu.CPUSteal != 0 {
+		t.Error("unexpected host cpu", cpu)
+	}
+
+	descr := cs.Records["HostDescr"].(*HostDescr)
+	if descr.Hostname != "web1" || descr.OSRelease != "4.19" || descr.MachineType != 3 {
+		t.Error("unexpected host description", descr)
+	}
+
+	if descr.UUID != "4c4c4544-004a-1080-804c-b2c04f4d4731" {
+		t.Error("expect uuid 4c4c4544-004a-1080-804c-b2c04f4d4731, got", descr.UUID)
+	}
+}
+
+func TestDecodeExpandedCounterSample(t *testing.T) {
+	var (
+		lag = []interface{}{uint32(SFLAGPortStats), uint32(56),
+			[]byte{0, 0x1b, 0x21, 0xa, 0xb, 0xc, 0, 0},
+			[]byte{0, 0x1b, 0x21, 0xa, 0xb, 0xd, 0, 0},
+			uint32(100), []byte{0x3d, 0x3d, 0x3d, 0x3d},
+			uint32(10), uint32(0), uint32(0), uint32(0), uint32(0),
+			uint32(11), uint32(0), uint32(0)}
+		sample = []interface{}{uint32(DataExpandedCounterSample), uint32(80),
+			uint32(7), uint32(3), uint32(0x1000001), uint32(1)}
+	)
+
+	sample = append(sample, lag...)
+
+	d := NewSFDecoder(bytes.NewReader(counterDatagram(sample)), nil)
+	datagram, err := d.SFDecode()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cs := datagram.Counters[0].(*ExpandedCounterSample)
+	if cs.SourceIDType != 3 || cs.SourceIDIdx != 0x1000001 {
+		t.Error("unexpected expanded counter sample", cs)
+	}
+
+	l := cs.Records["LAG"].(*LAGPortStats)
+	if l.ActorSystemID != "00:1b:21:0a:0b:0c" || l.AttachedAggID != 100 || l.LACPDUsTx != 11 || l.PartnerOperState != 0x3d {
+		t.Error("unexpected lag port stats", l)
+	}
+
+	records := datagram.CounterRecords()
+	if len(records) != 1 || records[0].SourceIDIdx != 0x1000001 || records[0].Type != "LAG" {
+		t.Error("unexpected counter records", records)
+	}
+}
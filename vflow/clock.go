@@ -0,0 +1,80 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    clock.go
+//: details: exporters clock skew against the collector time
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package main
+
+import (
+	"sync"
+)
+
+// ExporterClock represents an exporter clock against the collector
+type ExporterClock struct {
+	Protocol string
+	Skew     int64 // export time minus receive time in milliseconds
+	LastSeen int64 // receive time in unix seconds
+}
+
+var exporterClocks = struct {
+	m map[string]ExporterClock
+	sync.RWMutex
+}{m: make(map[string]ExporterClock)}
+
+// clockSkew keeps the agent's clock skew by the export time in unix
+// seconds and the receive time in unix milliseconds, it logs once the
+// skew exceeds the threshold. the skew includes the transit delay and
+// the export time resolution (one second).
+func clockSkew(proto, agent string, exportTime uint32, rcvd int64) {
+	if opts.ClockSkewThreshold < 1 || rcvd == 0 {
+		return
+	}
+
+	var (
+		skew      = int64(exportTime)*1000 - rcvd
+		threshold = int64(opts.ClockSkewThreshold) * 1000
+	)
+
+	exporterClocks.Lock()
+	prev, ok := exporterClocks.m[agent]
+	exporterClocks.m[agent] = ExporterClock{proto, skew, rcvd / 1000}
+	exporterClocks.Unlock()
+
+	if skewed(skew, threshold) && (!ok || !skewed(prev.Skew, threshold)) {
+		logger.Printf("%s exporter %s clock skew: %d ms", proto, agent, skew)
+	}
+}
+
+func skewed(skew, threshold int64) bool {
+	return skew > threshold || skew < -threshold
+}
+
+// clocks returns a copy of the exporters clock skew
+func clocks() map[string]ExporterClock {
+	exporterClocks.RLock()
+	defer exporterClocks.RUnlock()
+
+	m := make(map[string]ExporterClock, len(exporterClocks.m))
+	for agent, c := range exporterClocks.m {
+		m[agent] = c
+	}
+
+	return m
+}
@@ -0,0 +1,100 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    flow.go
+//: details: normalized flows producer
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package main
+
+import (
+	"encoding/json"
+	"path"
+	"sync"
+
+	"github.com/VerizonDigital/vflow/flow"
+	"github.com/VerizonDigital/vflow/producer"
+)
+
+var (
+	flowMQCh = make(chan []byte, 1000)
+
+	// the collectors share the normalized flows producer
+	flowOnce         sync.Once
+	flowMQErrorCount uint64
+)
+
+// flowProducer starts the normalized flows producer once
+func flowProducer() {
+	if !opts.FlowEnabled {
+		return
+	}
+
+	flowOnce.Do(func() {
+		go func() {
+			p := producer.NewProducer(opts.MQName)
+
+			p.MQConfigFile = path.Join(opts.VFlowConfigPath, opts.MQConfigFile)
+			p.MQErrorCount = &flowMQErrorCount
+			p.Logger = logger
+			p.Chan = flowMQCh
+			p.Topic = opts.FlowTopic
+
+			if err := p.Run(); err != nil {
+				logger.Fatal(err)
+			}
+		}()
+	})
+}
+
+// rawOutput returns true if the decoded messages are produced,
+// they can be replaced by the normalized flows
+func rawOutput() bool {
+	return !opts.FlowEnabled || opts.FlowRawOutput
+}
+
+// flowMessages encodes the normalized flows as JSON messages
+func flowMessages(flows []flow.Flow) [][]byte {
+	msgs := make([][]byte, 0, len(flows))
+
+	for i := range flows {
+		b, err := json.Marshal(flows[i])
+		if err != nil {
+			logger.Println(err)
+			continue
+		}
+
+		msgs = append(msgs, b)
+	}
+
+	return msgs
+}
+
+// produceFlows sends the normalized flows to the flows topic
+func produceFlows(flows []flow.Flow) {
+	for _, b := range flowMessages(flows) {
+		select {
+		case flowMQCh <- b:
+		default:
+		}
+
+		if opts.Verbose {
+			logger.Println(string(b))
+		}
+	}
+}
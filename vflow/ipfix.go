@@ -31,6 +31,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/VerizonDigital/vflow/flow"
 	"github.com/VerizonDigital/vflow/ipfix"
 	"github.com/VerizonDigital/vflow/producer"
 )
@@ -43,12 +44,19 @@ type IPFIX struct {
 	stop    bool
 	stats   IPFIXStats
 	pool    chan chan struct{}
+
+	tcpListeners []net.Listener
+	tcpConns     map[net.Conn]struct{}
+	tcpMu        sync.Mutex
+
+	fileQuit chan struct{}
 }
 
 // IPFIXUDPMsg represents IPFIX UDP data
 type IPFIXUDPMsg struct {
 	raddr *net.UDPAddr
 	body  []byte
+	rcvd  time.Time
 }
 
 // IPFIXStats represents IPFIX stats
@@ -60,6 +68,26 @@ type IPFIXStats struct {
 	DecodedCount   uint64
 	MQErrorCount   uint64
 	Workers        int32
+
+	TCPConns        int32
+	TCPSessionCount uint64
+	TCPCount        uint64
+	TLSErrorCount   uint64
+
+	FileQueue      int
+	FileErrorCount uint64
+
+	PendingBufferedCount uint64
+	PendingDecodedCount  uint64
+	PendingDroppedCount  uint64
+
+	UnknownElementCount uint64
+
+	TemplateInsertCount   uint64
+	TemplateRefreshCount  uint64
+	TemplateChangeCount   uint64
+	TemplateWithdrawCount uint64
+	TemplateEvictCount    uint64
 }
 
 var (
@@ -71,6 +99,15 @@ var (
 	// templates memory cache
 	mCache ipfix.MemCache
 
+	// data sets without template
+	ipfixPending *ipfix.Pending
+
+	// the information elements load once for IPFIX and Netflow v9
+	dictionaryOnce sync.Once
+
+	// data records JSON format
+	ipfixJSONFormat ipfix.JSONFormat
+
 	// ipfix udp payload pool
 	ipfixBuffer = &sync.Pool{
 		New: func() interface{} {
@@ -82,10 +119,12 @@ var (
 // NewIPFIX constructs IPFIX
 func NewIPFIX() *IPFIX {
 	return &IPFIX{
-		port:    opts.IPFIXPort,
-		addr:    opts.IPFIXAddr,
-		workers: opts.IPFIXWorkers,
-		pool:    make(chan chan struct{}, maxWorkers),
+		port:     opts.IPFIXPort,
+		addr:     opts.IPFIXAddr,
+		workers:  opts.IPFIXWorkers,
+		pool:     make(chan chan struct{}, maxWorkers),
+		tcpConns: make(map[net.Conn]struct{}),
+		fileQuit: make(chan struct{}),
 	}
 }
 
@@ -104,6 +143,9 @@ func (i *IPFIX) run() {
 		logger.Fatal(err)
 	}
 
+	loadDictionary()
+	ipfixJSONFormat = jsonFormat("ipfix", opts.IPFIXJSONFormat)
+
 	atomic.AddInt32(&i.stats.Workers, int32(i.workers))
 	for n := 0; n < i.workers; n++ {
 		go func() {
@@ -115,9 +157,29 @@ func (i *IPFIX) run() {
 
 	logger.Printf("ipfix is running (UDP: listening on [::]:%d workers#: %d)", i.port, i.workers)
 
-	ipfix.LoadExtElements(opts.VFlowConfigPath)
+	if opts.IPFIXTplCacheEnabled {
+		mCache = ipfix.GetCache(opts.IPFIXTplCacheFile)
+	} else {
+		mCache = ipfix.NewMemCache()
+	}
+
+	if opts.IPFIXTplCacheEnabled && opts.IPFIXTplCheckpoint > 0 {
+		go templateCheckpoint("ipfix", opts.IPFIXTplCacheFile, mCache,
+			time.Duration(opts.IPFIXTplCheckpoint)*time.Second)
+	}
+
+	if opts.IPFIXPendingTimeout > 0 {
+		timeout := time.Duration(opts.IPFIXPendingTimeout) * time.Second
+		ipfixPending = ipfix.NewPending(timeout, opts.IPFIXPendingMaxSize*1024)
+		go pendingSweeper(ipfixPending, timeout)
+	}
+
+	ipfixSampling = newSampling(opts.IPFIXSampling || opts.IPFIXSamplingUpscale)
 
-	mCache = ipfix.GetCache(opts.IPFIXTplCacheFile)
+	// the templates over TCP and TLS live as long as the session
+	if opts.IPFIXTplLifetime > 0 {
+		go templateSweeper("ipfix", mCache, time.Duration(opts.IPFIXTplLifetime)*time.Second)
+	}
 	go ipfix.RPC(mCache, &ipfix.RPCConfig{
 		Enabled: opts.IPFIXRPCEnabled,
 		Logger:  logger,
@@ -125,6 +187,20 @@ func (i *IPFIX) run() {
 
 	go mirrorIPFIXDispatcher(ipfixMCh)
 
+	if opts.IPFIXFileEnabled {
+		go i.fileSink()
+	}
+
+	if opts.IPFIXTCPEnabled {
+		go i.runTCP()
+	}
+
+	if opts.IPFIXTLSEnabled {
+		go i.runTLS()
+	}
+
+	flowProducer()
+
 	go func() {
 		p := producer.NewProducer(opts.MQName)
 
@@ -156,7 +232,10 @@ func (i *IPFIX) run() {
 			continue
 		}
 		atomic.AddUint64(&i.stats.UDPCount, 1)
-		ipfixUDPCh <- IPFIXUDPMsg{raddr, b[:n]}
+		if opts.IPFIXFileEnabled {
+			i.archive(raddr.IP.String(), b[:n])
+		}
+		ipfixUDPCh <- IPFIXUDPMsg{raddr, b[:n], time.Now()}
 	}
 
 }
@@ -171,11 +250,18 @@ func (i *IPFIX) shutdown() {
 	// stop reading from UDP listener
 	i.stop = true
 	logger.Println("stopping ipfix service gracefully ...")
+	i.shutdownTCP()
 	time.Sleep(1 * time.Second)
 
+	if opts.IPFIXFileEnabled {
+		i.fileQuit <- struct{}{}
+	}
+
 	// dump the templates to storage
-	if err := mCache.Dump(opts.IPFIXTplCacheFile); err != nil {
-		logger.Println("couldn't not dump template", err)
+	if opts.IPFIXTplCacheEnabled {
+		if err := mCache.Dump(opts.IPFIXTplCacheFile); err != nil {
+			logger.Println("couldn't not dump template", err)
+		}
 	}
 
 	// logging and close UDP channel
@@ -191,7 +277,7 @@ func (i *IPFIX) ipfixWorker(wQuit chan struct{}) {
 		buf        = new(bytes.Buffer)
 		err        error
 		ok         bool
-		b          []byte
+		msgs       [][]byte
 	)
 
 LOOP:
@@ -226,6 +312,17 @@ LOOP:
 		}
 
 		d := ipfix.NewDecoder(msg.raddr.IP, msg.body)
+		if ipfixPending != nil {
+			d.SetPending(ipfixPending)
+		}
+		if ipfixSampling != nil {
+			d.SetSampling(ipfixSampling)
+		}
+		if opts.IPFIXTplScopePort {
+			d.SetSourcePort(msg.raddr.Port)
+		}
+		d.SetStrict(opts.IPFIXStrictElements)
+		d.SetReceiveTime(msg.rcvd)
 		if decodedMsg, err = d.Decode(mCache); err != nil {
 			logger.Println(err)
 			// in case ipfix message header couldn't decode
@@ -236,20 +333,44 @@ LOOP:
 
 		atomic.AddUint64(&i.stats.DecodedCount, 1)
 
-		if len(decodedMsg.DataSets) > 0 {
-			b, err = decodedMsg.JSONMarshal(buf)
-			if err != nil {
-				logger.Println(err)
+		clockSkew("ipfix", decodedMsg.AgentID, decodedMsg.Header.ExportTime, decodedMsg.ReceiveTime)
+
+		// the buffered data sets that decoded by this message's templates
+		for _, m := range append([]*ipfix.Message{decodedMsg}, decodedMsg.Pending...) {
+			if len(m.DataSets) < 1 {
 				continue
 			}
 
-			select {
-			case ipfixMQCh <- append([]byte{}, b...):
-			default:
+			if opts.IPFIXAbsoluteTime {
+				m.AbsoluteTime()
 			}
 
-			if opts.Verbose {
-				logger.Println(string(b))
+			if opts.IPFIXSamplingUpscale {
+				m.Upscale()
+			}
+
+			if opts.FlowEnabled {
+				produceFlows(flow.FromIPFIX(m))
+			}
+
+			if !rawOutput() {
+				continue
+			}
+
+			msgs, err = ipfixMessages(buf, m)
+			if err != nil {
+				logger.Println(err)
+			}
+
+			for _, b := range msgs {
+				select {
+				case ipfixMQCh <- b:
+				default:
+				}
+
+				if opts.Verbose {
+					logger.Println(string(b))
+				}
 			}
 		}
 
@@ -257,6 +378,18 @@ LOOP:
 }
 
 func (i *IPFIX) status() *IPFIXStats {
+	var pending ipfix.PendingStats
+	if ipfixPending != nil {
+		pending = ipfixPending.Stats()
+	}
+
+	tpl := mCache.Stats()
+
+	var unknown uint64
+	for _, e := range ipfix.UnknownElements() {
+		unknown += e.Count
+	}
+
 	return &IPFIXStats{
 		UDPQueue:       len(ipfixUDPCh),
 		UDPMirrorQueue: len(ipfixMCh),
@@ -265,6 +398,147 @@ func (i *IPFIX) status() *IPFIXStats {
 		DecodedCount:   atomic.LoadUint64(&i.stats.DecodedCount),
 		MQErrorCount:   atomic.LoadUint64(&i.stats.MQErrorCount),
 		Workers:        atomic.LoadInt32(&i.stats.Workers),
+
+		TCPConns:        atomic.LoadInt32(&i.stats.TCPConns),
+		TCPSessionCount: atomic.LoadUint64(&i.stats.TCPSessionCount),
+		TCPCount:        atomic.LoadUint64(&i.stats.TCPCount),
+		TLSErrorCount:   atomic.LoadUint64(&i.stats.TLSErrorCount),
+
+		FileQueue:      len(ipfixFileCh),
+		FileErrorCount: atomic.LoadUint64(&i.stats.FileErrorCount),
+
+		PendingBufferedCount: pending.BufferedCount,
+		PendingDecodedCount:  pending.DecodedCount,
+		PendingDroppedCount:  pending.DroppedCount,
+
+		UnknownElementCount: unknown,
+
+		TemplateInsertCount:   tpl.InsertCount,
+		TemplateRefreshCount:  tpl.RefreshCount,
+		TemplateChangeCount:   tpl.ChangeCount,
+		TemplateWithdrawCount: tpl.WithdrawCount,
+		TemplateEvictCount:    tpl.EvictCount,
+	}
+}
+
+// pendingSweeper drops the expired pending data sets periodically
+func pendingSweeper(p interface {
+	Sweep()
+}, timeout time.Duration) {
+	tick := time.Tick(timeout / 2)
+
+	for {
+		<-tick
+		p.Sweep()
+	}
+}
+
+// loadDictionary loads the information elements layers once
+// and logs the invalid elements, it runs before the decoders
+func loadDictionary() {
+	dictionaryOnce.Do(func() {
+		dict := ipfix.Dictionary{
+			IANAFile:      configFile(opts.IPFIXIANAFile),
+			Packs:         opts.IPFIXVendorPacks,
+			SiteFile:      configFile(opts.IPFIXElementsFile),
+			ExportersFile: configFile(opts.IPFIXExporterElementsFile),
+		}
+
+		for _, err := range dict.Load() {
+			logger.Println("ipfix elements:", err)
+		}
+	})
+}
+
+// ipfixMessages encodes the decoded message as a JSON message or
+// as a JSON message per data record if the record messages enabled
+func ipfixMessages(buf *bytes.Buffer, m *ipfix.Message) ([][]byte, error) {
+	if !opts.IPFIXRecordMessages {
+		buf.Reset()
+		b, err := m.JSONMarshalFormat(buf, ipfixJSONFormat)
+		if err != nil {
+			return nil, err
+		}
+
+		return [][]byte{append([]byte{}, b...)}, nil
+	}
+
+	msgs := make([][]byte, 0, len(m.DataSets))
+	for i := range m.DataSets {
+		buf.Reset()
+		b, err := m.JSONMarshalRecord(buf, i, ipfixJSONFormat)
+		if err != nil {
+			return msgs, err
+		}
+
+		msgs = append(msgs, append([]byte{}, b...))
+	}
+
+	return msgs, nil
+}
+
+// jsonFormat returns the data records JSON format of the protocol
+func jsonFormat(proto, name string) ipfix.JSONFormat {
+	format, err := ipfix.ParseJSONFormat(name)
+	if err != nil {
+		logger.Fatal(proto, " ", err)
+	}
+
+	return format
+}
+
+// configFile returns the file in the configuration directory
+// unless it's an absolute path
+func configFile(file string) string {
+	if file == "" || path.IsAbs(file) {
+		return file
+	}
+
+	return path.Join(opts.VFlowConfigPath, file)
+}
+
+// templateSweeper evicts the templates that the agents stopped
+// to refresh periodically, RFC 7011 8.4
+func templateSweeper(proto string, m interface {
+	Expire(time.Duration) map[string]int
+}, lifetime time.Duration) {
+	tick := time.Tick(lifetime / 2)
+
+	for {
+		<-tick
+		evicted := m.Expire(lifetime)
+		if !opts.Verbose {
+			continue
+		}
+
+		for agent, n := range evicted {
+			logger.Printf("%s :: %d template(s) of %s expired", proto, n, agent)
+		}
+	}
+}
+
+// templateCheckpoint dumps the templates periodically if they
+// changed so the learned templates survive a crash
+func templateCheckpoint(proto, file string, m interface {
+	Dump(string) error
+	Changes() uint64
+}, interval time.Duration) {
+	last := m.Changes()
+	tick := time.Tick(interval)
+
+	for {
+		<-tick
+		changes := m.Changes()
+		if changes == last {
+			continue
+		}
+
+		if err := m.Dump(file); err != nil {
+			logger.Printf("%s :: couldn't checkpoint templates %v", proto, err)
+			continue
+		}
+
+		last = changes
 	}
 }
 
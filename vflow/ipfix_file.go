@@ -0,0 +1,260 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    ipfix_file.go
+//: details: IPFIX file writer sink and file replay - RFC 5655
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/VerizonDigital/vflow/flow"
+	"github.com/VerizonDigital/vflow/ipfix"
+)
+
+// IPFIXFileMsg represents a raw IPFIX message to archive
+type IPFIXFileMsg struct {
+	exporter string
+	body     []byte
+}
+
+// IPFIXFileReader represents IPFIX files replay
+type IPFIXFileReader struct {
+	path  string
+	buf   *bytes.Buffer
+	out   *offlineOutput
+	stats IPFIXFileStats
+}
+
+// IPFIXFileStats represents IPFIX files replay stats
+type IPFIXFileStats struct {
+	FileCount    uint64
+	MessageCount uint64
+	DecodedCount uint64
+	ErrorCount   uint64
+}
+
+var ipfixFileCh = make(chan IPFIXFileMsg, 1000)
+
+// fileSink archives the raw IPFIX messages in arrival order
+func (i *IPFIX) fileSink() {
+	w := ipfix.NewFileWriter(
+		opts.IPFIXFileDir,
+		int64(opts.IPFIXFileMaxSize)*1024*1024,
+		time.Duration(opts.IPFIXFileInterval)*time.Second,
+	)
+
+	logger.Printf("ipfix file writer is running (directory: %s)", opts.IPFIXFileDir)
+
+	// the idle exporters' files are closed so the file
+	// descriptors don't grow with the exporters churn
+	var idleCh <-chan time.Time
+	if opts.IPFIXFileIdle > 0 {
+		idle := time.Duration(opts.IPFIXFileIdle) * time.Second
+		ticker := time.NewTicker(idle / 2)
+		defer ticker.Stop()
+		idleCh = ticker.C
+	}
+
+	for {
+		select {
+		case <-idleCh:
+			w.CloseIdle(time.Duration(opts.IPFIXFileIdle) * time.Second)
+		case <-i.fileQuit:
+			if err := w.Close(); err != nil {
+				logger.Println(err)
+			}
+			return
+		case msg := <-ipfixFileCh:
+			if err := w.Write(msg.exporter, msg.body); err != nil {
+				atomic.AddUint64(&i.stats.FileErrorCount, 1)
+				logger.Println(err)
+			}
+		}
+	}
+}
+
+// archive sends a copy of the raw IPFIX message to the file sink,
+// the callers check the file writer is enabled on the hot path
+func (i *IPFIX) archive(exporter string, b []byte) {
+	select {
+	case ipfixFileCh <- IPFIXFileMsg{exporter, append([]byte{}, b...)}:
+	default:
+		atomic.AddUint64(&i.stats.FileErrorCount, 1)
+	}
+}
+
+// NewIPFIXFileReader constructs IPFIX files replay,
+// the path can be a file or a directory of files
+func NewIPFIXFileReader(path string) *IPFIXFileReader {
+	logger = opts.Logger
+
+	return &IPFIXFileReader{
+		path: path,
+		buf:  new(bytes.Buffer),
+		out:  newOfflineOutput(),
+	}
+}
+
+func (r *IPFIXFileReader) run() {
+	files, err := ipfixFiles(r.path)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	loadDictionary()
+	ipfixJSONFormat = jsonFormat("ipfix", opts.IPFIXJSONFormat)
+	ipfixSampling = newSampling(opts.IPFIXSampling || opts.IPFIXSamplingUpscale)
+
+	for _, file := range files {
+		if err := r.replay(file); err != nil {
+			r.stats.ErrorCount++
+			logger.Println(file, err)
+		}
+	}
+
+	r.out.close()
+
+	logger.Printf("ipfix file %s: files#: %d, messages#: %d, decoded#: %d, errors#: %d",
+		r.path,
+		r.stats.FileCount,
+		r.stats.MessageCount,
+		r.stats.DecodedCount,
+		r.stats.ErrorCount,
+	)
+}
+
+// replay decodes a file with its own templates scope,
+// each file begins with the templates of the exporter
+func (r *IPFIXFileReader) replay(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r.stats.FileCount++
+
+	var (
+		mCache      = ipfix.NewMemCache()
+		rd          = ipfix.NewFileReader(f)
+		raddr, agID = ipfix.FileExporter(file)
+	)
+
+	for {
+		b, err := rd.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		r.stats.MessageCount++
+
+		d := ipfix.NewDecoder(raddr, b)
+		if agID != "" {
+			d.SetAgentID(agID)
+		}
+		d.SetStrict(opts.IPFIXStrictElements)
+		if ipfixSampling != nil {
+			d.SetSampling(ipfixSampling)
+		}
+
+		decodedMsg, err := d.Decode(mCache)
+		if err != nil {
+			r.stats.ErrorCount++
+			logger.Println(err)
+			if decodedMsg == nil {
+				continue
+			}
+		}
+
+		if len(decodedMsg.DataSets) < 1 {
+			continue
+		}
+
+		if opts.IPFIXAbsoluteTime {
+			decodedMsg.AbsoluteTime()
+		}
+
+		if opts.IPFIXSamplingUpscale {
+			decodedMsg.Upscale()
+		}
+
+		if opts.FlowEnabled {
+			for _, b := range flowMessages(flow.FromIPFIX(decodedMsg)) {
+				r.out.write(opts.FlowTopic, b)
+			}
+		}
+
+		if !rawOutput() {
+			r.stats.DecodedCount++
+			continue
+		}
+
+		msgs, err := ipfixMessages(r.buf, decodedMsg)
+		if err != nil {
+			logger.Println(err)
+		}
+
+		if len(msgs) > 0 {
+			r.stats.DecodedCount++
+		}
+
+		for _, msg := range msgs {
+			r.out.write(opts.IPFIXTopic, msg)
+		}
+	}
+}
+
+// ipfixFiles returns the file or the IPFIX files
+// in the directory tree sorted by the path
+func ipfixFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.Walk(path, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() && filepath.Ext(p) == ".ipfix" {
+			files = append(files, p)
+		}
+		return nil
+	})
+
+	sort.Strings(files)
+
+	return files, err
+}
@@ -0,0 +1,258 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    ipfix_tcp.go
+//: details: IPFIX over TCP transport - RFC 7011 section 10.4
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/VerizonDigital/vflow/flow"
+	"github.com/VerizonDigital/vflow/ipfix"
+)
+
+// ipfixMsgHdrLen is the IPFIX message header length
+const ipfixMsgHdrLen = 16
+
+var errIPFIXTCPFraming = errors.New("invalid ipfix message header over tcp")
+
+func (i *IPFIX) runTCP() {
+	hostPort := net.JoinHostPort(i.addr, strconv.Itoa(opts.IPFIXTCPPort))
+
+	ln, err := net.Listen("tcp", hostPort)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	logger.Printf("ipfix is running (TCP: listening on [::]:%d)", opts.IPFIXTCPPort)
+
+	i.serveTCP(ln)
+}
+
+// serveTCP accepts the IPFIX sessions through the listener
+// until the ipfix service stops
+func (i *IPFIX) serveTCP(ln net.Listener) {
+	i.tcpMu.Lock()
+	i.tcpListeners = append(i.tcpListeners, ln)
+	i.tcpMu.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if i.stop {
+				return
+			}
+
+			logger.Println(err)
+			continue
+		}
+
+		if opts.IPFIXTCPMaxConns > 0 && int(atomic.LoadInt32(&i.stats.TCPConns)) >= opts.IPFIXTCPMaxConns {
+			logger.Printf("ipfix tcp session from: %s rejected, max sessions reached", conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
+
+		atomic.AddInt32(&i.stats.TCPConns, 1)
+
+		go i.ipfixTCPSession(conn)
+	}
+}
+
+func (i *IPFIX) shutdownTCP() {
+	i.tcpMu.Lock()
+	defer i.tcpMu.Unlock()
+
+	for _, ln := range i.tcpListeners {
+		ln.Close()
+	}
+
+	for conn := range i.tcpConns {
+		conn.Close()
+	}
+}
+
+// ipfixTCPSession decodes the IPFIX messages of a TCP session in order.
+// the templates are scoped to the session and they're flushed once
+// the session closed as they're sent once per session and never refreshed.
+func (i *IPFIX) ipfixTCPSession(conn net.Conn) {
+	var (
+		mem        = ipfix.NewMemCache()
+		r          = bufio.NewReader(conn)
+		raddr      = conn.RemoteAddr().(*net.TCPAddr)
+		agentID    string
+		buf        = new(bytes.Buffer)
+		decodedMsg *ipfix.Message
+		body       []byte
+		msgs       [][]byte
+		err        error
+	)
+
+	i.tcpMu.Lock()
+	i.tcpConns[conn] = struct{}{}
+	i.tcpMu.Unlock()
+
+	atomic.AddUint64(&i.stats.TCPSessionCount, 1)
+
+	if opts.Verbose {
+		logger.Printf("ipfix tcp session from: %s has been established", raddr)
+	}
+
+	defer func() {
+		conn.Close()
+		mem.Flush()
+
+		i.tcpMu.Lock()
+		delete(i.tcpConns, conn)
+		i.tcpMu.Unlock()
+
+		atomic.AddInt32(&i.stats.TCPConns, -1)
+
+		if opts.Verbose {
+			logger.Printf("ipfix tcp session from: %s has been closed", raddr)
+		}
+	}()
+
+	setIPFIXTCPDeadline(conn)
+
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		if agentID, err = ipfixTLSAgentID(tlsConn); err != nil {
+			atomic.AddUint64(&i.stats.TLSErrorCount, 1)
+			logger.Println(raddr, err)
+			return
+		}
+	}
+
+	for !i.stop {
+		setIPFIXTCPDeadline(conn)
+
+		body, err = readIPFIXTCPMsg(r, body)
+		if err != nil {
+			if err != io.EOF && !i.stop {
+				logger.Println(raddr, err)
+			}
+			return
+		}
+
+		atomic.AddUint64(&i.stats.TCPCount, 1)
+
+		if opts.IPFIXFileEnabled {
+			if agentID != "" {
+				i.archive(agentID, body)
+			} else {
+				i.archive(raddr.IP.String(), body)
+			}
+		}
+
+		d := ipfix.NewDecoder(raddr.IP, body)
+		d.SetAgentID(agentID)
+		d.SetStrict(opts.IPFIXStrictElements)
+		d.SetReceiveTime(time.Now())
+		if ipfixSampling != nil {
+			d.SetSampling(ipfixSampling)
+		}
+		if decodedMsg, err = d.Decode(mem); err != nil {
+			logger.Println(err)
+			// in case ipfix message header couldn't decode
+			if decodedMsg == nil {
+				continue
+			}
+		}
+
+		atomic.AddUint64(&i.stats.DecodedCount, 1)
+
+		clockSkew("ipfix", decodedMsg.AgentID, decodedMsg.Header.ExportTime, decodedMsg.ReceiveTime)
+
+		if opts.IPFIXAbsoluteTime {
+			decodedMsg.AbsoluteTime()
+		}
+
+		if opts.IPFIXSamplingUpscale {
+			decodedMsg.Upscale()
+		}
+
+		if len(decodedMsg.DataSets) > 0 && opts.FlowEnabled {
+			produceFlows(flow.FromIPFIX(decodedMsg))
+		}
+
+		if len(decodedMsg.DataSets) > 0 && rawOutput() {
+			msgs, err = ipfixMessages(buf, decodedMsg)
+			if err != nil {
+				logger.Println(err)
+			}
+
+			for _, b := range msgs {
+				select {
+				case ipfixMQCh <- b:
+				default:
+				}
+
+				if opts.Verbose {
+					logger.Println(string(b))
+				}
+			}
+		}
+	}
+}
+
+// setIPFIXTCPDeadline extends the session read deadline by the idle
+// timeout so an idle or stalled exporter doesn't hold the session
+func setIPFIXTCPDeadline(conn net.Conn) {
+	if opts.IPFIXTCPIdle > 0 {
+		conn.SetReadDeadline(time.Now().Add(time.Duration(opts.IPFIXTCPIdle) * time.Second))
+	}
+}
+
+// readIPFIXTCPMsg reads a whole IPFIX message from the stream,
+// the message is framed by the length field of the message header
+func readIPFIXTCPMsg(r *bufio.Reader, b []byte) ([]byte, error) {
+	hdr, err := r.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+
+	version := binary.BigEndian.Uint16(hdr[0:2])
+	length := int(binary.BigEndian.Uint16(hdr[2:4]))
+
+	if version != 0x000a || length < ipfixMsgHdrLen {
+		return nil, errIPFIXTCPFraming
+	}
+
+	if cap(b) < length {
+		b = make([]byte, length)
+	}
+	b = b[:length]
+
+	if _, err = io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
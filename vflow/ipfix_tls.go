@@ -0,0 +1,107 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    ipfix_tls.go
+//: details: IPFIX over TLS transport - RFC 7011 section 11
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"strconv"
+)
+
+func (i *IPFIX) runTLS() {
+	hostPort := net.JoinHostPort(i.addr, strconv.Itoa(opts.IPFIXTLSPort))
+
+	ln, err := tls.Listen("tcp", hostPort, ipfixTLSConfig())
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	logger.Printf("ipfix is running (TLS: listening on [::]:%d)", opts.IPFIXTLSPort)
+
+	i.serveTCP(ln)
+}
+
+func ipfixTLSConfig() *tls.Config {
+	cert, err := tls.LoadX509KeyPair(opts.IPFIXTLSCertFile, opts.IPFIXTLSKeyFile)
+	if err != nil {
+		logger.Fatal("IPFIX TLS error: ", err)
+	}
+
+	t := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.NoClientCert,
+	}
+
+	if opts.IPFIXTLSCAFile != "" {
+		caCert, err := ioutil.ReadFile(opts.IPFIXTLSCAFile)
+		if err != nil {
+			logger.Fatal("IPFIX TLS error: ", err)
+		}
+
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			logger.Fatal("IPFIX TLS error: no certificate in ", opts.IPFIXTLSCAFile)
+		}
+
+		t.ClientCAs = caCertPool
+		t.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	// the client certificates verify against the system roots without
+	// the CA bundle, any public certificate could claim an exporter
+	if opts.IPFIXTLSVerifyClient {
+		if t.ClientCAs == nil {
+			logger.Fatal("IPFIX TLS error: ipfix-tls-verify-client needs ipfix-tls-ca-file")
+		}
+
+		t.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return t
+}
+
+// ipfixTLSAgentID completes the handshake and returns the exporter
+// identity from the client certificate subject if it's enabled
+func ipfixTLSAgentID(conn *tls.Conn) (string, error) {
+	if err := conn.Handshake(); err != nil {
+		return "", err
+	}
+
+	if !opts.IPFIXTLSCertAgentID {
+		return "", nil
+	}
+
+	state := conn.ConnectionState()
+	if len(state.PeerCertificates) < 1 {
+		return "", nil
+	}
+
+	subject := state.PeerCertificates[0].Subject
+	if subject.CommonName != "" {
+		return subject.CommonName, nil
+	}
+
+	return subject.String(), nil
+}
@@ -31,6 +31,8 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/VerizonDigital/vflow/flow"
+	"github.com/VerizonDigital/vflow/ipfix"
 	"github.com/VerizonDigital/vflow/netflow/v9"
 	"github.com/VerizonDigital/vflow/producer"
 )
@@ -49,6 +51,7 @@ type NetflowV9 struct {
 type NetflowV9UDPMsg struct {
 	raddr *net.UDPAddr
 	body  []byte
+	rcvd  time.Time
 }
 
 // NetflowV9Stats represents netflow v9 stats
@@ -59,6 +62,15 @@ type NetflowV9Stats struct {
 	DecodedCount uint64
 	MQErrorCount uint64
 	Workers      int32
+
+	PendingBufferedCount uint64
+	PendingDecodedCount  uint64
+	PendingDroppedCount  uint64
+
+	TemplateInsertCount  uint64
+	TemplateRefreshCount uint64
+	TemplateChangeCount  uint64
+	TemplateEvictCount   uint64
 }
 
 var (
@@ -67,6 +79,12 @@ var (
 
 	mCacheNF9 netflow9.MemCache
 
+	// data sets without template
+	netflowV9Pending *netflow9.Pending
+
+	// data records JSON format
+	netflowV9JSONFormat ipfix.JSONFormat
+
 	// ipfix udp payload pool
 	netflowV9Buffer = &sync.Pool{
 		New: func() interface{} {
@@ -100,6 +118,10 @@ func (i *NetflowV9) run() {
 		logger.Fatal(err)
 	}
 
+	// the vendor packs carry the Netflow v9 vendor fields
+	loadDictionary()
+	netflowV9JSONFormat = jsonFormat("netflow v9", opts.NetflowV9JSONFormat)
+
 	atomic.AddInt32(&i.stats.Workers, int32(i.workers))
 	for n := 0; n < i.workers; n++ {
 		go func() {
@@ -111,7 +133,30 @@ func (i *NetflowV9) run() {
 
 	logger.Printf("netflow v9 is running (UDP: listening on [::]:%d workers#: %d)", i.port, i.workers)
 
-	mCacheNF9 = netflow9.GetCache(opts.NetflowV9TplCacheFile)
+	if opts.NetflowV9TplCacheEnabled {
+		mCacheNF9 = netflow9.GetCache(opts.NetflowV9TplCacheFile)
+	} else {
+		mCacheNF9 = netflow9.NewMemCache()
+	}
+
+	if opts.NetflowV9TplCacheEnabled && opts.NetflowV9TplCheckpoint > 0 {
+		go templateCheckpoint("netflow v9", opts.NetflowV9TplCacheFile, mCacheNF9,
+			time.Duration(opts.NetflowV9TplCheckpoint)*time.Second)
+	}
+
+	if opts.NetflowV9PendingTimeout > 0 {
+		timeout := time.Duration(opts.NetflowV9PendingTimeout) * time.Second
+		netflowV9Pending = netflow9.NewPending(timeout, opts.NetflowV9PendingMaxSize*1024)
+		go pendingSweeper(netflowV9Pending, timeout)
+	}
+
+	netflowV9Sampling = newSampling(opts.NetflowV9Sampling || opts.NetflowV9SamplingUpscale)
+
+	if opts.NetflowV9TplLifetime > 0 {
+		go templateSweeper("netflow v9", mCacheNF9, time.Duration(opts.NetflowV9TplLifetime)*time.Second)
+	}
+
+	flowProducer()
 
 	go func() {
 		p := producer.NewProducer(opts.MQName)
@@ -144,7 +189,7 @@ func (i *NetflowV9) run() {
 			continue
 		}
 		atomic.AddUint64(&i.stats.UDPCount, 1)
-		netflowV9UDPCh <- NetflowV9UDPMsg{raddr, b[:n]}
+		netflowV9UDPCh <- NetflowV9UDPMsg{raddr, b[:n], time.Now()}
 	}
 
 }
@@ -162,8 +207,10 @@ func (i *NetflowV9) shutdown() {
 	time.Sleep(1 * time.Second)
 
 	// dump the templates to storage
-	if err := mCacheNF9.Dump(opts.NetflowV9TplCacheFile); err != nil {
-		logger.Println("couldn't not dump template", err)
+	if opts.NetflowV9TplCacheEnabled {
+		if err := mCacheNF9.Dump(opts.NetflowV9TplCacheFile); err != nil {
+			logger.Println("couldn't not dump template", err)
+		}
 	}
 
 	// logging and close UDP channel
@@ -178,7 +225,7 @@ func (i *NetflowV9) netflowV9Worker(wQuit chan struct{}) {
 		buf        = new(bytes.Buffer)
 		err        error
 		ok         bool
-		b          []byte
+		msgs       [][]byte
 	)
 
 LOOP:
@@ -202,6 +249,16 @@ LOOP:
 		}
 
 		d := netflow9.NewDecoder(msg.raddr.IP, msg.body)
+		if netflowV9Pending != nil {
+			d.SetPending(netflowV9Pending)
+		}
+		if opts.NetflowV9TplScopePort {
+			d.SetSourcePort(msg.raddr.Port)
+		}
+		d.SetReceiveTime(msg.rcvd)
+		if netflowV9Sampling != nil {
+			d.SetSampling(netflowV9Sampling)
+		}
 		if decodedMsg, err = d.Decode(mCacheNF9); err != nil {
 			logger.Println(err)
 			if decodedMsg == nil {
@@ -211,28 +268,86 @@ LOOP:
 
 		atomic.AddUint64(&i.stats.DecodedCount, 1)
 
-		if decodedMsg.DataSets != nil {
-			b, err = decodedMsg.JSONMarshal(buf)
+		clockSkew("netflow9", decodedMsg.AgentID, decodedMsg.Header.UNIXSecs, decodedMsg.ReceiveTime)
+
+		// the buffered data sets that decoded by this message's templates
+		for _, m := range append([]*netflow9.Message{decodedMsg}, decodedMsg.Pending...) {
+			if m.DataSets == nil {
+				continue
+			}
+
+			if opts.NetflowV9AbsoluteTime {
+				m.AbsoluteTime()
+			}
+
+			if opts.NetflowV9SamplingUpscale {
+				m.Upscale()
+			}
+
+			if opts.FlowEnabled {
+				produceFlows(flow.FromNetflowV9(m))
+			}
+
+			if !rawOutput() {
+				continue
+			}
+
+			msgs, err = netflowV9Messages(buf, m)
 			if err != nil {
 				logger.Println(err)
-				continue
 			}
 
-			select {
-			case netflowV9MQCh <- append([]byte{}, b...):
-			default:
+			for _, b := range msgs {
+				select {
+				case netflowV9MQCh <- b:
+				default:
+				}
+
+				if opts.Verbose {
+					logger.Println(string(b))
+				}
 			}
 		}
 
-		if opts.Verbose {
-			logger.Println(string(b))
+	}
+
+}
+
+// netflowV9Messages encodes the decoded message as a JSON message or
+// as a JSON message per data record if the record messages enabled
+func netflowV9Messages(buf *bytes.Buffer, m *netflow9.Message) ([][]byte, error) {
+	if !opts.NetflowV9RecordMessages {
+		buf.Reset()
+		b, err := m.JSONMarshalFormat(buf, netflowV9JSONFormat)
+		if err != nil {
+			return nil, err
 		}
 
+		return [][]byte{append([]byte{}, b...)}, nil
 	}
 
+	msgs := make([][]byte, 0, len(m.DataSets))
+	for i := range m.DataSets {
+		buf.Reset()
+		b, err := m.JSONMarshalRecord(buf, i, netflowV9JSONFormat)
+		if err != nil {
+			return msgs, err
+		}
+
+		msgs = append(msgs, append([]byte{}, b...))
+	}
+
+	return msgs, nil
 }
 
 func (i *NetflowV9) status() *NetflowV9Stats {
+	var pending netflow9.PendingStats
+	if netflowV9Pending != nil {
+		pending = netflowV9Pending.Stats()
+	}
+
+	tpl := mCacheNF9.Stats()
+
 	return &NetflowV9Stats{
 		UDPQueue:     len(netflowV9UDPCh),
 		MessageQueue: len(netflowV9MQCh),
@@ -240,6 +355,15 @@ func (i *NetflowV9) status() *NetflowV9Stats {
 		DecodedCount: atomic.LoadUint64(&i.stats.DecodedCount),
 		MQErrorCount: atomic.LoadUint64(&i.stats.MQErrorCount),
 		Workers:      atomic.LoadInt32(&i.stats.Workers),
+
+		PendingBufferedCount: pending.BufferedCount,
+		PendingDecodedCount:  pending.DecodedCount,
+		PendingDroppedCount:  pending.DroppedCount,
+
+		TemplateInsertCount:  tpl.InsertCount,
+		TemplateRefreshCount: tpl.RefreshCount,
+		TemplateChangeCount:  tpl.ChangeCount,
+		TemplateEvictCount:   tpl.EvictCount,
 	}
 
 }
@@ -0,0 +1,87 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    offline.go
+//: details: offline decoded messages output
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package main
+
+import (
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/VerizonDigital/vflow/producer"
+)
+
+// offlineOutput writes the offline decoded messages to the stdout
+// or to the producer with the protocol's topic, it blocks rather
+// than dropping messages since there is no real time constraint
+type offlineOutput struct {
+	chans        map[string]chan []byte
+	wg           sync.WaitGroup
+	mqErrorCount uint64
+}
+
+func newOfflineOutput() *offlineOutput {
+	return &offlineOutput{
+		chans: make(map[string]chan []byte),
+	}
+}
+
+func (o *offlineOutput) write(topic string, msg []byte) {
+	if !opts.OfflineProducer {
+		fmt.Println(string(msg))
+		return
+	}
+
+	ch, ok := o.chans[topic]
+	if !ok {
+		ch = make(chan []byte, 1000)
+		o.chans[topic] = ch
+
+		o.wg.Add(1)
+		go func() {
+			defer o.wg.Done()
+
+			p := producer.NewProducer(opts.MQName)
+
+			p.MQConfigFile = path.Join(opts.VFlowConfigPath, opts.MQConfigFile)
+			p.MQErrorCount = &o.mqErrorCount
+			p.Logger = logger
+			p.Chan = ch
+			p.Topic = topic
+
+			if err := p.Run(); err != nil {
+				logger.Fatal(err)
+			}
+		}()
+	}
+
+	ch <- append([]byte{}, msg...)
+}
+
+// close flushes the producers
+func (o *offlineOutput) close() {
+	for _, ch := range o.chans {
+		close(ch)
+	}
+
+	o.wg.Wait()
+}
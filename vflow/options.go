@@ -44,6 +44,8 @@ var (
 
 type arrUInt32Flags []uint32
 
+type arrStringFlags []string
+
 // Options represents options
 type Options struct {
 	// global options
@@ -68,6 +70,10 @@ type Options struct {
 	SFlowTopic      string         `yaml:"sflow-topic"`
 	SFlowTypeFilter arrUInt32Flags `yaml:"sflow-type-filter"`
 
+	SFlowCountersEnabled bool   `yaml:"sflow-counters-enabled"`
+	SFlowCountersTopic   string `yaml:"sflow-counters-topic"`
+	SFlowCountersRates   bool   `yaml:"sflow-counters-rates"`
+
 	// IPFIX options
 	IPFIXEnabled       bool   `yaml:"ipfix-enabled"`
 	IPFIXRPCEnabled    bool   `yaml:"ipfix-rpc-enabled"`
@@ -80,6 +86,54 @@ type Options struct {
 	IPFIXMirrorPort    int    `yaml:"ipfix-mirror-port"`
 	IPFIXMirrorWorkers int    `yaml:"ipfix-mirror-workers"`
 	IPFIXTplCacheFile  string `yaml:"ipfix-tpl-cache-file"`
+	IPFIXTplScopePort  bool   `yaml:"ipfix-tpl-scope-port"`
+	IPFIXTplLifetime   int    `yaml:"ipfix-tpl-lifetime"`
+	IPFIXTCPEnabled    bool   `yaml:"ipfix-tcp-enabled"`
+	IPFIXTCPPort       int    `yaml:"ipfix-tcp-port"`
+	IPFIXTCPIdle       int    `yaml:"ipfix-tcp-idle-timeout"`
+	IPFIXTCPMaxConns   int    `yaml:"ipfix-tcp-max-conns"`
+
+	IPFIXTLSEnabled      bool   `yaml:"ipfix-tls-enabled"`
+	IPFIXTLSPort         int    `yaml:"ipfix-tls-port"`
+	IPFIXTLSCertFile     string `yaml:"ipfix-tls-cert"`
+	IPFIXTLSKeyFile      string `yaml:"ipfix-tls-key"`
+	IPFIXTLSCAFile       string `yaml:"ipfix-tls-ca-file"`
+	IPFIXTLSVerifyClient bool   `yaml:"ipfix-tls-verify-client"`
+	IPFIXTLSCertAgentID  bool   `yaml:"ipfix-tls-cert-agent-id"`
+
+	IPFIXFileEnabled  bool   `yaml:"ipfix-file-enabled"`
+	IPFIXFileDir      string `yaml:"ipfix-file-dir"`
+	IPFIXFileMaxSize  int    `yaml:"ipfix-file-max-size"`
+	IPFIXFileInterval int    `yaml:"ipfix-file-interval"`
+	IPFIXFileIdle     int    `yaml:"ipfix-file-idle-timeout"`
+
+	IPFIXTplCacheEnabled bool `yaml:"ipfix-tpl-cache-enabled"`
+	IPFIXTplCheckpoint   int  `yaml:"ipfix-tpl-checkpoint-interval"`
+
+	IPFIXStrictElements bool `yaml:"ipfix-strict-elements"`
+
+	IPFIXIANAFile             string `yaml:"ipfix-iana-file"`
+	IPFIXElementsFile         string `yaml:"ipfix-elements-file"`
+	IPFIXExporterElementsFile string `yaml:"ipfix-exporter-elements-file"`
+
+	IPFIXVendorPacks arrStringFlags `yaml:"ipfix-vendor-packs"`
+
+	IPFIXJSONFormat     string `yaml:"ipfix-json-format"`
+	IPFIXRecordMessages bool   `yaml:"ipfix-record-messages"`
+	IPFIXAbsoluteTime   bool   `yaml:"ipfix-absolute-time"`
+
+	IPFIXSampling        bool `yaml:"ipfix-sampling"`
+	IPFIXSamplingUpscale bool `yaml:"ipfix-sampling-upscale"`
+
+	IPFIXPendingTimeout int `yaml:"ipfix-pending-timeout"`
+	IPFIXPendingMaxSize int `yaml:"ipfix-pending-max-size"`
+
+	// Netflow v5
+	NetflowV5Enabled bool   `yaml:"netflow5-enabled"`
+	NetflowV5Port    int    `yaml:"netflow5-port"`
+	NetflowV5UDPSize int    `yaml:"netflow5-udp-size"`
+	NetflowV5Workers int    `yaml:"netflow5-workers"`
+	NetflowV5Topic   string `yaml:"netflow5-topic"`
 
 	// Netflow
 	NetflowV9Enabled      bool   `yaml:"netflow9-enabled"`
@@ -88,6 +142,40 @@ type Options struct {
 	NetflowV9Workers      int    `yaml:"netflow9-workers"`
 	NetflowV9Topic        string `yaml:"netflow9-topic"`
 	NetflowV9TplCacheFile string `yaml:"netflow9-tpl-cache-file"`
+	NetflowV9TplScopePort bool   `yaml:"netflow9-tpl-scope-port"`
+	NetflowV9TplLifetime  int    `yaml:"netflow9-tpl-lifetime"`
+
+	NetflowV9TplCacheEnabled bool `yaml:"netflow9-tpl-cache-enabled"`
+	NetflowV9TplCheckpoint   int  `yaml:"netflow9-tpl-checkpoint-interval"`
+
+	NetflowV9PendingTimeout int `yaml:"netflow9-pending-timeout"`
+	NetflowV9PendingMaxSize int `yaml:"netflow9-pending-max-size"`
+
+	NetflowV9JSONFormat     string `yaml:"netflow9-json-format"`
+	NetflowV9RecordMessages bool   `yaml:"netflow9-record-messages"`
+	NetflowV9AbsoluteTime   bool   `yaml:"netflow9-absolute-time"`
+
+	NetflowV9Sampling        bool `yaml:"netflow9-sampling"`
+	NetflowV9SamplingUpscale bool `yaml:"netflow9-sampling-upscale"`
+
+	// static sampling rates
+	SamplingOverrides arrStringFlags `yaml:"sampling-overrides"`
+
+	// normalized flow
+	FlowEnabled   bool   `yaml:"flow-enabled"`
+	FlowTopic     string `yaml:"flow-topic"`
+	FlowRawOutput bool   `yaml:"flow-raw-output"`
+
+	// exporters clock skew
+	ClockSkewThreshold int `yaml:"clock-skew-threshold"`
+
+	// offline decoding
+	ReadPcap        string `yaml:"read-pcap"`
+	ReadIPFIXFile   string `yaml:"read-ipfix-file"`
+	OfflineProducer bool   `yaml:"offline-producer"`
+
+	// ReadPcapProducer is the former name of the offline-producer
+	ReadPcapProducer bool `yaml:"read-pcap-producer"`
 
 	// producer
 	MQName       string `yaml:"mq-name"`
@@ -119,6 +207,20 @@ func (a *arrUInt32Flags) Set(value string) error {
 	return nil
 }
 
+func (a *arrStringFlags) String() string {
+	return strings.Join(*a, ",")
+}
+
+func (a *arrStringFlags) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*a = append(*a, v)
+		}
+	}
+
+	return nil
+}
+
 // NewOptions constructs new options
 func NewOptions() *Options {
 	return &Options{
@@ -140,6 +242,10 @@ func NewOptions() *Options {
 		SFlowTopic:      "vflow.sflow",
 		SFlowTypeFilter: []uint32{},
 
+		SFlowCountersEnabled: false,
+		SFlowCountersTopic:   "vflow.sflow.counters",
+		SFlowCountersRates:   false,
+
 		IPFIXEnabled:       true,
 		IPFIXRPCEnabled:    true,
 		IPFIXPort:          4739,
@@ -150,6 +256,50 @@ func NewOptions() *Options {
 		IPFIXMirrorPort:    4172,
 		IPFIXMirrorWorkers: 5,
 		IPFIXTplCacheFile:  "/tmp/vflow.templates",
+		IPFIXTplScopePort:  false,
+		IPFIXTplLifetime:   1800,
+		IPFIXTCPEnabled:    false,
+		IPFIXTCPPort:       4739,
+		IPFIXTCPIdle:       300,
+		IPFIXTCPMaxConns:   1000,
+
+		IPFIXTLSEnabled:      false,
+		IPFIXTLSPort:         4740,
+		IPFIXTLSVerifyClient: true,
+		IPFIXTLSCertAgentID:  false,
+
+		IPFIXFileEnabled:  false,
+		IPFIXFileDir:      "/var/lib/vflow/ipfix",
+		IPFIXFileMaxSize:  100,
+		IPFIXFileInterval: 3600,
+		IPFIXFileIdle:     300,
+
+		IPFIXTplCacheEnabled: true,
+		IPFIXTplCheckpoint:   60,
+
+		IPFIXStrictElements: false,
+
+		IPFIXIANAFile:             "",
+		IPFIXElementsFile:         "ipfix.elements",
+		IPFIXExporterElementsFile: "ipfix.exporters",
+
+		IPFIXVendorPacks: []string{},
+
+		IPFIXJSONFormat:     "compact",
+		IPFIXRecordMessages: false,
+		IPFIXAbsoluteTime:   false,
+
+		IPFIXSampling:        false,
+		IPFIXSamplingUpscale: false,
+
+		IPFIXPendingTimeout: 60,
+		IPFIXPendingMaxSize: 1024,
+
+		NetflowV5Enabled: true,
+		NetflowV5Port:    2055,
+		NetflowV5UDPSize: 1500,
+		NetflowV5Workers: 200,
+		NetflowV5Topic:   "vflow.netflow5",
 
 		NetflowV9Enabled:      true,
 		NetflowV9Port:         4729,
@@ -157,6 +307,33 @@ func NewOptions() *Options {
 		NetflowV9Workers:      200,
 		NetflowV9Topic:        "vflow.netflow9",
 		NetflowV9TplCacheFile: "/tmp/netflowv9.templates",
+		NetflowV9TplScopePort: false,
+		NetflowV9TplLifetime:  1800,
+
+		NetflowV9TplCacheEnabled: true,
+		NetflowV9TplCheckpoint:   60,
+
+		NetflowV9PendingTimeout: 60,
+		NetflowV9PendingMaxSize: 1024,
+
+		NetflowV9JSONFormat:     "compact",
+		NetflowV9RecordMessages: false,
+		NetflowV9AbsoluteTime:   false,
+
+		NetflowV9Sampling:        false,
+		NetflowV9SamplingUpscale: false,
+
+		SamplingOverrides: []string{},
+
+		FlowEnabled:   false,
+		FlowTopic:     "vflow.flow",
+		FlowRawOutput: true,
+
+		ClockSkewThreshold: 10,
+
+		ReadPcap:        "",
+		ReadIPFIXFile:   "",
+		OfflineProducer: false,
 
 		MQName:       "kafka",
 		MQConfigFile: "mq.conf",
@@ -186,6 +363,11 @@ func GetOptions() *Options {
 		}
 	}
 
+	// offline decoding can run next to the vFlow service
+	if opts.ReadPcap != "" || opts.ReadIPFIXFile != "" {
+		return opts
+	}
+
 	if ok := opts.vFlowIsRunning(); ok {
 		opts.Logger.Fatal("the vFlow already is running!")
 	}
@@ -302,6 +484,9 @@ func (opts *Options) vFlowFlagSet() {
 	flag.IntVar(&opts.SFlowWorkers, "sflow-workers", opts.SFlowWorkers, "sflow workers number")
 	flag.StringVar(&opts.SFlowTopic, "sflow-topic", opts.SFlowTopic, "sflow topic name")
 	flag.Var(&opts.SFlowTypeFilter, "sflow-type-filter", "sflow type filter")
+	flag.BoolVar(&opts.SFlowCountersEnabled, "sflow-counters-enabled", opts.SFlowCountersEnabled, "enable/disable sflow counter records")
+	flag.StringVar(&opts.SFlowCountersTopic, "sflow-counters-topic", opts.SFlowCountersTopic, "sflow counter records topic name")
+	flag.BoolVar(&opts.SFlowCountersRates, "sflow-counters-rates", opts.SFlowCountersRates, "enable/disable sflow counters per second rates")
 
 	// ipfix options
 	flag.BoolVar(&opts.IPFIXEnabled, "ipfix-enabled", opts.IPFIXEnabled, "enable/disable IPFIX listener")
@@ -312,9 +497,48 @@ func (opts *Options) vFlowFlagSet() {
 	flag.IntVar(&opts.IPFIXWorkers, "ipfix-workers", opts.IPFIXWorkers, "IPFIX workers number")
 	flag.StringVar(&opts.IPFIXTopic, "ipfix-topic", opts.IPFIXTopic, "ipfix topic name")
 	flag.StringVar(&opts.IPFIXTplCacheFile, "ipfix-tpl-cache-file", opts.IPFIXTplCacheFile, "IPFIX template cache file")
+	flag.BoolVar(&opts.IPFIXTplCacheEnabled, "ipfix-tpl-cache-enabled", opts.IPFIXTplCacheEnabled, "enable/disable IPFIX templates persistence")
+	flag.IntVar(&opts.IPFIXTplCheckpoint, "ipfix-tpl-checkpoint-interval", opts.IPFIXTplCheckpoint, "IPFIX templates checkpoint interval in seconds, zero disables")
+	flag.BoolVar(&opts.IPFIXTplScopePort, "ipfix-tpl-scope-port", opts.IPFIXTplScopePort, "enable/disable IPFIX agent source port in the templates scope")
+	flag.IntVar(&opts.IPFIXTplLifetime, "ipfix-tpl-lifetime", opts.IPFIXTplLifetime, "IPFIX UDP template lifetime in seconds, zero disables")
 	flag.StringVar(&opts.IPFIXMirrorAddr, "ipfix-mirror-addr", opts.IPFIXMirrorAddr, "IPFIX mirror destination address")
 	flag.IntVar(&opts.IPFIXMirrorPort, "ipfix-mirror-port", opts.IPFIXMirrorPort, "IPFIX mirror destination port number")
 	flag.IntVar(&opts.IPFIXMirrorWorkers, "ipfix-mirror-workers", opts.IPFIXMirrorWorkers, "IPFIX mirror workers number")
+	flag.BoolVar(&opts.IPFIXTCPEnabled, "ipfix-tcp-enabled", opts.IPFIXTCPEnabled, "enable/disable IPFIX TCP listener")
+	flag.IntVar(&opts.IPFIXTCPPort, "ipfix-tcp-port", opts.IPFIXTCPPort, "IPFIX TCP port number")
+	flag.IntVar(&opts.IPFIXTCPIdle, "ipfix-tcp-idle-timeout", opts.IPFIXTCPIdle, "IPFIX TCP/TLS session idle timeout in seconds, 0 disables")
+	flag.IntVar(&opts.IPFIXTCPMaxConns, "ipfix-tcp-max-conns", opts.IPFIXTCPMaxConns, "IPFIX TCP/TLS maximum concurrent sessions, 0 unlimited")
+	flag.BoolVar(&opts.IPFIXTLSEnabled, "ipfix-tls-enabled", opts.IPFIXTLSEnabled, "enable/disable IPFIX TLS listener")
+	flag.IntVar(&opts.IPFIXTLSPort, "ipfix-tls-port", opts.IPFIXTLSPort, "IPFIX TLS port number")
+	flag.StringVar(&opts.IPFIXTLSCertFile, "ipfix-tls-cert", opts.IPFIXTLSCertFile, "IPFIX TLS server certificate file")
+	flag.StringVar(&opts.IPFIXTLSKeyFile, "ipfix-tls-key", opts.IPFIXTLSKeyFile, "IPFIX TLS server key file")
+	flag.StringVar(&opts.IPFIXTLSCAFile, "ipfix-tls-ca-file", opts.IPFIXTLSCAFile, "IPFIX TLS CA bundle to verify the exporters")
+	flag.BoolVar(&opts.IPFIXTLSVerifyClient, "ipfix-tls-verify-client", opts.IPFIXTLSVerifyClient, "enable/disable IPFIX TLS client certificate verification")
+	flag.BoolVar(&opts.IPFIXTLSCertAgentID, "ipfix-tls-cert-agent-id", opts.IPFIXTLSCertAgentID, "enable/disable IPFIX TLS exporter identity from client certificate")
+	flag.BoolVar(&opts.IPFIXFileEnabled, "ipfix-file-enabled", opts.IPFIXFileEnabled, "enable/disable IPFIX file writer")
+	flag.StringVar(&opts.IPFIXFileDir, "ipfix-file-dir", opts.IPFIXFileDir, "IPFIX files directory")
+	flag.IntVar(&opts.IPFIXFileMaxSize, "ipfix-file-max-size", opts.IPFIXFileMaxSize, "IPFIX file maximum size in megabytes")
+	flag.IntVar(&opts.IPFIXFileInterval, "ipfix-file-interval", opts.IPFIXFileInterval, "IPFIX file rotation interval in seconds")
+	flag.IntVar(&opts.IPFIXFileIdle, "ipfix-file-idle-timeout", opts.IPFIXFileIdle, "IPFIX file idle exporter close timeout in seconds, 0 disables")
+	flag.BoolVar(&opts.IPFIXStrictElements, "ipfix-strict-elements", opts.IPFIXStrictElements, "enable/disable IPFIX dropping the data records with unknown elements")
+	flag.StringVar(&opts.IPFIXIANAFile, "ipfix-iana-file", opts.IPFIXIANAFile, "IPFIX IANA information elements CSV instead of the built-in")
+	flag.StringVar(&opts.IPFIXElementsFile, "ipfix-elements-file", opts.IPFIXElementsFile, "IPFIX site information elements overrides file")
+	flag.StringVar(&opts.IPFIXExporterElementsFile, "ipfix-exporter-elements-file", opts.IPFIXExporterElementsFile, "IPFIX per exporter information elements overrides file")
+	flag.Var(&opts.IPFIXVendorPacks, "ipfix-vendor-packs", "IPFIX vendor information elements packs, comma separated")
+	flag.StringVar(&opts.IPFIXJSONFormat, "ipfix-json-format", opts.IPFIXJSONFormat, "IPFIX JSON data records format: compact, flat or named")
+	flag.BoolVar(&opts.IPFIXRecordMessages, "ipfix-record-messages", opts.IPFIXRecordMessages, "enable/disable IPFIX message per data record")
+	flag.BoolVar(&opts.IPFIXAbsoluteTime, "ipfix-absolute-time", opts.IPFIXAbsoluteTime, "enable/disable IPFIX flow times conversion to unix milliseconds")
+	flag.BoolVar(&opts.IPFIXSampling, "ipfix-sampling", opts.IPFIXSampling, "enable/disable IPFIX data records sampling rates from the options data")
+	flag.BoolVar(&opts.IPFIXSamplingUpscale, "ipfix-sampling-upscale", opts.IPFIXSamplingUpscale, "enable/disable IPFIX counters upscaling by the sampling rates")
+	flag.IntVar(&opts.IPFIXPendingTimeout, "ipfix-pending-timeout", opts.IPFIXPendingTimeout, "IPFIX pending data sets timeout in seconds, zero disables")
+	flag.IntVar(&opts.IPFIXPendingMaxSize, "ipfix-pending-max-size", opts.IPFIXPendingMaxSize, "IPFIX pending data sets maximum size per agent in kilobytes")
+
+	// netflow version 5
+	flag.BoolVar(&opts.NetflowV5Enabled, "netflow5-enabled", opts.NetflowV5Enabled, "enable/disable netflow version 5 listener")
+	flag.IntVar(&opts.NetflowV5Port, "netflow5-port", opts.NetflowV5Port, "Netflow Version 5 port number")
+	flag.IntVar(&opts.NetflowV5UDPSize, "netflow5-max-udp-size", opts.NetflowV5UDPSize, "Netflow version 5 maximum UDP size")
+	flag.IntVar(&opts.NetflowV5Workers, "netflow5-workers", opts.NetflowV5Workers, "Netflow version 5 workers number")
+	flag.StringVar(&opts.NetflowV5Topic, "netflow5-topic", opts.NetflowV5Topic, "Netflow version 5 topic name")
 
 	// netflow version 9
 	flag.BoolVar(&opts.NetflowV9Enabled, "netflow9-enabled", opts.NetflowV9Enabled, "enable/disable netflow version 9 listener")
@@ -323,6 +547,32 @@ func (opts *Options) vFlowFlagSet() {
 	flag.IntVar(&opts.NetflowV9Workers, "netflow9-workers", opts.NetflowV9Workers, "Netflow version 9 workers number")
 	flag.StringVar(&opts.NetflowV9Topic, "netflow9-topic", opts.NetflowV9Topic, "Netflow version 9 topic name")
 	flag.StringVar(&opts.NetflowV9TplCacheFile, "netflow9-tpl-cache-file", opts.NetflowV9TplCacheFile, "Netflow version 9 template cache file")
+	flag.BoolVar(&opts.NetflowV9TplCacheEnabled, "netflow9-tpl-cache-enabled", opts.NetflowV9TplCacheEnabled, "enable/disable Netflow version 9 templates persistence")
+	flag.IntVar(&opts.NetflowV9TplCheckpoint, "netflow9-tpl-checkpoint-interval", opts.NetflowV9TplCheckpoint, "Netflow version 9 templates checkpoint interval in seconds, zero disables")
+	flag.BoolVar(&opts.NetflowV9TplScopePort, "netflow9-tpl-scope-port", opts.NetflowV9TplScopePort, "enable/disable Netflow version 9 agent source port in the templates scope")
+	flag.IntVar(&opts.NetflowV9TplLifetime, "netflow9-tpl-lifetime", opts.NetflowV9TplLifetime, "Netflow version 9 template lifetime in seconds, zero disables")
+	flag.IntVar(&opts.NetflowV9PendingTimeout, "netflow9-pending-timeout", opts.NetflowV9PendingTimeout, "Netflow version 9 pending data sets timeout in seconds, zero disables")
+	flag.IntVar(&opts.NetflowV9PendingMaxSize, "netflow9-pending-max-size", opts.NetflowV9PendingMaxSize, "Netflow version 9 pending data sets maximum size per agent in kilobytes")
+	flag.StringVar(&opts.NetflowV9JSONFormat, "netflow9-json-format", opts.NetflowV9JSONFormat, "Netflow version 9 JSON data records format: compact, flat or named")
+	flag.BoolVar(&opts.NetflowV9RecordMessages, "netflow9-record-messages", opts.NetflowV9RecordMessages, "enable/disable Netflow version 9 message per data record")
+	flag.BoolVar(&opts.NetflowV9AbsoluteTime, "netflow9-absolute-time", opts.NetflowV9AbsoluteTime, "enable/disable Netflow version 9 flow times conversion to unix milliseconds")
+	flag.BoolVar(&opts.NetflowV9Sampling, "netflow9-sampling", opts.NetflowV9Sampling, "enable/disable Netflow version 9 data records sampling rates from the options data")
+	flag.BoolVar(&opts.NetflowV9SamplingUpscale, "netflow9-sampling-upscale", opts.NetflowV9SamplingUpscale, "enable/disable Netflow version 9 counters upscaling by the sampling rates")
+	flag.Var(&opts.SamplingOverrides, "sampling-overrides", "IPFIX and Netflow version 9 static sampling rates as exporter=rate, comma separated")
+
+	// normalized flow options
+	flag.BoolVar(&opts.FlowEnabled, "flow-enabled", opts.FlowEnabled, "enable/disable the normalized flows of IPFIX, Netflow v9 and sFlow")
+	flag.StringVar(&opts.FlowTopic, "flow-topic", opts.FlowTopic, "normalized flows topic name")
+	flag.BoolVar(&opts.FlowRawOutput, "flow-raw-output", opts.FlowRawOutput, "enable/disable the decoded messages next to the normalized flows")
+
+	// exporters clock options
+	flag.IntVar(&opts.ClockSkewThreshold, "clock-skew-threshold", opts.ClockSkewThreshold, "exporters clock skew threshold to log in seconds, zero disables")
+
+	// offline decoding options
+	flag.StringVar(&opts.ReadPcap, "read-pcap", opts.ReadPcap, "decode the pcap/pcapng file offline and exit")
+	flag.StringVar(&opts.ReadIPFIXFile, "read-ipfix-file", opts.ReadIPFIXFile, "replay the IPFIX file or directory offline and exit")
+	flag.BoolVar(&opts.OfflineProducer, "offline-producer", opts.OfflineProducer, "enable/disable sending the offline decoded messages to the producer instead of stdout")
+	flag.BoolVar(&opts.OfflineProducer, "read-pcap-producer", opts.OfflineProducer, "deprecated alias of the offline-producer")
 
 	// producer options
 	flag.StringVar(&opts.MQName, "mqueue", opts.MQName, "producer message queue name")
@@ -338,6 +588,9 @@ func (opts *Options) vFlowFlagSet() {
 	# set 3rd party ipfix collector
 	vflow -ipfix-mirror-addr 192.168.1.10 -ipfix-mirror-port 4319
 
+	# decode a capture file offline
+	vflow -read-pcap /tmp/exporter.pcap
+
 	# enaable verbose logging
 	vflow -verbose=true
 
@@ -371,4 +624,8 @@ func vFlowLoadCfg(opts *Options) {
 	if err != nil {
 		opts.Logger.Println(err)
 	}
+
+	if opts.ReadPcapProducer {
+		opts.OfflineProducer = true
+	}
 }
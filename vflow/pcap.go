@@ -0,0 +1,375 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    pcap.go
+//: details: decodes the captured flow packets offline from pcap/pcapng file
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"github.com/VerizonDigital/vflow/flow"
+	"github.com/VerizonDigital/vflow/ipfix"
+	"github.com/VerizonDigital/vflow/netflow/v5"
+	"github.com/VerizonDigital/vflow/netflow/v9"
+	"github.com/VerizonDigital/vflow/packet"
+	"github.com/VerizonDigital/vflow/pcap"
+	"github.com/VerizonDigital/vflow/sflow"
+)
+
+// PcapReader represents offline decoding of a capture file,
+// the packets are decoded in capture order so the templates
+// are always processed before the data sets that follow them
+type PcapReader struct {
+	file      string
+	mCache    ipfix.MemCache
+	mCacheNF9 netflow9.MemCache
+	buf       *bytes.Buffer
+	out       *offlineOutput
+	stats     PcapStats
+}
+
+// PcapStats represents offline decoding stats
+type PcapStats struct {
+	PacketCount  uint64
+	UDPCount     uint64
+	DecodedCount uint64
+	ErrorCount   uint64
+}
+
+var (
+	errPcapNotUDP       = errors.New("not an UDP packet")
+	errPcapLinkType     = errors.New("unsupported link type")
+	errPcapShortPayload = errors.New("short link layer payload")
+)
+
+// NewPcapReader constructs offline capture decoder
+func NewPcapReader(file string) *PcapReader {
+	logger = opts.Logger
+
+	return &PcapReader{
+		file:      file,
+		mCache:    ipfix.NewMemCache(),
+		mCacheNF9: netflow9.NewMemCache(),
+		buf:       new(bytes.Buffer),
+		out:       newOfflineOutput(),
+	}
+}
+
+func (r *PcapReader) run() {
+	f, err := os.Open(r.file)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	defer f.Close()
+
+	rd, err := pcap.NewReader(f)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	loadDictionary()
+	ipfixJSONFormat = jsonFormat("ipfix", opts.IPFIXJSONFormat)
+	netflowV9JSONFormat = jsonFormat("netflow v9", opts.NetflowV9JSONFormat)
+	ipfixSampling = newSampling(opts.IPFIXSampling || opts.IPFIXSamplingUpscale)
+	netflowV9Sampling = newSampling(opts.NetflowV9Sampling || opts.NetflowV9SamplingUpscale)
+
+	if opts.SFlowCountersRates {
+		sFlowCounterRates = sflow.NewCounterRates()
+	}
+
+	for {
+		p, err := rd.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			logger.Println(err)
+			break
+		}
+
+		r.stats.PacketCount++
+
+		src, port, payload, err := pcapUDPPayload(p)
+		if err != nil {
+			continue
+		}
+
+		r.stats.UDPCount++
+
+		if err = r.decode(src, port, payload, p); err != nil {
+			r.stats.ErrorCount++
+			logger.Println(err)
+		}
+	}
+
+	r.shutdown()
+}
+
+func (r *PcapReader) shutdown() {
+	r.out.close()
+
+	logger.Printf("pcap %s: packets#: %d, udp#: %d, decoded#: %d, errors#: %d",
+		r.file,
+		r.stats.PacketCount,
+		r.stats.UDPCount,
+		r.stats.DecodedCount,
+		r.stats.ErrorCount,
+	)
+}
+
+func (r *PcapReader) decode(src net.IP, port int, b []byte, p *pcap.Packet) error {
+	var (
+		topic string
+		msgs  [][]byte
+		msg   []byte
+		err   error
+	)
+
+	r.buf.Reset()
+
+	switch port {
+	case opts.IPFIXPort:
+		topic = opts.IPFIXTopic
+		msgs, err = r.decodeIPFIX(src, b, p.Timestamp)
+	case opts.NetflowV9Port:
+		topic = opts.NetflowV9Topic
+		msgs, err = r.decodeNetflowV9(src, b, p.Timestamp)
+	case opts.NetflowV5Port:
+		topic = opts.NetflowV5Topic
+		msg, err = r.decodeNetflowV5(src, b)
+	case opts.SFlowPort:
+		topic = opts.SFlowTopic
+		msg, err = r.decodeSFlow(b, p)
+	default:
+		return nil
+	}
+
+	if msg != nil {
+		msgs = [][]byte{msg}
+	}
+
+	if len(msgs) > 0 {
+		r.stats.DecodedCount++
+	}
+
+	for _, msg := range msgs {
+		r.out.write(topic, msg)
+	}
+
+	return err
+}
+
+// decodeIPFIX decodes an IPFIX message, the capture time is the receive time
+func (r *PcapReader) decodeIPFIX(src net.IP, b []byte, rcvd time.Time) ([][]byte, error) {
+	d := ipfix.NewDecoder(src, b)
+	d.SetStrict(opts.IPFIXStrictElements)
+	d.SetReceiveTime(rcvd)
+	if ipfixSampling != nil {
+		d.SetSampling(ipfixSampling)
+	}
+	decodedMsg, err := d.Decode(r.mCache)
+	if decodedMsg == nil || decodedMsg.DataSets == nil {
+		return nil, err
+	}
+
+	if opts.IPFIXAbsoluteTime {
+		decodedMsg.AbsoluteTime()
+	}
+
+	if opts.IPFIXSamplingUpscale {
+		decodedMsg.Upscale()
+	}
+
+	if opts.FlowEnabled {
+		r.writeFlows(flow.FromIPFIX(decodedMsg))
+	}
+
+	if !rawOutput() {
+		return nil, err
+	}
+
+	msgs, mErr := ipfixMessages(r.buf, decodedMsg)
+	if mErr != nil {
+		return msgs, mErr
+	}
+
+	return msgs, err
+}
+
+// decodeNetflowV9 decodes a netflow v9 packet, the capture time is the receive time
+func (r *PcapReader) decodeNetflowV9(src net.IP, b []byte, rcvd time.Time) ([][]byte, error) {
+	d := netflow9.NewDecoder(src, b)
+	d.SetReceiveTime(rcvd)
+	if netflowV9Sampling != nil {
+		d.SetSampling(netflowV9Sampling)
+	}
+	decodedMsg, err := d.Decode(r.mCacheNF9)
+	if decodedMsg == nil || decodedMsg.DataSets == nil {
+		return nil, err
+	}
+
+	if opts.NetflowV9AbsoluteTime {
+		decodedMsg.AbsoluteTime()
+	}
+
+	if opts.NetflowV9SamplingUpscale {
+		decodedMsg.Upscale()
+	}
+
+	if opts.FlowEnabled {
+		r.writeFlows(flow.FromNetflowV9(decodedMsg))
+	}
+
+	if !rawOutput() {
+		return nil, err
+	}
+
+	msgs, mErr := netflowV9Messages(r.buf, decodedMsg)
+	if mErr != nil {
+		return msgs, mErr
+	}
+
+	return msgs, err
+}
+
+func (r *PcapReader) decodeNetflowV5(src net.IP, b []byte) ([]byte, error) {
+	d := netflow5.NewDecoder(src, b)
+	decodedMsg, err := d.Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	return decodedMsg.JSONMarshal(r.buf)
+}
+
+func (r *PcapReader) decodeSFlow(b []byte, p *pcap.Packet) ([]byte, error) {
+	d := sflow.NewSFDecoder(bytes.NewReader(b), opts.SFlowTypeFilter)
+	datagram, err := d.SFDecode()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, err := range d.Skipped() {
+		logger.Println(err)
+	}
+
+	// the collected time is the capture time
+	datagram.ColTime = p.Timestamp.Unix()
+
+	if opts.SFlowCountersEnabled {
+		for _, b := range counterMessages(datagram) {
+			r.out.write(opts.SFlowCountersTopic, b)
+		}
+	}
+
+	if len(datagram.Samples) < 1 {
+		return nil, nil
+	}
+
+	if opts.FlowEnabled {
+		r.writeFlows(flow.FromSFlow(datagram))
+	}
+
+	if !rawOutput() {
+		return nil, nil
+	}
+
+	return json.Marshal(datagram)
+}
+
+// writeFlows writes the normalized flows to the flows topic
+func (r *PcapReader) writeFlows(flows []flow.Flow) {
+	if !rawOutput() && len(flows) > 0 {
+		r.stats.DecodedCount++
+	}
+
+	for _, b := range flowMessages(flows) {
+		r.out.write(opts.FlowTopic, b)
+	}
+}
+
+// pcapUDPPayload returns the source address, the destination
+// port and the payload of an UDP packet
+func pcapUDPPayload(p *pcap.Packet) (net.IP, int, []byte, error) {
+	var (
+		data     = p.Data
+		protocol uint32
+	)
+
+	switch p.LinkType {
+	case pcap.LinkTypeEthernet:
+		protocol = packet.HeaderProtocolEthernet
+	case pcap.LinkTypeLinuxSLL:
+		if len(data) < 16 {
+			return nil, 0, nil, errPcapShortPayload
+		}
+		data = data[16:]
+	case pcap.LinkTypeNull:
+		if len(data) < 4 {
+			return nil, 0, nil, errPcapShortPayload
+		}
+		data = data[4:]
+	case pcap.LinkTypeRaw, pcap.LinkTypeIPv4, pcap.LinkTypeIPv6:
+	default:
+		return nil, 0, nil, errPcapLinkType
+	}
+
+	if protocol == 0 {
+		if len(data) < 1 {
+			return nil, 0, nil, errPcapShortPayload
+		}
+
+		switch data[0] >> 4 {
+		case 4:
+			protocol = packet.HeaderProtocolIPv4
+		case 6:
+			protocol = packet.HeaderProtocolIPv6
+		default:
+			return nil, 0, nil, errPcapLinkType
+		}
+	}
+
+	pkt := packet.NewPacket()
+	if _, err := pkt.Decoder(data, protocol); err != nil {
+		return nil, 0, nil, err
+	}
+
+	udp, ok := pkt.L4.(packet.UDPHeader)
+	if !ok {
+		return nil, 0, nil, errPcapNotUDP
+	}
+
+	var src string
+	switch h := pkt.L3.(type) {
+	case packet.IPv4Header:
+		src = h.Src
+	case packet.IPv6Header:
+		src = h.Src
+	}
+
+	return net.ParseIP(src), udp.DstPort, pkt.Payload(), nil
+}
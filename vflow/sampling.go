@@ -0,0 +1,72 @@
+//: ----------------------------------------------------------------------------
+//: Copyright (C) 2017 Verizon.  All Rights Reserved.
+//: All Rights Reserved
+//:
+//: file:    sampling.go
+//: details: IPFIX and Netflow v9 exporters sampling tables
+//: author:  agent
+//: date:    10/18/2026
+//:
+//: Licensed under the Apache License, Version 2.0 (the "License");
+//: you may not use this file except in compliance with the License.
+//: You may obtain a copy of the License at
+//:
+//:     http://www.apache.org/licenses/LICENSE-2.0
+//:
+//: Unless required by applicable law or agreed to in writing, software
+//: distributed under the License is distributed on an "AS IS" BASIS,
+//: WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//: See the License for the specific language governing permissions and
+//: limitations under the License.
+//: ----------------------------------------------------------------------------
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/VerizonDigital/vflow/ipfix"
+)
+
+var (
+	// the sampling tables of the exporters
+	ipfixSampling     *ipfix.Sampling
+	netflowV9Sampling *ipfix.Sampling
+)
+
+// newSampling constructs a sampling table with the static sampling
+// rates if it's enabled, it returns nil otherwise
+func newSampling(enabled bool) *ipfix.Sampling {
+	if !enabled {
+		return nil
+	}
+
+	s := ipfix.NewSampling()
+	for _, o := range opts.SamplingOverrides {
+		exporter, rate, err := samplingOverride(o)
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		s.SetOverride(exporter, rate)
+	}
+
+	return s
+}
+
+// samplingOverride returns the exporter and the rate of exporter=rate
+func samplingOverride(s string) (string, uint32, error) {
+	i := strings.LastIndex(s, "=")
+	if i < 1 {
+		return "", 0, fmt.Errorf("invalid sampling override: %s", s)
+	}
+
+	rate, err := strconv.ParseUint(strings.TrimSpace(s[i+1:]), 10, 32)
+	if err != nil || rate < 1 {
+		return "", 0, fmt.Errorf("invalid sampling override rate: %s", s)
+	}
+
+	return strings.TrimSpace(s[:i]), uint32(rate), nil
+}
@@ -32,6 +32,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/VerizonDigital/vflow/flow"
 	"github.com/VerizonDigital/vflow/producer"
 	"github.com/VerizonDigital/vflow/sflow"
 )
@@ -58,13 +59,18 @@ type SFlowStats struct {
 	MessageQueue int
 	UDPCount     uint64
 	DecodedCount uint64
+	CounterCount uint64
 	MQErrorCount uint64
 	Workers      int32
 }
 
 var (
-	sFlowUDPCh = make(chan SFUDPMsg, 1000)
-	sFlowMQCh  = make(chan []byte, 1000)
+	sFlowUDPCh        = make(chan SFUDPMsg, 1000)
+	sFlowMQCh         = make(chan []byte, 1000)
+	sFlowCountersMQCh = make(chan []byte, 1000)
+
+	// the previous counter records of the data sources
+	sFlowCounterRates *sflow.CounterRates
 
 	// sflow udp payload pool
 	sFlowBuffer = &sync.Pool{
@@ -111,6 +117,8 @@ func (s *SFlow) run() {
 
 	logger.Printf("sFlow is running (UDP: listening on [::]:%d workers#: %d)", s.port, s.workers)
 
+	flowProducer()
+
 	go func() {
 		p := producer.NewProducer(opts.MQName)
 
@@ -125,6 +133,27 @@ func (s *SFlow) run() {
 		}
 	}()
 
+	if opts.SFlowCountersEnabled {
+		if opts.SFlowCountersRates {
+			sFlowCounterRates = sflow.NewCounterRates()
+			go counterRatesSweeper(sFlowCounterRates)
+		}
+
+		go func() {
+			p := producer.NewProducer(opts.MQName)
+
+			p.MQConfigFile = path.Join(opts.VFlowConfigPath, opts.MQConfigFile)
+			p.MQErrorCount = &s.stats.MQErrorCount
+			p.Logger = logger
+			p.Chan = sFlowCountersMQCh
+			p.Topic = opts.SFlowCountersTopic
+
+			if err := p.Run(); err != nil {
+				logger.Fatal(err)
+			}
+		}()
+	}
+
 	go func() {
 		if !opts.DynWorkers {
 			logger.Println("sFlow dynamic worker disabled")
@@ -184,7 +213,42 @@ LOOP:
 		reader = bytes.NewReader(msg.body)
 		d := sflow.NewSFDecoder(reader, opts.SFlowTypeFilter)
 		datagram, err := d.SFDecode()
-		if err != nil || len(datagram.Samples) < 1 {
+		if err != nil {
+			sFlowBuffer.Put(msg.body[:opts.SFlowUDPSize])
+			continue
+		}
+
+		for _, err := range d.Skipped() {
+			logger.Println(err)
+		}
+
+		// the counter samples go to their own topic
+		if opts.SFlowCountersEnabled {
+			for _, b := range counterMessages(datagram) {
+				atomic.AddUint64(&s.stats.CounterCount, 1)
+
+				select {
+				case sFlowCountersMQCh <- b:
+				default:
+				}
+
+				if opts.Verbose {
+					logger.Println(string(b))
+				}
+			}
+		}
+
+		if len(datagram.Samples) < 1 {
+			sFlowBuffer.Put(msg.body[:opts.SFlowUDPSize])
+			continue
+		}
+
+		if opts.FlowEnabled {
+			produceFlows(flow.FromSFlow(datagram))
+		}
+
+		if !rawOutput() {
+			atomic.AddUint64(&s.stats.DecodedCount, 1)
 			sFlowBuffer.Put(msg.body[:opts.SFlowUDPSize])
 			continue
 		}
@@ -211,12 +275,47 @@ LOOP:
 	}
 }
 
+// counterMessages encodes the counter records of the datagram as JSON
+// messages, with the per second rates if they're enabled
+func counterMessages(datagram *sflow.SFDatagram) [][]byte {
+	records := datagram.CounterRecords()
+	msgs := make([][]byte, 0, len(records))
+
+	for i := range records {
+		if sFlowCounterRates != nil {
+			sFlowCounterRates.Rates(&records[i])
+		}
+
+		b, err := json.Marshal(records[i])
+		if err != nil {
+			logger.Println(err)
+			continue
+		}
+
+		msgs = append(msgs, b)
+	}
+
+	return msgs
+}
+
+// counterRatesSweeper drops the data sources without counter records
+// for ten minutes, the agents usually send them every 30 seconds
+func counterRatesSweeper(c *sflow.CounterRates) {
+	tick := time.Tick(time.Minute)
+
+	for {
+		<-tick
+		c.Sweep(10 * time.Minute)
+	}
+}
+
 func (s *SFlow) status() *SFlowStats {
 	return &SFlowStats{
 		UDPQueue:     len(sFlowUDPCh),
 		MessageQueue: len(sFlowMQCh),
 		UDPCount:     atomic.LoadUint64(&s.stats.UDPCount),
 		DecodedCount: atomic.LoadUint64(&s.stats.DecodedCount),
+		CounterCount: atomic.LoadUint64(&s.stats.CounterCount),
 		MQErrorCount: atomic.LoadUint64(&s.stats.MQErrorCount),
 		Workers:      atomic.LoadInt32(&s.stats.Workers),
 	}
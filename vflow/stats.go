@@ -28,6 +28,8 @@ import (
 	"net/http"
 	"runtime"
 	"time"
+
+	"github.com/VerizonDigital/vflow/ipfix"
 )
 
 var startTime = time.Now().Unix()
@@ -80,17 +82,19 @@ func StatsSysHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // StatsFlowHandler handles /flow endpoint
-func StatsFlowHandler(i *IPFIX, s *SFlow, n *NetflowV9) http.HandlerFunc {
+func StatsFlowHandler(i *IPFIX, s *SFlow, n5 *NetflowV5, n *NetflowV9) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var data = &struct {
 			StartTime int64
 			IPFIX     *IPFIXStats
 			SFlow     *SFlowStats
+			NetflowV5 *NetflowV5Stats
 			NetflowV9 *NetflowV9Stats
 		}{
 			startTime,
 			i.status(),
 			s.status(),
+			n5.status(),
 			n.status(),
 		}
 
@@ -105,14 +109,56 @@ func StatsFlowHandler(i *IPFIX, s *SFlow, n *NetflowV9) http.HandlerFunc {
 	}
 }
 
-func statsHTTPServer(ipfix *IPFIX, sflow *SFlow, netflow9 *NetflowV9) {
+// StatsIPFIXUnknownHandler handles /ipfix/unknown endpoint, it
+// reports the information elements that aren't in the model
+func StatsIPFIXUnknownHandler(w http.ResponseWriter, r *http.Request) {
+	j, err := json.Marshal(ipfix.UnknownElements())
+	if err != nil {
+		logger.Println(err)
+	}
+
+	if _, err = w.Write(j); err != nil {
+		logger.Println(err)
+	}
+}
+
+// StatsIPFIXLearnedHandler handles /ipfix/learned endpoint, it
+// reports the elements that the exporters announced (RFC 5610)
+func StatsIPFIXLearnedHandler(w http.ResponseWriter, r *http.Request) {
+	j, err := json.Marshal(ipfix.LearnedElements())
+	if err != nil {
+		logger.Println(err)
+	}
+
+	if _, err = w.Write(j); err != nil {
+		logger.Println(err)
+	}
+}
+
+// StatsClockHandler handles /clock endpoint, it reports
+// the exporters clock skew against the collector time
+func StatsClockHandler(w http.ResponseWriter, r *http.Request) {
+	j, err := json.Marshal(clocks())
+	if err != nil {
+		logger.Println(err)
+	}
+
+	if _, err = w.Write(j); err != nil {
+		logger.Println(err)
+	}
+}
+
+func statsHTTPServer(ipfix *IPFIX, sflow *SFlow, netflow5 *NetflowV5, netflow9 *NetflowV9) {
 	if !opts.StatsEnabled {
 		return
 	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/sys", StatsSysHandler)
-	mux.HandleFunc("/flow", StatsFlowHandler(ipfix, sflow, netflow9))
+	mux.HandleFunc("/flow", StatsFlowHandler(ipfix, sflow, netflow5, netflow9))
+	mux.HandleFunc("/ipfix/unknown", StatsIPFIXUnknownHandler)
+	mux.HandleFunc("/ipfix/learned", StatsIPFIXLearnedHandler)
+	mux.HandleFunc("/clock", StatsClockHandler)
 
 	addr := net.JoinHostPort(opts.StatsHTTPAddr, opts.StatsHTTPPort)
 
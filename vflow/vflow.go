@@ -51,13 +51,24 @@ func main() {
 	opts = GetOptions()
 	runtime.GOMAXPROCS(opts.GetCPU())
 
+	if opts.ReadPcap != "" {
+		NewPcapReader(opts.ReadPcap).run()
+		return
+	}
+
+	if opts.ReadIPFIXFile != "" {
+		NewIPFIXFileReader(opts.ReadIPFIXFile).run()
+		return
+	}
+
 	signal.Notify(signalCh, syscall.SIGINT, syscall.SIGTERM)
 
 	sFlow := NewSFlow()
 	ipfix := NewIPFIX()
+	netflow5 := NewNetflowV5()
 	netflow9 := NewNetflowV9()
 
-	protos := []proto{sFlow, ipfix, netflow9}
+	protos := []proto{sFlow, ipfix, netflow5, netflow9}
 
 	for _, p := range protos {
 		wg.Add(1)
@@ -67,7 +78,7 @@ func main() {
 		}(p)
 	}
 
-	go statsHTTPServer(ipfix, sFlow, netflow9)
+	go statsHTTPServer(ipfix, sFlow, netflow5, netflow9)
 
 	<-signalCh
 